@@ -0,0 +1,30 @@
+package tkDto
+
+import tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+
+// AggregateActivityRecordsRequest counts the activity records matching the ReadRequest
+// filters (its Pagination is ignored), grouped by every GroupBy dimension and, when
+// set, by TimeBucketGranularity windows of CreatedAt. At most MaxGroupsCount groups
+// are returned, the response IsTruncated when there were more.
+type AggregateActivityRecordsRequest struct {
+	ReadRequest           ReadActivityRecordsRequest                         `json:"readRequest"`
+	GroupBy               []tkValueObject.ActivityRecordAggregationDimension `json:"groupBy"`
+	TimeBucketGranularity *tkValueObject.TimeBucketGranularity               `json:"timeBucketGranularity"`
+	MaxGroupsCount        uint16                                             `json:"maxGroupsCount"`
+}
+
+// ActivityRecordsAggregationGroup only has the fields of the requested dimensions set.
+// A record with several affected resources counts once for each distinct type.
+type ActivityRecordsAggregationGroup struct {
+	RecordCode           *tkValueObject.ActivityRecordCode       `json:"recordCode,omitempty"`
+	RecordLevel          *tkValueObject.ActivityRecordLevel      `json:"recordLevel,omitempty"`
+	OperatorSri          *tkValueObject.SystemResourceIdentifier `json:"operatorSri,omitempty"`
+	AffectedResourceType *tkValueObject.SystemResourceType       `json:"affectedResourceType,omitempty"`
+	TimeBucketStartAt    *tkValueObject.UnixTime                 `json:"timeBucketStartAt,omitempty"`
+	RecordsCount         uint64                                  `json:"recordsCount"`
+}
+
+type AggregateActivityRecordsResponse struct {
+	Groups      []ActivityRecordsAggregationGroup `json:"groups"`
+	IsTruncated bool                              `json:"isTruncated"`
+}
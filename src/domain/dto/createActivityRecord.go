@@ -9,4 +9,5 @@ type CreateActivityRecord struct {
 	RecordDetails     any                                      `json:"recordDetails"`
 	OperatorSri       *tkValueObject.SystemResourceIdentifier  `json:"operatorSri"`
 	OperatorIpAddress *tkValueObject.IpAddress                 `json:"operatorIpAddress"`
+	CorrelationId     *tkValueObject.CorrelationId             `json:"correlationId"`
 }
@@ -2,6 +2,9 @@ package tkDto
 
 import tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 
+// DeleteActivityRecord AffectedResources matches like the ReadActivityRecordsRequest one.
+// The Request* fields aren't filters: they identify who asked for the deletion and are
+// copied to the SECURITY activity records it leaves.
 type DeleteActivityRecord struct {
 	RecordId          *tkValueObject.ActivityRecordId          `json:"recordId"`
 	RecordLevel       *tkValueObject.ActivityRecordLevel       `json:"recordLevel"`
@@ -11,6 +14,17 @@ type DeleteActivityRecord struct {
 	OperatorIpAddress *tkValueObject.IpAddress                 `json:"operatorIpAddress"`
 	CreatedBeforeAt   *tkValueObject.UnixTime                  `json:"createdBeforeAt"`
 	CreatedAfterAt    *tkValueObject.UnixTime                  `json:"createdAfterAt"`
+	CorrelationId     *tkValueObject.CorrelationId             `json:"correlationId"`
+	// AccountId scopes the deletion, see ReadActivityRecordsRequest.AccountId.
+	AccountId *tkValueObject.AccountId `json:"accountId"`
+
+	RequestOperatorSri       *tkValueObject.SystemResourceIdentifier `json:"requestOperatorSri"`
+	RequestOperatorIpAddress *tkValueObject.IpAddress                `json:"requestOperatorIpAddress"`
+	RequestCorrelationId     *tkValueObject.CorrelationId            `json:"requestCorrelationId"`
+}
+
+type DeleteActivityRecordResponse struct {
+	DeletedRecordsCount uint64 `json:"deletedRecordsCount"`
 }
 
 func NewDeleteActivityRecord(
@@ -0,0 +1,16 @@
+package tkDto
+
+import tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+
+// ExportActivityRecordsRequest.ReadRequest carries the same filters accepted by
+// ReadActivityRecords; its Pagination is ignored since the export walks every
+// matching record by ascending ID, BatchSize records at a time.
+type ExportActivityRecordsRequest struct {
+	ReadRequest  ReadActivityRecordsRequest `json:"readRequest"`
+	ExportFormat tkValueObject.ExportFormat `json:"exportFormat"`
+	BatchSize    uint16                     `json:"batchSize"`
+}
+
+type ExportActivityRecordsResponse struct {
+	ExportedRecordsCount uint64 `json:"exportedRecordsCount"`
+}
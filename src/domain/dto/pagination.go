@@ -7,12 +7,20 @@ var (
 	PaginationUnpaginated = Pagination{PageNumber: 0, ItemsPerPage: 1000}
 )
 
+// Pagination.Cursor (keyset mode) takes precedence over LastSeenId and PageNumber.
+// NextCursor is only filled in responses, when the page is full and sorted.
+// ShouldSkipTotals spares counting the matching items, leaving PagesTotal and
+// ItemsTotal nil, e.g. when walking every page with a keyset.
 type Pagination struct {
 	PageNumber    uint32                                 `json:"pageNumber"`
 	ItemsPerPage  uint16                                 `json:"itemsPerPage"`
 	SortBy        *tkValueObject.PaginationSortBy        `json:"sortBy"`
 	SortDirection *tkValueObject.PaginationSortDirection `json:"sortDirection"`
 	LastSeenId    *tkValueObject.PaginationLastSeenId    `json:"lastSeenId"`
+	Cursor        *tkValueObject.PaginationCursor        `json:"cursor"`
 	PagesTotal    *uint32                                `json:"pagesTotal"`
 	ItemsTotal    *uint64                                `json:"itemsTotal"`
+	NextCursor    *tkValueObject.PaginationCursor        `json:"nextCursor"`
+
+	ShouldSkipTotals bool `json:"-"`
 }
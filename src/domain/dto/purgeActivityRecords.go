@@ -0,0 +1,32 @@
+package tkDto
+
+import (
+	"time"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+// ActivityRecordRetentionRule declares how long (MaxAge) and/or how many
+// (MaxRecordsCount) activity records matching RecordLevel and RecordCode are kept.
+// A rule without RecordLevel and RecordCode matches every record.
+type ActivityRecordRetentionRule struct {
+	RecordLevel     *tkValueObject.ActivityRecordLevel `json:"recordLevel"`
+	RecordCode      *tkValueObject.ActivityRecordCode  `json:"recordCode"`
+	MaxAge          *time.Duration                     `json:"maxAge"`
+	MaxRecordsCount *uint64                            `json:"maxRecordsCount"`
+}
+
+// PurgeActivityRecordsRequest evaluates the RetentionRules in order and each record is
+// governed only by the first rule that matches it (like firewall rules), so more
+// specific rules (e.g. SECURITY for 1 year) must come before generic ones (e.g.
+// everything for 90 days). Records not matched by any rule are kept.
+type PurgeActivityRecordsRequest struct {
+	RetentionRules []ActivityRecordRetentionRule `json:"retentionRules"`
+	BatchSize      uint16                        `json:"batchSize"`
+	ShouldVacuum   bool                          `json:"shouldVacuum"`
+}
+
+type PurgeActivityRecordsResponse struct {
+	PurgedRecordsCount        uint64   `json:"purgedRecordsCount"`
+	PurgedRecordsCountPerRule []uint64 `json:"purgedRecordsCountPerRule"`
+}
@@ -5,6 +5,18 @@ import (
 	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 )
 
+// ActivityRecordDetailsFilter compares the RecordDetails value found at Path with
+// Value, which must be a string, a number, a bool or nil (only for "eq" and "ne").
+// Records without the path never match a non-nil Value.
+type ActivityRecordDetailsFilter struct {
+	Path     tkValueObject.ActivityRecordDetailsPath `json:"path"`
+	Operator tkValueObject.ComparisonOperator        `json:"operator"`
+	Value    any                                     `json:"value"`
+}
+
+// ReadActivityRecordsRequest AffectedResources matches the records affecting any
+// resource matched by one of the SRIs, wildcard and account SRIs included (see
+// SystemResourceIdentifier.Matches).
 type ReadActivityRecordsRequest struct {
 	Pagination        Pagination                               `json:"pagination"`
 	RecordId          *tkValueObject.ActivityRecordId          `json:"recordId"`
@@ -13,8 +25,14 @@ type ReadActivityRecordsRequest struct {
 	AffectedResources []tkValueObject.SystemResourceIdentifier `json:"affectedResources"`
 	OperatorSri       *tkValueObject.SystemResourceIdentifier  `json:"operatorSri"`
 	OperatorIpAddress *tkValueObject.IpAddress                 `json:"operatorIpAddress"`
+	CorrelationId     *tkValueObject.CorrelationId             `json:"correlationId"`
 	CreatedBeforeAt   *tkValueObject.UnixTime                  `json:"createdBeforeAt"`
 	CreatedAfterAt    *tkValueObject.UnixTime                  `json:"createdAfterAt"`
+	// RecordDetailsFilters are combined with AND.
+	RecordDetailsFilters []ActivityRecordDetailsFilter `json:"recordDetailsFilters"`
+	// AccountId scopes the records to the ones operated by the account (or by one of
+	// its resources) or affecting one of its resources.
+	AccountId *tkValueObject.AccountId `json:"accountId"`
 }
 
 type ReadActivityRecordsResponse struct {
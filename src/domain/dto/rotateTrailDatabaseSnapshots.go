@@ -0,0 +1,26 @@
+package tkDto
+
+import (
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+// CreateTrailDatabaseSnapshot writes a new snapshot into SnapshotsDirPath, optionally
+// compressed (gzip, xz or br).
+type CreateTrailDatabaseSnapshot struct {
+	SnapshotsDirPath  tkValueObject.UnixAbsoluteFilePath `json:"snapshotsDirPath"`
+	CompressionFormat *tkValueObject.CompressionFormat   `json:"compressionFormat"`
+}
+
+// RotateTrailDatabaseSnapshotsRequest creates a snapshot and then keeps only the
+// MaxSnapshotsCount most recent ones of SnapshotsDirPath (the new one included).
+type RotateTrailDatabaseSnapshotsRequest struct {
+	SnapshotsDirPath  tkValueObject.UnixAbsoluteFilePath `json:"snapshotsDirPath"`
+	CompressionFormat *tkValueObject.CompressionFormat   `json:"compressionFormat"`
+	MaxSnapshotsCount uint16                             `json:"maxSnapshotsCount"`
+}
+
+type RotateTrailDatabaseSnapshotsResponse struct {
+	CreatedSnapshot          tkEntity.TrailDatabaseSnapshot       `json:"createdSnapshot"`
+	DeletedSnapshotFilePaths []tkValueObject.UnixAbsoluteFilePath `json:"deletedSnapshotFilePaths"`
+}
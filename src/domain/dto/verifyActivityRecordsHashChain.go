@@ -0,0 +1,14 @@
+package tkDto
+
+import tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+
+// VerifyActivityRecordsHashChainResponse reports the result of walking the activity
+// records hash chain. When IsIntact is false, FirstBrokenRecordId is the first record
+// whose link could not be verified and BrokenLinkReason explains why.
+type VerifyActivityRecordsHashChainResponse struct {
+	IsIntact             bool                            `json:"isIntact"`
+	VerifiedRecordsCount uint64                          `json:"verifiedRecordsCount"`
+	CheckpointsCount     uint64                          `json:"checkpointsCount"`
+	FirstBrokenRecordId  *tkValueObject.ActivityRecordId `json:"firstBrokenRecordId"`
+	BrokenLinkReason     *string                         `json:"brokenLinkReason"`
+}
@@ -12,6 +12,7 @@ type ActivityRecord struct {
 	RecordDetails     any                                      `json:"recordDetails"`
 	OperatorSri       *tkValueObject.SystemResourceIdentifier  `json:"operatorSri"`
 	OperatorIpAddress *tkValueObject.IpAddress                 `json:"operatorIpAddress"`
+	CorrelationId     *tkValueObject.CorrelationId             `json:"correlationId"`
 	CreatedAt         tkValueObject.UnixTime                   `json:"createdAt"`
 }
 
@@ -0,0 +1,23 @@
+package entity
+
+import (
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+type TrailDatabaseSnapshot struct {
+	FilePath  tkValueObject.UnixAbsoluteFilePath `json:"filePath"`
+	Size      tkValueObject.Byte                 `json:"size"`
+	CreatedAt tkValueObject.UnixTime             `json:"createdAt"`
+}
+
+func NewTrailDatabaseSnapshot(
+	filePath tkValueObject.UnixAbsoluteFilePath,
+	size tkValueObject.Byte,
+	createdAt tkValueObject.UnixTime,
+) TrailDatabaseSnapshot {
+	return TrailDatabaseSnapshot{
+		FilePath:  filePath,
+		Size:      size,
+		CreatedAt: createdAt,
+	}
+}
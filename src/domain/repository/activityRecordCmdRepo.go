@@ -4,5 +4,10 @@ import tkDto "github.com/goinfinite/tk/src/domain/dto"
 
 type ActivityRecordCmdRepo interface {
 	Create(createDto tkDto.CreateActivityRecord) error
-	Delete(deleteDto tkDto.DeleteActivityRecord) error
+	Delete(
+		deleteDto tkDto.DeleteActivityRecord,
+	) (tkDto.DeleteActivityRecordResponse, error)
+	Purge(
+		purgeDto tkDto.PurgeActivityRecordsRequest,
+	) (tkDto.PurgeActivityRecordsResponse, error)
 }
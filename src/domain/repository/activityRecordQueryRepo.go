@@ -8,4 +8,8 @@ import (
 type ActivityRecordQueryRepo interface {
 	Read(tkDto.ReadActivityRecordsRequest) (tkDto.ReadActivityRecordsResponse, error)
 	ReadFirst(tkDto.ReadActivityRecordsRequest) (tkEntity.ActivityRecord, error)
+	Aggregate(
+		tkDto.AggregateActivityRecordsRequest,
+	) (tkDto.AggregateActivityRecordsResponse, error)
+	VerifyHashChain() (tkDto.VerifyActivityRecordsHashChainResponse, error)
 }
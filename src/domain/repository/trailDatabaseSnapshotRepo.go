@@ -0,0 +1,16 @@
+package tkRepository
+
+import (
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+// TrailDatabaseSnapshotRepo reads the snapshots most recent first.
+type TrailDatabaseSnapshotRepo interface {
+	Create(tkDto.CreateTrailDatabaseSnapshot) (tkEntity.TrailDatabaseSnapshot, error)
+	Read(
+		snapshotsDirPath tkValueObject.UnixAbsoluteFilePath,
+	) ([]tkEntity.TrailDatabaseSnapshot, error)
+	Delete(snapshotFilePath tkValueObject.UnixAbsoluteFilePath) error
+}
@@ -0,0 +1,35 @@
+package tkUseCase
+
+import (
+	"errors"
+	"log/slog"
+	"slices"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+)
+
+// AggregateActivityRecords counts the activity records matching the read filters,
+// grouped by the requested dimensions and/or time buckets, without loading them.
+func AggregateActivityRecords(
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo,
+	aggregateDto tkDto.AggregateActivityRecordsRequest,
+) (responseDto tkDto.AggregateActivityRecordsResponse, err error) {
+	if len(aggregateDto.GroupBy) == 0 && aggregateDto.TimeBucketGranularity == nil {
+		return responseDto, errors.New("AggregationRequiresGroupByOrTimeBucket")
+	}
+
+	for dimensionIndex, aggregationDimension := range aggregateDto.GroupBy {
+		if slices.Contains(aggregateDto.GroupBy[:dimensionIndex], aggregationDimension) {
+			return responseDto, errors.New("DuplicatedAggregationDimension")
+		}
+	}
+
+	responseDto, err = activityRecordQueryRepo.Aggregate(aggregateDto)
+	if err != nil {
+		slog.Error("AggregateActivityRecordsInfraError", slog.String("err", err.Error()))
+		return responseDto, errors.New("AggregateActivityRecordsInfraError")
+	}
+
+	return responseDto, nil
+}
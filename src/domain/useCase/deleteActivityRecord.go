@@ -6,17 +6,60 @@ import (
 
 	tkDto "github.com/goinfinite/tk/src/domain/dto"
 	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 )
 
-func DeleteActivityRecord(
+var (
+	ActivityRecordCodeActivityRecordsDeleted      tkValueObject.ActivityRecordCode = "ActivityRecordsDeleted"
+	ActivityRecordCodeActivityRecordsDeleteFailed tkValueObject.ActivityRecordCode = "ActivityRecordsDeleteFailed"
+)
+
+// DeleteActivityRecordWithCount deletes every activity record matching the filters
+// and returns how many were deleted. Every deletion, successful or not, leaves its own
+// SECURITY activity record with the filters and the number of deleted records,
+// attributed to the Request* operator.
+func DeleteActivityRecordWithCount(
 	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo,
 	deleteDto tkDto.DeleteActivityRecord,
-) error {
-	err := activityRecordCmdRepo.Delete(deleteDto)
+) (responseDto tkDto.DeleteActivityRecordResponse, err error) {
+	responseDto, err = activityRecordCmdRepo.Delete(deleteDto)
 	if err != nil {
 		slog.Error("DeleteActivityRecordError", slog.String("err", err.Error()))
-		return errors.New("DeleteActivityRecordInfraError")
+		CreateActivityRecord(activityRecordCmdRepo, tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelSecurity,
+			RecordCode:  ActivityRecordCodeActivityRecordsDeleteFailed,
+			RecordDetails: map[string]any{
+				"deleteRequest":       deleteDto,
+				"deletedRecordsCount": responseDto.DeletedRecordsCount,
+				"err":                 err.Error(),
+			},
+			OperatorSri:       deleteDto.RequestOperatorSri,
+			OperatorIpAddress: deleteDto.RequestOperatorIpAddress,
+			CorrelationId:     deleteDto.RequestCorrelationId,
+		})
+		return responseDto, errors.New("DeleteActivityRecordInfraError")
 	}
 
-	return nil
+	CreateActivityRecord(activityRecordCmdRepo, tkDto.CreateActivityRecord{
+		RecordLevel: tkValueObject.ActivityRecordLevelSecurity,
+		RecordCode:  ActivityRecordCodeActivityRecordsDeleted,
+		RecordDetails: map[string]any{
+			"deleteRequest":       deleteDto,
+			"deletedRecordsCount": responseDto.DeletedRecordsCount,
+		},
+		OperatorSri:       deleteDto.RequestOperatorSri,
+		OperatorIpAddress: deleteDto.RequestOperatorIpAddress,
+		CorrelationId:     deleteDto.RequestCorrelationId,
+	})
+
+	return responseDto, nil
+}
+
+// DeleteActivityRecord is DeleteActivityRecordWithCount without the count.
+func DeleteActivityRecord(
+	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo,
+	deleteDto tkDto.DeleteActivityRecord,
+) error {
+	_, err := DeleteActivityRecordWithCount(activityRecordCmdRepo, deleteDto)
+	return err
 }
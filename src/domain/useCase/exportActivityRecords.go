@@ -0,0 +1,195 @@
+package tkUseCase
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"io"
+	"log/slog"
+	"strconv"
+	"strings"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+const activityRecordsExportBatchSizeDefault uint16 = 500
+
+var ActivityRecordsCsvExportHeader = []string{
+	"recordId", "recordLevel", "recordCode", "affectedResources", "recordDetails",
+	"operatorSri", "operatorIpAddress", "correlationId", "createdAt",
+}
+
+// activityRecordCsvCellSanitizer prevents spreadsheet applications from evaluating
+// attacker controlled values (e.g. record details) as formulas.
+func activityRecordCsvCellSanitizer(cellValue string) string {
+	if cellValue == "" {
+		return cellValue
+	}
+
+	switch cellValue[0] {
+	case '=', '+', '-', '@', '\t', '\r':
+		return "'" + cellValue
+	}
+	return cellValue
+}
+
+func activityRecordToCsvRow(activityRecord tkEntity.ActivityRecord) ([]string, error) {
+	affectedResourcesStrSlice := []string{}
+	for _, affectedResource := range activityRecord.AffectedResources {
+		affectedResourcesStrSlice = append(affectedResourcesStrSlice, affectedResource.String())
+	}
+
+	recordDetailsStr := ""
+	switch recordDetails := activityRecord.RecordDetails.(type) {
+	case nil:
+	case string:
+		recordDetailsStr = recordDetails
+	default:
+		recordDetailsBytes, err := json.Marshal(recordDetails)
+		if err != nil {
+			return nil, err
+		}
+		recordDetailsStr = string(recordDetailsBytes)
+	}
+
+	operatorSriStr := ""
+	if activityRecord.OperatorSri != nil {
+		operatorSriStr = activityRecord.OperatorSri.String()
+	}
+
+	operatorIpAddressStr := ""
+	if activityRecord.OperatorIpAddress != nil {
+		operatorIpAddressStr = activityRecord.OperatorIpAddress.String()
+	}
+
+	correlationIdStr := ""
+	if activityRecord.CorrelationId != nil {
+		correlationIdStr = activityRecord.CorrelationId.String()
+	}
+
+	csvRow := []string{
+		strconv.FormatUint(activityRecord.RecordId.Uint64(), 10),
+		activityRecord.RecordLevel.String(),
+		activityRecord.RecordCode.String(),
+		strings.Join(affectedResourcesStrSlice, ","),
+		recordDetailsStr,
+		operatorSriStr,
+		operatorIpAddressStr,
+		correlationIdStr,
+		activityRecord.CreatedAt.String(),
+	}
+	for cellIndex, cellValue := range csvRow {
+		csvRow[cellIndex] = activityRecordCsvCellSanitizer(cellValue)
+	}
+
+	return csvRow, nil
+}
+
+// ExportActivityRecords streams every activity record matching the read request
+// filters to outputWriter, one record per line (NDJSON) or row (CSV). Records are read
+// in batches with a keyset cursor on the record ID, so memory usage doesn't depend on
+// how many records match, and without counting them. When outputWriter has a Flush
+// method, it's called after every batch.
+func ExportActivityRecords(
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo,
+	exportDto tkDto.ExportActivityRecordsRequest,
+	outputWriter io.Writer,
+) (responseDto tkDto.ExportActivityRecordsResponse, err error) {
+	switch exportDto.ExportFormat {
+	case tkValueObject.ExportFormatNdjson, tkValueObject.ExportFormatCsv:
+	default:
+		return responseDto, errors.New("InvalidExportFormat")
+	}
+
+	batchSize := activityRecordsExportBatchSizeDefault
+	if exportDto.BatchSize != 0 {
+		batchSize = exportDto.BatchSize
+	}
+
+	sortBy := tkValueObject.PaginationSortBy("id")
+	sortDirection := tkValueObject.PaginationSortDirectionAsc
+	readRequestDto := exportDto.ReadRequest
+	readRequestDto.Pagination = tkDto.Pagination{
+		ItemsPerPage:     batchSize,
+		SortBy:           &sortBy,
+		SortDirection:    &sortDirection,
+		ShouldSkipTotals: true,
+	}
+
+	ndjsonEncoder := json.NewEncoder(outputWriter)
+	csvWriter := csv.NewWriter(outputWriter)
+	outputFlusher, isOutputFlushable := outputWriter.(interface{ Flush() })
+
+	isFirstBatch := true
+	for {
+		readResponseDto, err := activityRecordQueryRepo.Read(readRequestDto)
+		if err != nil {
+			slog.Error("ExportActivityRecordsInfraError", slog.String("err", err.Error()))
+			return responseDto, errors.New("ExportActivityRecordsInfraError")
+		}
+
+		if isFirstBatch && exportDto.ExportFormat == tkValueObject.ExportFormatCsv {
+			err = csvWriter.Write(ActivityRecordsCsvExportHeader)
+			if err != nil {
+				return responseDto, errors.New("ExportActivityRecordsWriteError")
+			}
+		}
+		isFirstBatch = false
+
+		// Unreadable records are skipped by the repository, so a short batch doesn't mean
+		// the last one; only an empty batch does.
+		if len(readResponseDto.ActivityRecords) == 0 {
+			break
+		}
+
+		for _, activityRecord := range readResponseDto.ActivityRecords {
+			switch exportDto.ExportFormat {
+			case tkValueObject.ExportFormatCsv:
+				csvRow, err := activityRecordToCsvRow(activityRecord)
+				if err != nil {
+					slog.Debug(
+						"ExportActivityRecordCsvRowError",
+						slog.Uint64("recordId", activityRecord.RecordId.Uint64()),
+						slog.String("err", err.Error()),
+					)
+					continue
+				}
+				err = csvWriter.Write(csvRow)
+			default:
+				err = ndjsonEncoder.Encode(activityRecord)
+			}
+			if err != nil {
+				slog.Debug("ExportActivityRecordsWriteError", slog.String("err", err.Error()))
+				return responseDto, errors.New("ExportActivityRecordsWriteError")
+			}
+			responseDto.ExportedRecordsCount++
+		}
+
+		csvWriter.Flush()
+		err = csvWriter.Error()
+		if err != nil {
+			slog.Debug("ExportActivityRecordsWriteError", slog.String("err", err.Error()))
+			return responseDto, errors.New("ExportActivityRecordsWriteError")
+		}
+		if isOutputFlushable {
+			outputFlusher.Flush()
+		}
+
+		lastRecordId := readResponseDto.ActivityRecords[len(readResponseDto.ActivityRecords)-1].RecordId
+		lastSeenId := tkValueObject.PaginationLastSeenId(
+			strconv.FormatUint(lastRecordId.Uint64(), 10),
+		)
+		readRequestDto.Pagination.LastSeenId = &lastSeenId
+	}
+
+	csvWriter.Flush()
+	err = csvWriter.Error()
+	if err != nil {
+		return responseDto, errors.New("ExportActivityRecordsWriteError")
+	}
+
+	return responseDto, nil
+}
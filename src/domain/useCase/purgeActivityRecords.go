@@ -0,0 +1,57 @@
+package tkUseCase
+
+import (
+	"errors"
+	"log/slog"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+var (
+	ActivityRecordCodeActivityRecordsPurged      tkValueObject.ActivityRecordCode = "ActivityRecordsPurged"
+	ActivityRecordCodeActivityRecordsPurgeFailed tkValueObject.ActivityRecordCode = "ActivityRecordsPurgeFailed"
+)
+
+// PurgeActivityRecords deletes the activity records that expired according to the
+// retention rules. Every run, successful or not, leaves its own summary activity record.
+func PurgeActivityRecords(
+	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo,
+	purgeDto tkDto.PurgeActivityRecordsRequest,
+) (responseDto tkDto.PurgeActivityRecordsResponse, err error) {
+	if len(purgeDto.RetentionRules) == 0 {
+		return responseDto, errors.New("RetentionRulesCannotBeEmpty")
+	}
+
+	for _, retentionRule := range purgeDto.RetentionRules {
+		if retentionRule.MaxAge == nil && retentionRule.MaxRecordsCount == nil {
+			return responseDto, errors.New("RetentionRuleMustHaveMaxAgeOrMaxRecordsCount")
+		}
+		if retentionRule.MaxAge != nil && *retentionRule.MaxAge <= 0 {
+			return responseDto, errors.New("RetentionRuleMaxAgeMustBePositive")
+		}
+	}
+
+	responseDto, err = activityRecordCmdRepo.Purge(purgeDto)
+	if err != nil {
+		slog.Error("PurgeActivityRecordsInfraError", slog.String("err", err.Error()))
+		CreateActivityRecord(activityRecordCmdRepo, tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelError,
+			RecordCode:  ActivityRecordCodeActivityRecordsPurgeFailed,
+			RecordDetails: map[string]any{
+				"purgedRecordsCount": responseDto.PurgedRecordsCount,
+				"err":                err.Error(),
+			},
+		})
+		return responseDto, errors.New("PurgeActivityRecordsInfraError")
+	}
+
+	CreateActivityRecord(activityRecordCmdRepo, tkDto.CreateActivityRecord{
+		RecordLevel:   tkValueObject.ActivityRecordLevelInfo,
+		RecordCode:    ActivityRecordCodeActivityRecordsPurged,
+		RecordDetails: responseDto,
+	})
+
+	return responseDto, nil
+}
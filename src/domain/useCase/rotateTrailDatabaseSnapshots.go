@@ -0,0 +1,58 @@
+package tkUseCase
+
+import (
+	"errors"
+	"log/slog"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+)
+
+// RotateTrailDatabaseSnapshots takes a new trail database snapshot and deletes the
+// oldest ones beyond MaxSnapshotsCount. Nothing is deleted when the snapshot fails, so
+// a broken backup never costs the previous ones.
+func RotateTrailDatabaseSnapshots(
+	snapshotRepo tkRepository.TrailDatabaseSnapshotRepo,
+	rotateDto tkDto.RotateTrailDatabaseSnapshotsRequest,
+) (responseDto tkDto.RotateTrailDatabaseSnapshotsResponse, err error) {
+	if rotateDto.MaxSnapshotsCount == 0 {
+		return responseDto, errors.New("MaxSnapshotsCountMustBePositive")
+	}
+
+	responseDto.CreatedSnapshot, err = snapshotRepo.Create(tkDto.CreateTrailDatabaseSnapshot{
+		SnapshotsDirPath:  rotateDto.SnapshotsDirPath,
+		CompressionFormat: rotateDto.CompressionFormat,
+	})
+	if err != nil {
+		slog.Error("CreateTrailDatabaseSnapshotInfraError", slog.String("err", err.Error()))
+		return responseDto, errors.New("CreateTrailDatabaseSnapshotInfraError")
+	}
+
+	snapshots, err := snapshotRepo.Read(rotateDto.SnapshotsDirPath)
+	if err != nil {
+		slog.Error("ReadTrailDatabaseSnapshotsInfraError", slog.String("err", err.Error()))
+		return responseDto, errors.New("ReadTrailDatabaseSnapshotsInfraError")
+	}
+
+	maxSnapshotsCount := int(rotateDto.MaxSnapshotsCount)
+	if len(snapshots) <= maxSnapshotsCount {
+		return responseDto, nil
+	}
+
+	for _, expiredSnapshot := range snapshots[maxSnapshotsCount:] {
+		err = snapshotRepo.Delete(expiredSnapshot.FilePath)
+		if err != nil {
+			slog.Error(
+				"DeleteTrailDatabaseSnapshotInfraError",
+				slog.String("filePath", expiredSnapshot.FilePath.String()),
+				slog.String("err", err.Error()),
+			)
+			return responseDto, errors.New("DeleteTrailDatabaseSnapshotInfraError")
+		}
+		responseDto.DeletedSnapshotFilePaths = append(
+			responseDto.DeletedSnapshotFilePaths, expiredSnapshot.FilePath,
+		)
+	}
+
+	return responseDto, nil
+}
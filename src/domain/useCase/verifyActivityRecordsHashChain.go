@@ -0,0 +1,39 @@
+package tkUseCase
+
+import (
+	"errors"
+	"log/slog"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+)
+
+// VerifyActivityRecordsHashChain walks the activity records hash chain and reports the
+// first broken link, proving (or disproving) that the audit trail was not edited
+// after the fact.
+func VerifyActivityRecordsHashChain(
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo,
+) (responseDto tkDto.VerifyActivityRecordsHashChainResponse, err error) {
+	responseDto, err = activityRecordQueryRepo.VerifyHashChain()
+	if err != nil {
+		slog.Error("VerifyActivityRecordsHashChainInfraError", slog.String("err", err.Error()))
+		return responseDto, errors.New("VerifyActivityRecordsHashChainInfraError")
+	}
+
+	if !responseDto.IsIntact {
+		brokenLinkAttrs := []any{}
+		if responseDto.FirstBrokenRecordId != nil {
+			brokenLinkAttrs = append(
+				brokenLinkAttrs, slog.Uint64("recordId", responseDto.FirstBrokenRecordId.Uint64()),
+			)
+		}
+		if responseDto.BrokenLinkReason != nil {
+			brokenLinkAttrs = append(
+				brokenLinkAttrs, slog.String("reason", *responseDto.BrokenLinkReason),
+			)
+		}
+		slog.Warn("ActivityRecordsHashChainBroken", brokenLinkAttrs...)
+	}
+
+	return responseDto, nil
+}
@@ -0,0 +1,51 @@
+package tkValueObject
+
+import (
+	"errors"
+	"strings"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+var (
+	ActivityRecordAggregationDimensionRecordCode           ActivityRecordAggregationDimension = "recordCode"
+	ActivityRecordAggregationDimensionRecordLevel          ActivityRecordAggregationDimension = "recordLevel"
+	ActivityRecordAggregationDimensionOperatorSri          ActivityRecordAggregationDimension = "operatorSri"
+	ActivityRecordAggregationDimensionAffectedResourceType ActivityRecordAggregationDimension = "affectedResourceType"
+)
+
+// ActivityRecordAggregationDimension is a field the activity records can be grouped by
+// when counting them.
+type ActivityRecordAggregationDimension string
+
+func NewActivityRecordAggregationDimension(value any) (
+	aggregationDimension ActivityRecordAggregationDimension, err error,
+) {
+	if existentDimension, assertOk := value.(ActivityRecordAggregationDimension); assertOk {
+		return existentDimension, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return aggregationDimension, errors.New(
+			"ActivityRecordAggregationDimensionMustBeString",
+		)
+	}
+
+	switch strings.ToLower(strings.TrimSpace(stringValue)) {
+	case "recordcode", "code":
+		return ActivityRecordAggregationDimensionRecordCode, nil
+	case "recordlevel", "level":
+		return ActivityRecordAggregationDimensionRecordLevel, nil
+	case "operatorsri", "operator":
+		return ActivityRecordAggregationDimensionOperatorSri, nil
+	case "affectedresourcetype", "resourcetype":
+		return ActivityRecordAggregationDimensionAffectedResourceType, nil
+	default:
+		return aggregationDimension, errors.New("InvalidActivityRecordAggregationDimension")
+	}
+}
+
+func (vo ActivityRecordAggregationDimension) String() string {
+	return string(vo)
+}
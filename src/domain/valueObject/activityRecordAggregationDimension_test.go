@@ -0,0 +1,46 @@
+package tkValueObject
+
+import (
+	"testing"
+)
+
+func TestNewActivityRecordAggregationDimension(t *testing.T) {
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput ActivityRecordAggregationDimension
+			expectError    bool
+		}{
+			{"recordCode", ActivityRecordAggregationDimensionRecordCode, false},
+			{"code", ActivityRecordAggregationDimensionRecordCode, false},
+			{"RECORDLEVEL", ActivityRecordAggregationDimensionRecordLevel, false},
+			{"level", ActivityRecordAggregationDimensionRecordLevel, false},
+			{"operatorSri", ActivityRecordAggregationDimensionOperatorSri, false},
+			{"affectedResourceType", ActivityRecordAggregationDimensionAffectedResourceType, false},
+			{" resourceType ", ActivityRecordAggregationDimensionAffectedResourceType, false},
+			{
+				ActivityRecordAggregationDimensionOperatorSri,
+				ActivityRecordAggregationDimensionOperatorSri, false,
+			},
+			// Invalid aggregation dimensions
+			{"", ActivityRecordAggregationDimension(""), true},
+			{"recordDetails", ActivityRecordAggregationDimension(""), true},
+			{"record_code; DROP TABLE", ActivityRecordAggregationDimension(""), true},
+			{123, ActivityRecordAggregationDimension(""), true},
+			{[]string{"code"}, ActivityRecordAggregationDimension(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewActivityRecordAggregationDimension(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,63 @@
+package tkValueObject
+
+import (
+	"errors"
+	"regexp"
+	"strings"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+// Dot separated keys, each optionally followed by array indexes (e.g. "user.emails[0]").
+// Quotes, backslashes and any other JSON path syntax are purposely rejected since the
+// path ends up inside SQL json functions.
+var activityRecordDetailsPathRegex = regexp.MustCompile(
+	`^[\p{L}\d\_\-]{1,64}(\[\d{1,6}\])*(\.[\p{L}\d\_\-]{1,64}(\[\d{1,6}\])*){0,15}$`,
+)
+
+var activityRecordDetailsPathSegmentRegex = regexp.MustCompile(
+	`^([\p{L}\d\_\-]+)((?:\[\d+\])*)$`,
+)
+
+// ActivityRecordDetailsPath is the location of a value inside the RecordDetails JSON,
+// relative to its root. The "$." JSON path root prefix is optional.
+type ActivityRecordDetailsPath string
+
+func NewActivityRecordDetailsPath(value any) (
+	detailsPath ActivityRecordDetailsPath, err error,
+) {
+	if existentDetailsPath, assertOk := value.(ActivityRecordDetailsPath); assertOk {
+		return existentDetailsPath, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return detailsPath, errors.New("ActivityRecordDetailsPathMustBeString")
+	}
+	stringValue = strings.TrimPrefix(strings.TrimSpace(stringValue), "$.")
+
+	if !activityRecordDetailsPathRegex.MatchString(stringValue) {
+		return detailsPath, errors.New("InvalidActivityRecordDetailsPath")
+	}
+
+	return ActivityRecordDetailsPath(stringValue), nil
+}
+
+func (vo ActivityRecordDetailsPath) String() string {
+	return string(vo)
+}
+
+// ReadAsJsonPath returns the path in the JSON path syntax understood by the SQL json
+// functions, with every key double quoted (e.g. `$."user"."emails"[0]`).
+func (vo ActivityRecordDetailsPath) ReadAsJsonPath() string {
+	jsonPathBuilder := strings.Builder{}
+	jsonPathBuilder.WriteString("$")
+	for pathSegment := range strings.SplitSeq(string(vo), ".") {
+		segmentParts := activityRecordDetailsPathSegmentRegex.FindStringSubmatch(pathSegment)
+		if len(segmentParts) != 3 {
+			continue
+		}
+		jsonPathBuilder.WriteString(`."` + segmentParts[1] + `"` + segmentParts[2])
+	}
+	return jsonPathBuilder.String()
+}
@@ -0,0 +1,71 @@
+package tkValueObject
+
+import (
+	"testing"
+)
+
+func TestNewActivityRecordDetailsPath(t *testing.T) {
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput ActivityRecordDetailsPath
+			expectError    bool
+		}{
+			{"domain", ActivityRecordDetailsPath("domain"), false},
+			{"user.name", ActivityRecordDetailsPath("user.name"), false},
+			{"items[0].size", ActivityRecordDetailsPath("items[0].size"), false},
+			{"matrix[1][2]", ActivityRecordDetailsPath("matrix[1][2]"), false},
+			{"$.virtual-host.fqdn", ActivityRecordDetailsPath("virtual-host.fqdn"), false},
+			{"nome_do_usuário", ActivityRecordDetailsPath("nome_do_usuário"), false},
+			{123, ActivityRecordDetailsPath("123"), false},
+			{ActivityRecordDetailsPath("domain"), ActivityRecordDetailsPath("domain"), false},
+			// Invalid details paths
+			{"", ActivityRecordDetailsPath(""), true},
+			{"$", ActivityRecordDetailsPath(""), true},
+			{"user..name", ActivityRecordDetailsPath(""), true},
+			{".domain", ActivityRecordDetailsPath(""), true},
+			{"items[]", ActivityRecordDetailsPath(""), true},
+			{"items[-1]", ActivityRecordDetailsPath(""), true},
+			{"items[#]", ActivityRecordDetailsPath(""), true},
+			{`domain"`, ActivityRecordDetailsPath(""), true},
+			{"domain') OR 1=1 --", ActivityRecordDetailsPath(""), true},
+			{"domain; DROP TABLE activity_records", ActivityRecordDetailsPath(""), true},
+			{`user\.name`, ActivityRecordDetailsPath(""), true},
+			{"a.b.c.d.e.f.g.h.i.j.k.l.m.n.o.p.q", ActivityRecordDetailsPath(""), true},
+			{[]string{"domain"}, ActivityRecordDetailsPath(""), true},
+			{nil, ActivityRecordDetailsPath(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewActivityRecordDetailsPath(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+
+	t.Run("ReadAsJsonPath", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     ActivityRecordDetailsPath
+			expectedOutput string
+		}{
+			{ActivityRecordDetailsPath("domain"), `$."domain"`},
+			{ActivityRecordDetailsPath("user.name"), `$."user"."name"`},
+			{ActivityRecordDetailsPath("items[0].size"), `$."items"[0]."size"`},
+			{ActivityRecordDetailsPath("matrix[1][2]"), `$."matrix"[1][2]`},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput := testCase.inputValue.ReadAsJsonPath()
+			if actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,59 @@
+package tkValueObject
+
+import (
+	"errors"
+	"strings"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+var (
+	ComparisonOperatorEqual              ComparisonOperator = "eq"
+	ComparisonOperatorNotEqual           ComparisonOperator = "ne"
+	ComparisonOperatorGreaterThan        ComparisonOperator = "gt"
+	ComparisonOperatorGreaterThanOrEqual ComparisonOperator = "gte"
+	ComparisonOperatorLessThan           ComparisonOperator = "lt"
+	ComparisonOperatorLessThanOrEqual    ComparisonOperator = "lte"
+	ComparisonOperatorContains           ComparisonOperator = "contains"
+)
+
+type ComparisonOperator string
+
+func NewComparisonOperator(value any) (operator ComparisonOperator, err error) {
+	if existentOperator, assertOk := value.(ComparisonOperator); assertOk {
+		return existentOperator, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return operator, errors.New("ComparisonOperatorMustBeString")
+	}
+	stringValue = strings.ToLower(strings.TrimSpace(stringValue))
+
+	stringValueVo := ComparisonOperator(stringValue)
+	switch stringValueVo {
+	case ComparisonOperatorEqual, ComparisonOperatorNotEqual,
+		ComparisonOperatorGreaterThan, ComparisonOperatorGreaterThanOrEqual,
+		ComparisonOperatorLessThan, ComparisonOperatorLessThanOrEqual,
+		ComparisonOperatorContains:
+		return stringValueVo, nil
+	case "==", "=":
+		return ComparisonOperatorEqual, nil
+	case "!=", "<>":
+		return ComparisonOperatorNotEqual, nil
+	case ">":
+		return ComparisonOperatorGreaterThan, nil
+	case ">=":
+		return ComparisonOperatorGreaterThanOrEqual, nil
+	case "<":
+		return ComparisonOperatorLessThan, nil
+	case "<=":
+		return ComparisonOperatorLessThanOrEqual, nil
+	default:
+		return operator, errors.New("InvalidComparisonOperator")
+	}
+}
+
+func (vo ComparisonOperator) String() string {
+	return string(vo)
+}
@@ -0,0 +1,53 @@
+package tkValueObject
+
+import (
+	"testing"
+)
+
+func TestNewComparisonOperator(t *testing.T) {
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput ComparisonOperator
+			expectError    bool
+		}{
+			{"eq", ComparisonOperatorEqual, false},
+			{"NE", ComparisonOperatorNotEqual, false},
+			{"gt", ComparisonOperatorGreaterThan, false},
+			{"gte", ComparisonOperatorGreaterThanOrEqual, false},
+			{"lt", ComparisonOperatorLessThan, false},
+			{"lte", ComparisonOperatorLessThanOrEqual, false},
+			{"contains", ComparisonOperatorContains, false},
+			{"==", ComparisonOperatorEqual, false},
+			{"=", ComparisonOperatorEqual, false},
+			{"!=", ComparisonOperatorNotEqual, false},
+			{"<>", ComparisonOperatorNotEqual, false},
+			{" > ", ComparisonOperatorGreaterThan, false},
+			{">=", ComparisonOperatorGreaterThanOrEqual, false},
+			{"<", ComparisonOperatorLessThan, false},
+			{"<=", ComparisonOperatorLessThanOrEqual, false},
+			{ComparisonOperatorContains, ComparisonOperatorContains, false},
+			// Invalid comparison operators
+			{"", ComparisonOperator(""), true},
+			{"like", ComparisonOperator(""), true},
+			{"===", ComparisonOperator(""), true},
+			{"=>", ComparisonOperator(""), true},
+			{123, ComparisonOperator(""), true},
+			{true, ComparisonOperator(""), true},
+			{[]string{"eq"}, ComparisonOperator(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewComparisonOperator(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,31 @@
+package tkValueObject
+
+import (
+	"errors"
+	"regexp"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+var correlationIdRegex = regexp.MustCompile(`^[a-zA-Z0-9][\w.:@/+=-]{0,127}$`)
+
+// CorrelationId ties together the activity records produced while handling the same
+// request (usually the X-Request-Id header), so it's restricted to header safe chars.
+type CorrelationId string
+
+func NewCorrelationId(value any) (correlationId CorrelationId, err error) {
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return correlationId, errors.New("CorrelationIdMustBeString")
+	}
+
+	if !correlationIdRegex.MatchString(stringValue) {
+		return correlationId, errors.New("InvalidCorrelationId")
+	}
+
+	return CorrelationId(stringValue), nil
+}
+
+func (vo CorrelationId) String() string {
+	return string(vo)
+}
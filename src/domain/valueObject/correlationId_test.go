@@ -0,0 +1,58 @@
+package tkValueObject
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestNewCorrelationId(t *testing.T) {
+	t.Run("ValidCorrelationId", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue  any
+			expectError bool
+		}{
+			{"f47ac10b-58cc-4372-a567-0e02b2c3d479", false},
+			{"KX3MZQ7ZB4RWHGUYQ2LXJ6T5VA", false},
+			{"req_1234", false},
+			{"gateway:eu-west/42", false},
+			{"a", false},
+			{1234, false},
+			{strings.Repeat("a", 128), false},
+		}
+
+		for _, testCase := range testCaseStructs {
+			_, err := NewCorrelationId(testCase.inputValue)
+			if testCase.expectError && err == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", err.Error(), testCase.inputValue)
+			}
+		}
+	})
+
+	t.Run("InvalidCorrelationId", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue  any
+			expectError bool
+		}{
+			{"", true},
+			{"-leadingDash", true},
+			{"with space", true},
+			{"line\nbreak", true},
+			{"<script>", true},
+			{strings.Repeat("a", 129), true},
+			{[]string{"req_1234"}, true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			_, err := NewCorrelationId(testCase.inputValue)
+			if testCase.expectError && err == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", err.Error(), testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,56 @@
+package tkValueObject
+
+import (
+	"errors"
+	"strings"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+var (
+	ExportFormatNdjson ExportFormat = "ndjson"
+	ExportFormatCsv    ExportFormat = "csv"
+)
+
+type ExportFormat string
+
+func NewExportFormat(value any) (exportFormat ExportFormat, err error) {
+	if existentExportFormat, assertOk := value.(ExportFormat); assertOk {
+		return existentExportFormat, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return exportFormat, errors.New("ExportFormatMustBeString")
+	}
+	stringValue = strings.TrimPrefix(stringValue, ".")
+	stringValue = strings.ToLower(stringValue)
+
+	switch stringValue {
+	case "jsonl", "json-lines", "jsonlines":
+		stringValue = "ndjson"
+	case "":
+		return exportFormat, errors.New("ExportFormatCannotBeEmpty")
+	}
+
+	stringValueVo := ExportFormat(stringValue)
+	switch stringValueVo {
+	case ExportFormatNdjson, ExportFormatCsv:
+		return stringValueVo, nil
+	default:
+		return exportFormat, errors.New("InvalidExportFormat")
+	}
+}
+
+func (vo ExportFormat) String() string {
+	return string(vo)
+}
+
+func (vo ExportFormat) ReadMimeType() MimeType {
+	switch vo {
+	case ExportFormatCsv:
+		return MimeType("text/csv")
+	default:
+		return MimeType("application/x-ndjson")
+	}
+}
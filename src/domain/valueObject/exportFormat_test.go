@@ -0,0 +1,58 @@
+package tkValueObject
+
+import (
+	"testing"
+)
+
+func TestNewExportFormat(t *testing.T) {
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput ExportFormat
+			expectError    bool
+		}{
+			{"ndjson", ExportFormatNdjson, false},
+			{"NDJSON", ExportFormatNdjson, false},
+			{"jsonl", ExportFormatNdjson, false},
+			{".csv", ExportFormatCsv, false},
+			{"CSV", ExportFormatCsv, false},
+			{ExportFormatCsv, ExportFormatCsv, false},
+			// Invalid export formats
+			{"", ExportFormat(""), true},
+			{"json", ExportFormat(""), true},
+			{"xlsx", ExportFormat(""), true},
+			{123, ExportFormat(""), true},
+			{[]string{"csv"}, ExportFormat(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewExportFormat(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+
+	t.Run("ReadMimeType", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     ExportFormat
+			expectedOutput string
+		}{
+			{ExportFormatNdjson, "application/x-ndjson"},
+			{ExportFormatCsv, "text/csv"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput := testCase.inputValue.ReadMimeType().String()
+			if actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,60 @@
+package tkValueObject
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"regexp"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+const paginationCursorMaxLength int = 4096
+
+var paginationCursorRegex = regexp.MustCompile(`^[A-Za-z0-9\-\_]+$`)
+
+// PaginationCursor is an opaque keyset pagination token: a base64url encoded JSON
+// object describing the last item of a page. Its content is produced and interpreted
+// by the infrastructure layer only.
+type PaginationCursor string
+
+func NewPaginationCursor(value any) (cursor PaginationCursor, err error) {
+	if existentCursor, assertOk := value.(PaginationCursor); assertOk {
+		return existentCursor, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return cursor, errors.New("PaginationCursorMustBeString")
+	}
+
+	if len(stringValue) > paginationCursorMaxLength {
+		return cursor, errors.New("PaginationCursorTooLong")
+	}
+
+	if !paginationCursorRegex.MatchString(stringValue) {
+		return cursor, errors.New("InvalidPaginationCursor")
+	}
+
+	cursorBytes, err := base64.RawURLEncoding.DecodeString(stringValue)
+	if err != nil {
+		return cursor, errors.New("InvalidPaginationCursor")
+	}
+
+	cursorPayload := map[string]any{}
+	err = json.Unmarshal(cursorBytes, &cursorPayload)
+	if err != nil {
+		return cursor, errors.New("InvalidPaginationCursor")
+	}
+
+	return PaginationCursor(stringValue), nil
+}
+
+func (vo PaginationCursor) String() string {
+	return string(vo)
+}
+
+// ReadPayloadBytes returns the decoded JSON payload of the cursor.
+func (vo PaginationCursor) ReadPayloadBytes() ([]byte, error) {
+	return base64.RawURLEncoding.DecodeString(string(vo))
+}
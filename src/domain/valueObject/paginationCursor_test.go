@@ -0,0 +1,54 @@
+package tkValueObject
+
+import (
+	"encoding/base64"
+	"testing"
+)
+
+func TestNewPaginationCursor(t *testing.T) {
+	validCursor := base64.RawURLEncoding.EncodeToString([]byte(`{"c":"id","d":"desc"}`))
+	arrayCursor := base64.RawURLEncoding.EncodeToString([]byte(`["id"]`))
+	paddedCursor := base64.URLEncoding.EncodeToString([]byte(`{"c":"id"}`))
+
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput PaginationCursor
+			expectError    bool
+		}{
+			{validCursor, PaginationCursor(validCursor), false},
+			{PaginationCursor(validCursor), PaginationCursor(validCursor), false},
+			// Invalid pagination cursors
+			{"", PaginationCursor(""), true},
+			{"notBase64!", PaginationCursor(""), true},
+			{"bm90SnNvbg", PaginationCursor(""), true},
+			{arrayCursor, PaginationCursor(""), true},
+			{paddedCursor, PaginationCursor(""), true},
+			{123, PaginationCursor(""), true},
+			{[]string{validCursor}, PaginationCursor(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewPaginationCursor(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+
+	t.Run("ReadPayloadBytes", func(t *testing.T) {
+		payloadBytes, err := PaginationCursor(validCursor).ReadPayloadBytes()
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if string(payloadBytes) != `{"c":"id","d":"desc"}` {
+			t.Errorf("UnexpectedOutputValue: '%s'", string(payloadBytes))
+		}
+	})
+}
@@ -2,23 +2,78 @@ package tkValueObject
 
 import (
 	"errors"
+	"math"
 	"regexp"
+	"strconv"
+	"strings"
+	"time"
 
 	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
 )
 
 var relativeTimeRegex = regexp.MustCompile(`^(?i)(\d+(?:\.\d+)?)\s*(second|minute|hour|day|week|month|year|s|m|h|d|w|M|y)(?:s?)\s*(ago|from now)?$`)
 
+// relativeTimeUnitDurations uses fixed lengths for the calendar units, so a month is
+// 30 days and a year is 365 days.
+var relativeTimeUnitDurations = map[string]time.Duration{
+	"second": time.Second,
+	"s":      time.Second,
+	"minute": time.Minute,
+	"m":      time.Minute,
+	"hour":   time.Hour,
+	"h":      time.Hour,
+	"day":    24 * time.Hour,
+	"d":      24 * time.Hour,
+	"week":   7 * 24 * time.Hour,
+	"w":      7 * 24 * time.Hour,
+	"month":  30 * 24 * time.Hour,
+	"year":   365 * 24 * time.Hour,
+	"y":      365 * 24 * time.Hour,
+}
+
+// RelativeTime is an offset from now such as "24h ago" or "2 days from now". Without
+// "ago" nor "from now" it's in the past, as it's mostly used to filter past events.
 type RelativeTime string
 
+// relativeTimeDurationParser returns the signed offset from now, negative for the past.
+func relativeTimeDurationParser(stringValue string) (time.Duration, error) {
+	regexMatches := relativeTimeRegex.FindStringSubmatch(stringValue)
+	if regexMatches == nil {
+		return 0, errors.New("InvalidRelativeTime")
+	}
+
+	amount, err := strconv.ParseFloat(regexMatches[1], 64)
+	if err != nil {
+		return 0, errors.New("InvalidRelativeTime")
+	}
+
+	rawUnit := regexMatches[2]
+	unitDuration := 30 * 24 * time.Hour
+	if rawUnit != "M" {
+		unitDuration = relativeTimeUnitDurations[strings.ToLower(rawUnit)]
+	}
+
+	offsetNanoseconds := amount * float64(unitDuration)
+	if offsetNanoseconds >= math.MaxInt64 {
+		return 0, errors.New("RelativeTimeOutOfRange")
+	}
+
+	offsetDuration := time.Duration(offsetNanoseconds)
+	if strings.ToLower(regexMatches[3]) == "from now" {
+		return offsetDuration, nil
+	}
+	return -offsetDuration, nil
+}
+
 func NewRelativeTime(value any) (relativeTime RelativeTime, err error) {
 	stringValue, err := tkVoUtil.InterfaceToString(value)
 	if err != nil {
 		return relativeTime, errors.New("RelativeTimeMustBeString")
 	}
 
-	if !relativeTimeRegex.MatchString(stringValue) {
-		return relativeTime, errors.New("InvalidRelativeTime")
+	_, err = relativeTimeDurationParser(stringValue)
+	if err != nil {
+		return relativeTime, err
 	}
 
 	return RelativeTime(stringValue), nil
@@ -27,3 +82,13 @@ func NewRelativeTime(value any) (relativeTime RelativeTime, err error) {
 func (vo RelativeTime) String() string {
 	return string(vo)
 }
+
+// ReadAsDuration returns the signed offset from now, negative for the past.
+func (vo RelativeTime) ReadAsDuration() time.Duration {
+	offsetDuration, _ := relativeTimeDurationParser(string(vo))
+	return offsetDuration
+}
+
+func (vo RelativeTime) ReadAsUnixTime() UnixTime {
+	return NewUnixTimeWithGoTime(time.Now().Add(vo.ReadAsDuration()))
+}
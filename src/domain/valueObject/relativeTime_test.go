@@ -2,6 +2,7 @@ package tkValueObject
 
 import (
 	"testing"
+	"time"
 )
 
 func TestNewRelativeTime(t *testing.T) {
@@ -73,4 +74,42 @@ func TestNewRelativeTime(t *testing.T) {
 			}
 		}
 	})
+	t.Run("OutOfRange", func(t *testing.T) {
+		_, err := NewRelativeTime("300 years ago")
+		if err == nil || err.Error() != "RelativeTimeOutOfRange" {
+			t.Errorf("MissingExpectedError: RelativeTimeOutOfRange (got %v)", err)
+		}
+	})
+
+	t.Run("ReadAsDuration", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     RelativeTime
+			expectedOutput time.Duration
+		}{
+			{RelativeTime("30s"), -30 * time.Second},
+			{RelativeTime("5m"), -5 * time.Minute},
+			{RelativeTime("6M from now"), 6 * 30 * 24 * time.Hour},
+			{RelativeTime("24h ago"), -24 * time.Hour},
+			{RelativeTime("1.5 hours ago"), -90 * time.Minute},
+			{RelativeTime("2 Days From Now"), 48 * time.Hour},
+			{RelativeTime("1 week ago"), -7 * 24 * time.Hour},
+			{RelativeTime("2y"), -2 * 365 * 24 * time.Hour},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput := testCase.inputValue.ReadAsDuration()
+			if actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+
+	t.Run("ReadAsUnixTime", func(t *testing.T) {
+		expectedUnixTime := NewUnixTimeBeforeNow(24 * time.Hour)
+		actualUnixTime := RelativeTime("24h ago").ReadAsUnixTime()
+		if actualUnixTime.Int64()-expectedUnixTime.Int64() > 1 ||
+			actualUnixTime.Int64() < expectedUnixTime.Int64() {
+			t.Errorf("UnexpectedOutputValue: '%v' vs '%v'", actualUnixTime, expectedUnixTime)
+		}
+	})
 }
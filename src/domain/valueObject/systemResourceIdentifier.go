@@ -4,6 +4,7 @@ import (
 	"errors"
 	"log/slog"
 	"regexp"
+	"strings"
 
 	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
 )
@@ -92,6 +93,55 @@ func (vo SystemResourceIdentifier) ReadResourceId() (resourceId SystemResourceId
 	return NewSystemResourceId(sriComponents[3])
 }
 
+// IsWildcard reports whether the SRI stands for all the resources of its type within
+// its account, i.e. its resourceId is "*".
+func (vo SystemResourceIdentifier) IsWildcard() bool {
+	sriComponents := vo.readComponents()
+	return len(sriComponents) >= 4 && sriComponents[3] == "*"
+}
+
+// ReadOwnedAccountId returns the account ID an account SRI
+// (sri://0:account/<accountId>) stands for, i.e. the owner of the resources the SRI
+// covers hierarchically.
+func (vo SystemResourceIdentifier) ReadOwnedAccountId() (accountId AccountId, err error) {
+	sriComponents := vo.readComponents()
+	isAccountSri := len(sriComponents) >= 4 && sriComponents[2] == "account" &&
+		strings.TrimLeft(sriComponents[1], "0") == ""
+	if !isAccountSri {
+		return accountId, errors.New("SystemResourceIdentifierIsNotAnAccount")
+	}
+	return NewAccountId(sriComponents[3])
+}
+
+// Matches reports whether the SRI, used as a filter, matches the other SRI. Besides
+// the identical SRI, a wildcard SRI (sri://<accountId>:<resourceType>/*) matches every
+// resource of the type within the account and an account SRI
+// (sri://0:account/<accountId>) matches every resource of that account.
+func (vo SystemResourceIdentifier) Matches(otherSri SystemResourceIdentifier) bool {
+	if vo == otherSri {
+		return true
+	}
+
+	otherAccountId, err := otherSri.ReadAccountId()
+	if err != nil {
+		return false
+	}
+
+	ownedAccountId, err := vo.ReadOwnedAccountId()
+	if err == nil && ownedAccountId == otherAccountId {
+		return true
+	}
+
+	if !vo.IsWildcard() {
+		return false
+	}
+	sriAccountId, err := vo.ReadAccountId()
+	if err != nil || sriAccountId != otherAccountId {
+		return false
+	}
+	return vo.readComponents()[2] == otherSri.readComponents()[2]
+}
+
 func NewSriAccount(accountId AccountId) SystemResourceIdentifier {
 	return NewSystemResourceIdentifierMustCreate(
 		"sri://0:account/" + accountId.String(),
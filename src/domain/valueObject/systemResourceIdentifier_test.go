@@ -225,4 +225,90 @@ func TestSystemResourceIdentifier(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("IsWildcard", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputSri           string
+			expectedIsWildcard bool
+		}{
+			{"sri://12:mailbox/*", true},
+			{"sri://12:mailbox/info", false},
+			{"sri://0:account/*", true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sri := NewSystemResourceIdentifierMustCreate(testCase.inputSri)
+			if sri.IsWildcard() != testCase.expectedIsWildcard {
+				t.Errorf(
+					"UnexpectedIsWildcard: expected %t for %s",
+					testCase.expectedIsWildcard, testCase.inputSri,
+				)
+			}
+		}
+	})
+
+	t.Run("ReadOwnedAccountId", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputSri          string
+			expectedAccountId string
+			expectError       bool
+		}{
+			{"sri://0:account/12", "12", false},
+			{"sri://00:account/7", "7", false},
+			{"sri://0:account/*", "", true},
+			{"sri://12:account/13", "", true},
+			{"sri://12:mailbox/info", "", true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sri := NewSystemResourceIdentifierMustCreate(testCase.inputSri)
+			accountId, err := sri.ReadOwnedAccountId()
+			if testCase.expectError && err == nil {
+				t.Errorf("MissingExpectedError: [%s]", testCase.inputSri)
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.inputSri)
+			}
+			if !testCase.expectError && accountId.String() != testCase.expectedAccountId {
+				t.Errorf(
+					"UnexpectedOwnedAccountId: expected %s, got %s",
+					testCase.expectedAccountId, accountId.String(),
+				)
+			}
+		}
+	})
+
+	t.Run("Matches", func(t *testing.T) {
+		testCaseStructs := []struct {
+			filterSri       string
+			otherSri        string
+			expectedMatches bool
+		}{
+			{"sri://12:mailbox/info", "sri://12:mailbox/info", true},
+			{"sri://12:mailbox/info", "sri://12:mailbox/sales", false},
+			{"sri://12:mailbox/*", "sri://12:mailbox/sales", true},
+			{"sri://12:mailbox/*", "sri://012:mailbox/sales", true},
+			{"sri://12:mailbox/*", "sri://12:mailbox/*", true},
+			{"sri://12:mailbox/*", "sri://13:mailbox/sales", false},
+			{"sri://12:mailbox/*", "sri://12:database/sales", false},
+			{"sri://0:account/12", "sri://0:account/12", true},
+			{"sri://0:account/12", "sri://12:mailbox/info", true},
+			{"sri://0:account/12", "sri://12:database/*", true},
+			{"sri://0:account/12", "sri://0:account/13", false},
+			{"sri://0:account/12", "sri://13:mailbox/info", false},
+			{"sri://0:account/*", "sri://0:account/13", true},
+			{"sri://0:account/*", "sri://13:mailbox/info", false},
+		}
+
+		for _, testCase := range testCaseStructs {
+			filterSri := NewSystemResourceIdentifierMustCreate(testCase.filterSri)
+			otherSri := NewSystemResourceIdentifierMustCreate(testCase.otherSri)
+			if filterSri.Matches(otherSri) != testCase.expectedMatches {
+				t.Errorf(
+					"UnexpectedMatches: expected %t for %s against %s",
+					testCase.expectedMatches, testCase.filterSri, testCase.otherSri,
+				)
+			}
+		}
+	})
 }
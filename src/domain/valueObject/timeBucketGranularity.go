@@ -0,0 +1,55 @@
+package tkValueObject
+
+import (
+	"errors"
+	"strings"
+
+	tkVoUtil "github.com/goinfinite/tk/src/domain/valueObject/util"
+)
+
+var (
+	TimeBucketGranularityHour  TimeBucketGranularity = "hour"
+	TimeBucketGranularityDay   TimeBucketGranularity = "day"
+	TimeBucketGranularityWeek  TimeBucketGranularity = "week"
+	TimeBucketGranularityMonth TimeBucketGranularity = "month"
+)
+
+// TimeBucketGranularity is the size of the time windows used to group time series.
+// Buckets are aligned to UTC and weeks start on Monday.
+type TimeBucketGranularity string
+
+func NewTimeBucketGranularity(value any) (granularity TimeBucketGranularity, err error) {
+	if existentGranularity, assertOk := value.(TimeBucketGranularity); assertOk {
+		return existentGranularity, nil
+	}
+
+	stringValue, err := tkVoUtil.InterfaceToString(value)
+	if err != nil {
+		return granularity, errors.New("TimeBucketGranularityMustBeString")
+	}
+	stringValue = strings.ToLower(strings.TrimSpace(stringValue))
+
+	switch stringValue {
+	case "h", "hourly":
+		stringValue = "hour"
+	case "d", "daily":
+		stringValue = "day"
+	case "w", "weekly":
+		stringValue = "week"
+	case "m", "monthly":
+		stringValue = "month"
+	}
+
+	stringValueVo := TimeBucketGranularity(stringValue)
+	switch stringValueVo {
+	case TimeBucketGranularityHour, TimeBucketGranularityDay,
+		TimeBucketGranularityWeek, TimeBucketGranularityMonth:
+		return stringValueVo, nil
+	default:
+		return granularity, errors.New("InvalidTimeBucketGranularity")
+	}
+}
+
+func (vo TimeBucketGranularity) String() string {
+	return string(vo)
+}
@@ -0,0 +1,40 @@
+package tkValueObject
+
+import (
+	"testing"
+)
+
+func TestNewTimeBucketGranularity(t *testing.T) {
+	t.Run("StringInput", func(t *testing.T) {
+		testCaseStructs := []struct {
+			inputValue     any
+			expectedOutput TimeBucketGranularity
+			expectError    bool
+		}{
+			{"hour", TimeBucketGranularityHour, false},
+			{"Daily", TimeBucketGranularityDay, false},
+			{"w", TimeBucketGranularityWeek, false},
+			{"MONTH", TimeBucketGranularityMonth, false},
+			{TimeBucketGranularityDay, TimeBucketGranularityDay, false},
+			// Invalid time bucket granularities
+			{"", TimeBucketGranularity(""), true},
+			{"minute", TimeBucketGranularity(""), true},
+			{"year", TimeBucketGranularity(""), true},
+			{123, TimeBucketGranularity(""), true},
+			{[]string{"day"}, TimeBucketGranularity(""), true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := NewTimeBucketGranularity(testCase.inputValue)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.inputValue)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.inputValue)
+			}
+			if !testCase.expectError && actualOutput != testCase.expectedOutput {
+				t.Errorf("UnexpectedOutputValue: '%v' vs '%v' [%v]", actualOutput, testCase.expectedOutput, testCase.inputValue)
+			}
+		}
+	})
+}
@@ -0,0 +1,327 @@
+package tkInfraActivityRecord
+
+import (
+	"errors"
+	"log/slog"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+)
+
+type ActivityRecordAsyncOverflowPolicy string
+
+const (
+	// ActivityRecordAsyncOverflowPolicyBlock makes Create wait until the queue has room.
+	ActivityRecordAsyncOverflowPolicyBlock ActivityRecordAsyncOverflowPolicy = "block"
+	// ActivityRecordAsyncOverflowPolicyDropNewest discards the record being created.
+	ActivityRecordAsyncOverflowPolicyDropNewest ActivityRecordAsyncOverflowPolicy = "dropNewest"
+	// ActivityRecordAsyncOverflowPolicyDropOldest discards the oldest queued record.
+	ActivityRecordAsyncOverflowPolicyDropOldest ActivityRecordAsyncOverflowPolicy = "dropOldest"
+)
+
+const (
+	activityRecordAsyncQueueCapacityDefault         uint = 4096
+	activityRecordAsyncBatchSizeDefault             uint = 100
+	activityRecordAsyncFlushIntervalMsDefault       uint = 1000
+	activityRecordAsyncPersistMaxAttemptsDefault    uint = 3
+	activityRecordAsyncPersistRetryBackoffMsDefault uint = 100
+
+	ErrActivityRecordAsyncCmdRepoClosed string = "ActivityRecordAsyncCmdRepoClosed"
+	ErrActivityRecordAsyncQueueFull     string = "ActivityRecordAsyncQueueFull"
+)
+
+// ActivityRecordAsyncCmdRepoSettings.SyncCmdRepo is the repository used to persist the
+// batches. When nil, NewActivityRecordCmdRepo is used. A batch failing to persist is
+// retried up to PersistMaxAttempts times (3 by default), waiting PersistRetryBackoffMs
+// (100 by default), doubled on each retry, in between.
+type ActivityRecordAsyncCmdRepoSettings struct {
+	SyncCmdRepo           *ActivityRecordCmdRepo
+	QueueCapacity         uint
+	BatchSize             uint
+	FlushIntervalMs       uint
+	OverflowPolicy        ActivityRecordAsyncOverflowPolicy
+	PersistMaxAttempts    uint
+	PersistRetryBackoffMs uint
+}
+
+// ActivityRecordAsyncCmdRepo is a tkRepository.ActivityRecordCmdRepo that queues the
+// CreateActivityRecord DTOs in memory and persists them in batched transactions by a
+// background worker, so the caller never waits for a disk write. A batch is flushed
+// when it reaches BatchSize or when FlushIntervalMs elapses, whichever comes first.
+//
+// The records of a batch still failing after the retries are lost: they're counted by
+// ReadDroppedCount and the error is returned by the next Flush (or Close).
+//
+// @attention Close MUST be called during shutdown, otherwise queued records are lost.
+type ActivityRecordAsyncCmdRepo struct {
+	syncCmdRepo         *ActivityRecordCmdRepo
+	batchSize           uint
+	flushInterval       time.Duration
+	overflowPolicy      ActivityRecordAsyncOverflowPolicy
+	persistMaxAttempts  uint
+	persistRetryBackoff time.Duration
+
+	queueChannel        chan tkDto.CreateActivityRecord
+	flushRequestChannel chan chan error
+	workerDoneChannel   chan struct{}
+
+	// queueMutex guards queueChannel against being closed while Create is sending.
+	queueMutex     sync.RWMutex
+	isClosed       bool
+	lastFlushErr   error
+	droppedCounter atomic.Uint64
+	// unreportedPersistErr is only accessed by the worker.
+	unreportedPersistErr error
+}
+
+func NewActivityRecordAsyncCmdRepo(
+	trailDbSvc *tkInfraDb.TrailDatabaseService,
+	settings ActivityRecordAsyncCmdRepoSettings,
+) *ActivityRecordAsyncCmdRepo {
+	queueCapacity := activityRecordAsyncQueueCapacityDefault
+	if settings.QueueCapacity != 0 {
+		queueCapacity = settings.QueueCapacity
+	}
+
+	batchSize := activityRecordAsyncBatchSizeDefault
+	if settings.BatchSize != 0 {
+		batchSize = settings.BatchSize
+	}
+
+	flushIntervalMs := activityRecordAsyncFlushIntervalMsDefault
+	if settings.FlushIntervalMs != 0 {
+		flushIntervalMs = settings.FlushIntervalMs
+	}
+
+	overflowPolicy := ActivityRecordAsyncOverflowPolicyBlock
+	switch settings.OverflowPolicy {
+	case ActivityRecordAsyncOverflowPolicyDropNewest,
+		ActivityRecordAsyncOverflowPolicyDropOldest:
+		overflowPolicy = settings.OverflowPolicy
+	}
+
+	persistMaxAttempts := activityRecordAsyncPersistMaxAttemptsDefault
+	if settings.PersistMaxAttempts != 0 {
+		persistMaxAttempts = settings.PersistMaxAttempts
+	}
+
+	persistRetryBackoffMs := activityRecordAsyncPersistRetryBackoffMsDefault
+	if settings.PersistRetryBackoffMs != 0 {
+		persistRetryBackoffMs = settings.PersistRetryBackoffMs
+	}
+
+	syncCmdRepo := settings.SyncCmdRepo
+	if syncCmdRepo == nil {
+		syncCmdRepo = NewActivityRecordCmdRepo(trailDbSvc)
+	}
+
+	repo := &ActivityRecordAsyncCmdRepo{
+		syncCmdRepo:         syncCmdRepo,
+		batchSize:           batchSize,
+		flushInterval:       time.Duration(flushIntervalMs) * time.Millisecond,
+		overflowPolicy:      overflowPolicy,
+		persistMaxAttempts:  persistMaxAttempts,
+		persistRetryBackoff: time.Duration(persistRetryBackoffMs) * time.Millisecond,
+		queueChannel:        make(chan tkDto.CreateActivityRecord, queueCapacity),
+		flushRequestChannel: make(chan chan error),
+		workerDoneChannel:   make(chan struct{}),
+	}
+	go repo.worker()
+
+	return repo
+}
+
+// persistBatch retries the batch with backoff, then counts its records as dropped and
+// keeps the error until a Flush or Close reports it.
+func (repo *ActivityRecordAsyncCmdRepo) persistBatch(pendingDtos []tkDto.CreateActivityRecord) {
+	if len(pendingDtos) == 0 {
+		return
+	}
+
+	retryBackoff := repo.persistRetryBackoff
+	var err error
+	for attemptIndex := range repo.persistMaxAttempts {
+		if attemptIndex > 0 {
+			time.Sleep(retryBackoff)
+			retryBackoff *= 2
+		}
+
+		err = repo.syncCmdRepo.CreateMany(pendingDtos)
+		if err == nil {
+			return
+		}
+	}
+
+	repo.droppedCounter.Add(uint64(len(pendingDtos)))
+	repo.unreportedPersistErr = errors.Join(repo.unreportedPersistErr, err)
+	slog.Error(
+		"ActivityRecordAsyncPersistBatchError",
+		slog.String("err", err.Error()),
+		slog.Int("batchSize", len(pendingDtos)),
+		slog.Uint64("attempts", uint64(repo.persistMaxAttempts)),
+	)
+}
+
+// reportPersistErr returns the errors of the batches lost since the last report.
+func (repo *ActivityRecordAsyncCmdRepo) reportPersistErr() error {
+	persistErr := repo.unreportedPersistErr
+	repo.unreportedPersistErr = nil
+	return persistErr
+}
+
+func (repo *ActivityRecordAsyncCmdRepo) worker() {
+	defer close(repo.workerDoneChannel)
+
+	flushTicker := time.NewTicker(repo.flushInterval)
+	defer flushTicker.Stop()
+
+	pendingDtos := make([]tkDto.CreateActivityRecord, 0, repo.batchSize)
+	for {
+		select {
+		case createDto, isChannelOpen := <-repo.queueChannel:
+			if !isChannelOpen {
+				repo.persistBatch(pendingDtos)
+				repo.lastFlushErr = repo.reportPersistErr()
+				return
+			}
+
+			pendingDtos = append(pendingDtos, createDto)
+			if uint(len(pendingDtos)) < repo.batchSize {
+				continue
+			}
+			repo.persistBatch(pendingDtos)
+			pendingDtos = pendingDtos[:0]
+
+		case <-flushTicker.C:
+			repo.persistBatch(pendingDtos)
+			pendingDtos = pendingDtos[:0]
+
+		case flushResponseChannel := <-repo.flushRequestChannel:
+			queueLength := len(repo.queueChannel)
+			for range queueLength {
+				pendingDtos = append(pendingDtos, <-repo.queueChannel)
+			}
+			repo.persistBatch(pendingDtos)
+			pendingDtos = pendingDtos[:0]
+			flushResponseChannel <- repo.reportPersistErr()
+		}
+	}
+}
+
+func (repo *ActivityRecordAsyncCmdRepo) enqueue(createDto tkDto.CreateActivityRecord) error {
+	switch repo.overflowPolicy {
+	case ActivityRecordAsyncOverflowPolicyDropNewest:
+		select {
+		case repo.queueChannel <- createDto:
+			return nil
+		default:
+			repo.droppedCounter.Add(1)
+			return errors.New(ErrActivityRecordAsyncQueueFull)
+		}
+
+	case ActivityRecordAsyncOverflowPolicyDropOldest:
+		for {
+			select {
+			case repo.queueChannel <- createDto:
+				return nil
+			default:
+			}
+
+			select {
+			case <-repo.queueChannel:
+				repo.droppedCounter.Add(1)
+			default:
+			}
+		}
+
+	default:
+		repo.queueChannel <- createDto
+		return nil
+	}
+}
+
+// Create queues the record and returns immediately (unless the overflow policy is
+// "block" and the queue is full). Record details failing to serialize are rejected
+// here, so they never fail the batch of other records. Persistence errors are logged
+// by the worker and returned by the next Flush or Close.
+func (repo *ActivityRecordAsyncCmdRepo) Create(createDto tkDto.CreateActivityRecord) error {
+	if createDto.RecordDetails != nil {
+		_, err := repo.syncCmdRepo.recordDetailsMarshaler(createDto.RecordDetails)
+		if err != nil {
+			return err
+		}
+	}
+
+	repo.queueMutex.RLock()
+	defer repo.queueMutex.RUnlock()
+
+	if repo.isClosed {
+		return errors.New(ErrActivityRecordAsyncCmdRepoClosed)
+	}
+
+	return repo.enqueue(createDto)
+}
+
+// Delete flushes the queue before deleting so the filters also reach the records that
+// were created but not yet persisted.
+func (repo *ActivityRecordAsyncCmdRepo) Delete(
+	deleteDto tkDto.DeleteActivityRecord,
+) (responseDto tkDto.DeleteActivityRecordResponse, err error) {
+	err = repo.Flush()
+	if err != nil && err.Error() != ErrActivityRecordAsyncCmdRepoClosed {
+		return responseDto, err
+	}
+
+	return repo.syncCmdRepo.Delete(deleteDto)
+}
+
+// Flush blocks until every record queued so far is persisted, returning the errors of
+// the batches lost since the previous Flush, if any.
+func (repo *ActivityRecordAsyncCmdRepo) Flush() error {
+	repo.queueMutex.RLock()
+	defer repo.queueMutex.RUnlock()
+
+	if repo.isClosed {
+		return errors.New(ErrActivityRecordAsyncCmdRepoClosed)
+	}
+
+	flushResponseChannel := make(chan error, 1)
+	repo.flushRequestChannel <- flushResponseChannel
+	return <-flushResponseChannel
+}
+
+// Close stops accepting new records, drains the queue and waits for the worker to
+// persist the last batch. It is safe to call Close more than once.
+func (repo *ActivityRecordAsyncCmdRepo) Close() error {
+	repo.queueMutex.Lock()
+	if !repo.isClosed {
+		repo.isClosed = true
+		close(repo.queueChannel)
+	}
+	repo.queueMutex.Unlock()
+
+	<-repo.workerDoneChannel
+	return repo.lastFlushErr
+}
+
+// ReadDroppedCount returns how many records were discarded by the overflow policy or
+// lost because their batch failed to persist.
+func (repo *ActivityRecordAsyncCmdRepo) ReadDroppedCount() uint64 {
+	return repo.droppedCounter.Load()
+}
+
+// Purge flushes the queue before purging so the retention rules also reach the
+// records that were created but not yet persisted.
+func (repo *ActivityRecordAsyncCmdRepo) Purge(
+	purgeDto tkDto.PurgeActivityRecordsRequest,
+) (responseDto tkDto.PurgeActivityRecordsResponse, err error) {
+	err = repo.Flush()
+	if err != nil && err.Error() != ErrActivityRecordAsyncCmdRepoClosed {
+		return responseDto, err
+	}
+
+	return repo.syncCmdRepo.Purge(purgeDto)
+}
@@ -0,0 +1,297 @@
+package tkInfraActivityRecord
+
+import (
+	"strconv"
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+)
+
+func TestActivityRecordAsyncCmdRepo(t *testing.T) {
+	recordCodeVo, err := tkValueObject.NewActivityRecordCode("ASYNC_TEST")
+	if err != nil {
+		t.Fatalf("CreateRecordCodeVoFailed: %v", err)
+	}
+	createDto := tkDto.CreateActivityRecord{
+		RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+		RecordCode:        recordCodeVo,
+		AffectedResources: []tkValueObject.SystemResourceIdentifier{},
+	}
+
+	readItemsTotal := func(t *testing.T, queryRepo *ActivityRecordQueryRepo) uint64 {
+		t.Helper()
+		responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+			Pagination: tkDto.PaginationUnpaginated,
+		})
+		if err != nil {
+			t.Fatalf("ReadFailed: %v", err)
+		}
+		return *responseDto.Pagination.ItemsTotal
+	}
+
+	t.Run("FlushPersistsQueuedRecords", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{FlushIntervalMs: 60000},
+		)
+		defer asyncCmdRepo.Close()
+
+		for range 25 {
+			err := asyncCmdRepo.Create(createDto)
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+
+		err := asyncCmdRepo.Flush()
+		if err != nil {
+			t.Fatalf("FlushFailed: %v", err)
+		}
+
+		itemsTotal := readItemsTotal(t, queryRepo)
+		if itemsTotal != 25 {
+			t.Errorf("ItemsTotalMismatch: expected 25, got %d", itemsTotal)
+		}
+	})
+
+	t.Run("CloseDrainsQueue", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{
+				BatchSize: 7, FlushIntervalMs: 60000,
+			},
+		)
+
+		testSri, err := tkValueObject.NewSystemResourceIdentifier("sri://0:test/async")
+		if err != nil {
+			t.Fatalf("CreateResourceVoFailed: %v", err)
+		}
+		createWithResourceDto := createDto
+		createWithResourceDto.AffectedResources = []tkValueObject.SystemResourceIdentifier{testSri}
+
+		for range 30 {
+			err := asyncCmdRepo.Create(createWithResourceDto)
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+
+		err = asyncCmdRepo.Close()
+		if err != nil {
+			t.Fatalf("CloseFailed: %v", err)
+		}
+
+		itemsTotal := readItemsTotal(t, queryRepo)
+		if itemsTotal != 30 {
+			t.Errorf("ItemsTotalMismatch: expected 30, got %d", itemsTotal)
+		}
+
+		recordEntity, err := queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{})
+		if err != nil {
+			t.Fatalf("ReadFirstFailed: %v", err)
+		}
+		if len(recordEntity.AffectedResources) != 1 {
+			t.Errorf(
+				"AffectedResourcesNotPersisted: expected 1, got %d",
+				len(recordEntity.AffectedResources),
+			)
+		}
+	})
+
+	t.Run("CreateAfterCloseFails", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{},
+		)
+
+		err := asyncCmdRepo.Close()
+		if err != nil {
+			t.Fatalf("CloseFailed: %v", err)
+		}
+		err = asyncCmdRepo.Close()
+		if err != nil {
+			t.Errorf("SecondCloseFailed: %v", err)
+		}
+
+		err = asyncCmdRepo.Create(createDto)
+		if err == nil || err.Error() != ErrActivityRecordAsyncCmdRepoClosed {
+			t.Errorf("MissingExpectedError: %s", ErrActivityRecordAsyncCmdRepoClosed)
+		}
+	})
+
+	t.Run("DeleteReachesQueuedRecords", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{FlushIntervalMs: 60000},
+		)
+		defer asyncCmdRepo.Close()
+
+		for range 3 {
+			err := asyncCmdRepo.Create(createDto)
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+
+		deleteResponseDto, err := asyncCmdRepo.Delete(tkDto.NewDeleteActivityRecord(
+			nil, nil, &recordCodeVo, nil, nil, nil, nil, nil,
+		))
+		if err != nil {
+			t.Fatalf("DeleteFailed: %v", err)
+		}
+		if deleteResponseDto.DeletedRecordsCount != 3 {
+			t.Errorf(
+				"DeletedRecordsCountMismatch: expected 3, got %d",
+				deleteResponseDto.DeletedRecordsCount,
+			)
+		}
+
+		itemsTotal := readItemsTotal(t, queryRepo)
+		if itemsTotal != 0 {
+			t.Errorf("ItemsTotalMismatch: expected 0, got %d", itemsTotal)
+		}
+	})
+
+	t.Run("OverflowPolicies", func(t *testing.T) {
+		testCaseStructs := []struct {
+			overflowPolicy       ActivityRecordAsyncOverflowPolicy
+			expectedQueuedCode   string
+			expectedDroppedCount uint64
+			expectError          bool
+		}{
+			{ActivityRecordAsyncOverflowPolicyDropNewest, "CODE0", 2, true},
+			{ActivityRecordAsyncOverflowPolicyDropOldest, "CODE2", 2, false},
+		}
+
+		for _, testCase := range testCaseStructs {
+			// The worker is intentionally not started so the queue stays full.
+			asyncCmdRepo := &ActivityRecordAsyncCmdRepo{
+				overflowPolicy: testCase.overflowPolicy,
+				queueChannel:   make(chan tkDto.CreateActivityRecord, 1),
+			}
+
+			var lastErr error
+			for codeIndex := range 3 {
+				overflowDto := createDto
+				overflowDto.RecordCode, _ = tkValueObject.NewActivityRecordCode(
+					"CODE" + strconv.Itoa(codeIndex),
+				)
+				lastErr = asyncCmdRepo.Create(overflowDto)
+			}
+
+			if testCase.expectError && lastErr == nil {
+				t.Errorf("MissingExpectedError: [%s]", testCase.overflowPolicy)
+			}
+			if !testCase.expectError && lastErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", lastErr.Error(), testCase.overflowPolicy)
+			}
+
+			droppedCount := asyncCmdRepo.ReadDroppedCount()
+			if droppedCount != testCase.expectedDroppedCount {
+				t.Errorf(
+					"DroppedCountMismatch: expected %d, got %d [%s]",
+					testCase.expectedDroppedCount, droppedCount, testCase.overflowPolicy,
+				)
+			}
+
+			queuedDto := <-asyncCmdRepo.queueChannel
+			if queuedDto.RecordCode.String() != testCase.expectedQueuedCode {
+				t.Errorf(
+					"QueuedRecordMismatch: expected %s, got %s [%s]",
+					testCase.expectedQueuedCode, queuedDto.RecordCode.String(),
+					testCase.overflowPolicy,
+				)
+			}
+		}
+	})
+
+	t.Run("UnserializableDetailsRejectedAlone", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{BatchSize: 10, FlushIntervalMs: 60000},
+		)
+		defer asyncCmdRepo.Close()
+
+		unserializableDto := createDto
+		unserializableDto.RecordDetails = map[string]any{"callback": func() {}}
+		for recordIndex := range 7 {
+			if recordIndex == 3 {
+				err := asyncCmdRepo.Create(unserializableDto)
+				if err == nil {
+					t.Error("MissingExpectedError: UnsupportedTypeError")
+				}
+				continue
+			}
+
+			err := asyncCmdRepo.Create(createDto)
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+
+		err := asyncCmdRepo.Flush()
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		itemsTotal := readItemsTotal(t, queryRepo)
+		if itemsTotal != 6 {
+			t.Errorf("ItemsTotalMismatch: expected 6, got %d", itemsTotal)
+		}
+		if asyncCmdRepo.ReadDroppedCount() != 0 {
+			t.Errorf("DroppedCountMismatch: expected 0, got %d", asyncCmdRepo.ReadDroppedCount())
+		}
+	})
+
+	t.Run("FailedBatchesAreCountedAndReported", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{
+				BatchSize: 2, FlushIntervalMs: 60000,
+				PersistMaxAttempts: 2, PersistRetryBackoffMs: 1,
+			},
+		)
+
+		err := dbSvc.Handler.Migrator().DropTable(&tkInfraDbModel.ActivityRecord{})
+		if err != nil {
+			t.Fatalf("DropTableFailed: %v", err)
+		}
+
+		for range 5 {
+			err := asyncCmdRepo.Create(createDto)
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+
+		err = asyncCmdRepo.Flush()
+		if err == nil {
+			t.Error("MissingExpectedError: PersistBatchError")
+		}
+		if asyncCmdRepo.ReadDroppedCount() != 5 {
+			t.Errorf("DroppedCountMismatch: expected 5, got %d", asyncCmdRepo.ReadDroppedCount())
+		}
+
+		err = dbSvc.Handler.AutoMigrate(&tkInfraDbModel.ActivityRecord{})
+		if err != nil {
+			t.Fatalf("AutoMigrateFailed: %v", err)
+		}
+
+		err = asyncCmdRepo.Create(createDto)
+		if err != nil {
+			t.Fatalf("CreateFailed: %v", err)
+		}
+		err = asyncCmdRepo.Close()
+		if err != nil {
+			t.Errorf("UnexpectedError: '%s'", err.Error())
+		}
+		if asyncCmdRepo.ReadDroppedCount() != 5 {
+			t.Errorf("DroppedCountMismatch: expected 5, got %d", asyncCmdRepo.ReadDroppedCount())
+		}
+	})
+}
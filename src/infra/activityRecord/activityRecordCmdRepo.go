@@ -2,43 +2,93 @@ package tkInfraActivityRecord
 
 import (
 	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"time"
 
 	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
 	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
 	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+)
+
+const (
+	activityRecordPurgeBatchSizeDefault  uint16 = 500
+	activityRecordDeleteBatchSizeDefault uint16 = 500
 )
 
 type ActivityRecordCmdRepo struct {
-	trailDbSvc *tkInfraDb.TrailDatabaseService
-	queryRepo  *ActivityRecordQueryRepo
+	trailDbSvc  *tkInfraDb.TrailDatabaseService
+	queryRepo   *ActivityRecordQueryRepo
+	hashChainer *activityRecordHashChainer
+	streamHub   *ActivityRecordStreamHub
+
+	fieldsEncryptor *activityRecordFieldsEncryptor
+
+	detailsRedactor *ActivityRecordDetailsRedactor
 }
 
 func NewActivityRecordCmdRepo(
 	trailDbSvc *tkInfraDb.TrailDatabaseService,
 ) *ActivityRecordCmdRepo {
+	cmdRepo, _ := NewActivityRecordCmdRepoWithSettings(
+		trailDbSvc, ActivityRecordRepoSettings{},
+	)
+	return cmdRepo
+}
+
+func NewActivityRecordCmdRepoWithSettings(
+	trailDbSvc *tkInfraDb.TrailDatabaseService,
+	settings ActivityRecordRepoSettings,
+) (*ActivityRecordCmdRepo, error) {
+	queryRepo, err := NewActivityRecordQueryRepoWithSettings(trailDbSvc, settings)
+	if err != nil {
+		return nil, err
+	}
+
 	return &ActivityRecordCmdRepo{
-		trailDbSvc: trailDbSvc,
-		queryRepo:  NewActivityRecordQueryRepo(trailDbSvc),
+		trailDbSvc:  trailDbSvc,
+		queryRepo:   queryRepo,
+		hashChainer: queryRepo.hashChainer,
+		streamHub:   settings.StreamHub,
+
+		fieldsEncryptor: queryRepo.fieldsEncryptor,
+		detailsRedactor: settings.readDetailsRedactor(),
+	}, nil
+}
+
+// recordDetailsMarshaler redacts and serializes the record details, if any.
+func (repo *ActivityRecordCmdRepo) recordDetailsMarshaler(
+	recordDetails any,
+) (*string, error) {
+	if recordDetails == nil {
+		return nil, nil
 	}
+
+	recordDetailsBytes, err := json.Marshal(repo.detailsRedactor.Redact(recordDetails))
+	if err != nil {
+		return nil, err
+	}
+	recordDetailsStr := string(recordDetailsBytes)
+	return &recordDetailsStr, nil
 }
 
-func (repo *ActivityRecordCmdRepo) Create(createDto tkDto.CreateActivityRecord) error {
+func (repo *ActivityRecordCmdRepo) createDtoToModel(
+	createDto tkDto.CreateActivityRecord,
+) (activityRecordModel tkInfraDbModel.ActivityRecord, err error) {
 	affectedResources := []tkInfraDbModel.ActivityRecordAffectedResource{}
 	for _, affectedResourceSri := range createDto.AffectedResources {
-		affectedResourceModel := tkInfraDbModel.ActivityRecordAffectedResource{
-			SystemResourceIdentifier: affectedResourceSri.String(),
-		}
+		affectedResourceModel := tkInfraDbModel.NewActivityRecordAffectedResource(
+			affectedResourceSri.String(),
+		)
 		affectedResources = append(affectedResources, affectedResourceModel)
 	}
 
-	var recordDetails *string
-	if createDto.RecordDetails != nil {
-		recordDetailsBytes, err := json.Marshal(createDto.RecordDetails)
-		if err != nil {
-			return err
-		}
-		recordDetailsStr := string(recordDetailsBytes)
-		recordDetails = &recordDetailsStr
+	recordDetails, err := repo.recordDetailsMarshaler(createDto.RecordDetails)
+	if err != nil {
+		return activityRecordModel, err
 	}
 
 	var operatorSriPtr *string
@@ -53,17 +103,144 @@ func (repo *ActivityRecordCmdRepo) Create(createDto tkDto.CreateActivityRecord)
 		operatorIpAddressPtr = &operatorIpAddress
 	}
 
-	activityRecordModel := tkInfraDbModel.NewActivityRecord(
+	var correlationIdPtr *string
+	if createDto.CorrelationId != nil {
+		correlationId := createDto.CorrelationId.String()
+		correlationIdPtr = &correlationId
+	}
+
+	activityRecordModel = tkInfraDbModel.NewActivityRecord(
 		0, createDto.RecordLevel.String(), createDto.RecordCode.String(),
 		affectedResources, recordDetails, operatorSriPtr, operatorIpAddressPtr,
 	)
+	activityRecordModel.CorrelationId = correlationIdPtr
+	if repo.fieldsEncryptor != nil {
+		err = repo.fieldsEncryptor.encryptModel(&activityRecordModel)
+		if err != nil {
+			return activityRecordModel, err
+		}
+	}
+
+	return activityRecordModel, nil
+}
+
+func (repo *ActivityRecordCmdRepo) createModels(
+	activityRecordModels []tkInfraDbModel.ActivityRecord,
+) error {
+	if repo.hashChainer == nil {
+		return repo.trailDbSvc.Handler.Transaction(func(dbTx *gorm.DB) error {
+			return dbTx.Create(&activityRecordModels).Error
+		})
+	}
+
+	activityRecordHashChainMutex.Lock()
+	defer activityRecordHashChainMutex.Unlock()
+
+	return repo.trailDbSvc.Handler.Transaction(func(dbTx *gorm.DB) error {
+		chainHeadModel, err := repo.hashChainer.lockChainHead(dbTx)
+		if err != nil {
+			return err
+		}
+
+		err = repo.hashChainer.chainModels(dbTx, chainHeadModel, activityRecordModels)
+		if err != nil {
+			return err
+		}
+
+		err = dbTx.Create(&activityRecordModels).Error
+		if err != nil {
+			return err
+		}
+
+		return repo.hashChainer.updateChainHead(dbTx)
+	})
+}
+
+// publishModels notifies the stream hub, if any, once the records are committed.
+func (repo *ActivityRecordCmdRepo) publishModels(
+	activityRecordModels []tkInfraDbModel.ActivityRecord,
+) {
+	if repo.streamHub == nil {
+		return
+	}
+
+	activityRecordEntities := make([]tkEntity.ActivityRecord, 0, len(activityRecordModels))
+	for _, activityRecordModel := range activityRecordModels {
+		var err error
+		if repo.fieldsEncryptor != nil {
+			activityRecordModel, err = repo.fieldsEncryptor.decryptModel(activityRecordModel)
+		}
+		var activityRecordEntity tkEntity.ActivityRecord
+		if err == nil {
+			activityRecordEntity, err = activityRecordModel.ToEntity()
+		}
+		if err != nil {
+			slog.Debug(
+				"ActivityRecordModelToEntityError",
+				slog.Uint64("id", activityRecordModel.ID),
+				slog.String("err", err.Error()),
+			)
+			continue
+		}
+		activityRecordEntities = append(activityRecordEntities, activityRecordEntity)
+	}
+
+	repo.streamHub.Publish(activityRecordEntities)
+}
+
+func (repo *ActivityRecordCmdRepo) Create(createDto tkDto.CreateActivityRecord) error {
+	activityRecordModel, err := repo.createDtoToModel(createDto)
+	if err != nil {
+		return err
+	}
+
+	activityRecordModels := []tkInfraDbModel.ActivityRecord{activityRecordModel}
+	if repo.hashChainer == nil {
+		err = repo.trailDbSvc.Handler.Create(&activityRecordModels[0]).Error
+	} else {
+		err = repo.createModels(activityRecordModels)
+	}
+	if err != nil {
+		return err
+	}
+
+	repo.publishModels(activityRecordModels)
+	return nil
+}
 
-	return repo.trailDbSvc.Handler.Create(&activityRecordModel).Error
+// CreateMany persists several activity records in a single transaction. Either all
+// records are persisted or none of them are.
+func (repo *ActivityRecordCmdRepo) CreateMany(createDtos []tkDto.CreateActivityRecord) error {
+	if len(createDtos) == 0 {
+		return nil
+	}
+
+	activityRecordModels := make([]tkInfraDbModel.ActivityRecord, 0, len(createDtos))
+	for _, createDto := range createDtos {
+		activityRecordModel, err := repo.createDtoToModel(createDto)
+		if err != nil {
+			return err
+		}
+		activityRecordModels = append(activityRecordModels, activityRecordModel)
+	}
+
+	err := repo.createModels(activityRecordModels)
+	if err != nil {
+		return err
+	}
+
+	repo.publishModels(activityRecordModels)
+	return nil
 }
 
-func (repo *ActivityRecordCmdRepo) Delete(deleteDto tkDto.DeleteActivityRecord) error {
-	readResponseDto, err := repo.queryRepo.Read(tkDto.ReadActivityRecordsRequest{
-		Pagination:        tkDto.PaginationUnpaginated,
+// Delete deletes every record matching the filters, along with their affected
+// resources, in bounded batches (each in its own transaction) so large deletions
+// neither hold long locks nor stop at a page size. The deleted records count includes
+// the batches deleted before a failure.
+func (repo *ActivityRecordCmdRepo) Delete(
+	deleteDto tkDto.DeleteActivityRecord,
+) (responseDto tkDto.DeleteActivityRecordResponse, err error) {
+	readRequestDto := tkDto.ReadActivityRecordsRequest{
 		RecordId:          deleteDto.RecordId,
 		RecordLevel:       deleteDto.RecordLevel,
 		RecordCode:        deleteDto.RecordCode,
@@ -72,19 +249,195 @@ func (repo *ActivityRecordCmdRepo) Delete(deleteDto tkDto.DeleteActivityRecord)
 		OperatorIpAddress: deleteDto.OperatorIpAddress,
 		CreatedBeforeAt:   deleteDto.CreatedBeforeAt,
 		CreatedAfterAt:    deleteDto.CreatedAfterAt,
-	})
+		CorrelationId:     deleteDto.CorrelationId,
+		AccountId:         deleteDto.AccountId,
+	}
+	// Built once upfront since some filters fail, e.g. the encrypted columns ones.
+	_, err = repo.queryRepo.filteredQueryBuilder(readRequestDto)
 	if err != nil {
-		return err
+		return responseDto, err
+	}
+	filteredIdsQueryFactory := func() *gorm.DB {
+		filteredQuery, _ := repo.queryRepo.filteredQueryBuilder(readRequestDto)
+		return filteredQuery.Order("activity_records.id ASC")
 	}
 
-	if len(readResponseDto.ActivityRecords) == 0 {
-		return nil
+	responseDto.DeletedRecordsCount, err = repo.deleteInBatches(
+		filteredIdsQueryFactory, int(activityRecordDeleteBatchSizeDefault),
+	)
+	return responseDto, err
+}
+
+// deleteRecordsByIds deletes the records and their affected resources in a single
+// transaction. When the hash chain is enabled, it also checkpoints the broken links and
+// moves the chain head.
+func (repo *ActivityRecordCmdRepo) deleteRecordsByIds(recordIds []uint64) error {
+	if repo.hashChainer != nil {
+		activityRecordHashChainMutex.Lock()
+		defer activityRecordHashChainMutex.Unlock()
+	}
+
+	return repo.trailDbSvc.Handler.Transaction(func(dbTx *gorm.DB) error {
+		deletedHashes := []string{}
+		if repo.hashChainer != nil {
+			_, err := repo.hashChainer.lockChainHead(dbTx)
+			if err != nil {
+				return err
+			}
+
+			err = dbTx.Model(&tkInfraDbModel.ActivityRecord{}).
+				Where("id IN ? AND content_hash IS NOT NULL", recordIds).
+				Pluck("content_hash", &deletedHashes).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		err := dbTx.Where("activity_record_id IN ?", recordIds).
+			Delete(&tkInfraDbModel.ActivityRecordAffectedResource{}).Error
+		if err != nil {
+			return err
+		}
+
+		err = dbTx.Delete(&tkInfraDbModel.ActivityRecord{}, recordIds).Error
+		if err != nil {
+			return err
+		}
+
+		if repo.hashChainer == nil {
+			return nil
+		}
+		err = repo.hashChainer.checkpointSuccessors(dbTx, recordIds, deletedHashes)
+		if err != nil {
+			return err
+		}
+
+		return repo.hashChainer.updateChainHead(dbTx)
+	})
+}
+
+// retentionRuleConditions returns the SQL condition matching the records governed by
+// the rule at ruleIndex, i.e. the records it matches that no previous rule matched.
+func (repo *ActivityRecordCmdRepo) retentionRuleConditions(
+	retentionRules []tkDto.ActivityRecordRetentionRule,
+	ruleIndex int,
+) (conditionsStr string, conditionsArgs []any) {
+	ruleMatchConditions := func(
+		retentionRule tkDto.ActivityRecordRetentionRule,
+	) (matchStr string, matchArgs []any) {
+		matchParts := []string{}
+		if retentionRule.RecordLevel != nil {
+			matchParts = append(matchParts, "record_level = ?")
+			matchArgs = append(matchArgs, retentionRule.RecordLevel.String())
+		}
+		if retentionRule.RecordCode != nil {
+			matchParts = append(matchParts, "record_code = ?")
+			matchArgs = append(matchArgs, retentionRule.RecordCode.String())
+		}
+		if len(matchParts) == 0 {
+			return "1 = 1", matchArgs
+		}
+		return "(" + strings.Join(matchParts, " AND ") + ")", matchArgs
 	}
 
-	recordIds := []uint64{}
-	for _, record := range readResponseDto.ActivityRecords {
-		recordIds = append(recordIds, record.RecordId.Uint64())
+	conditionsStr, conditionsArgs = ruleMatchConditions(retentionRules[ruleIndex])
+	for _, previousRule := range retentionRules[:ruleIndex] {
+		previousMatchStr, previousMatchArgs := ruleMatchConditions(previousRule)
+		conditionsStr += " AND NOT " + previousMatchStr
+		conditionsArgs = append(conditionsArgs, previousMatchArgs...)
+	}
+
+	return conditionsStr, conditionsArgs
+}
+
+// deleteInBatches deletes the records selected by idsQueryFactory in bounded batches
+// until no more records are selected.
+func (repo *ActivityRecordCmdRepo) deleteInBatches(
+	idsQueryFactory func() *gorm.DB,
+	batchSize int,
+) (deletedRecordsCount uint64, err error) {
+	for {
+		recordIds := []uint64{}
+		err = idsQueryFactory().Limit(batchSize).Pluck("id", &recordIds).Error
+		if err != nil {
+			return deletedRecordsCount, err
+		}
+		if len(recordIds) == 0 {
+			return deletedRecordsCount, nil
+		}
+
+		err = repo.deleteRecordsByIds(recordIds)
+		if err != nil {
+			return deletedRecordsCount, err
+		}
+		deletedRecordsCount += uint64(len(recordIds))
+
+		if len(recordIds) < batchSize {
+			return deletedRecordsCount, nil
+		}
+	}
+}
+
+func (repo *ActivityRecordCmdRepo) Purge(
+	purgeDto tkDto.PurgeActivityRecordsRequest,
+) (responseDto tkDto.PurgeActivityRecordsResponse, err error) {
+	batchSize := int(activityRecordPurgeBatchSizeDefault)
+	if purgeDto.BatchSize != 0 {
+		batchSize = int(purgeDto.BatchSize)
+	}
+
+	responseDto.PurgedRecordsCountPerRule = make([]uint64, len(purgeDto.RetentionRules))
+	for ruleIndex, retentionRule := range purgeDto.RetentionRules {
+		conditionsStr, conditionsArgs := repo.retentionRuleConditions(
+			purgeDto.RetentionRules, ruleIndex,
+		)
+		governedRecordsQuery := func() *gorm.DB {
+			return repo.trailDbSvc.Handler.
+				Model(&tkInfraDbModel.ActivityRecord{}).
+				Where(conditionsStr, conditionsArgs...)
+		}
+
+		if retentionRule.MaxAge != nil {
+			expiredBeforeAt := time.Now().Add(-*retentionRule.MaxAge).UTC()
+			purgedRecordsCount, err := repo.deleteInBatches(func() *gorm.DB {
+				return governedRecordsQuery().
+					Where("created_at < ?", expiredBeforeAt).
+					Order("id ASC")
+			}, batchSize)
+			responseDto.PurgedRecordsCountPerRule[ruleIndex] += purgedRecordsCount
+			responseDto.PurgedRecordsCount += purgedRecordsCount
+			if err != nil {
+				return responseDto, err
+			}
+		}
+
+		if retentionRule.MaxRecordsCount != nil {
+			purgedRecordsCount, err := repo.deleteInBatches(func() *gorm.DB {
+				return governedRecordsQuery().
+					Order("id DESC").
+					Offset(int(*retentionRule.MaxRecordsCount))
+			}, batchSize)
+			responseDto.PurgedRecordsCountPerRule[ruleIndex] += purgedRecordsCount
+			responseDto.PurgedRecordsCount += purgedRecordsCount
+			if err != nil {
+				return responseDto, err
+			}
+		}
+	}
+
+	if purgeDto.ShouldVacuum && responseDto.PurgedRecordsCount > 0 {
+		compactStatement := tkInfraDb.NewSqlDialect(repo.trailDbSvc.Handler).CompactStatement(
+			[]string{
+				tkInfraDbModel.ActivityRecord{}.TableName(),
+				tkInfraDbModel.ActivityRecordAffectedResource{}.TableName(),
+				tkInfraDbModel.ActivityRecordCheckpoint{}.TableName(),
+			},
+		)
+		err = repo.trailDbSvc.Handler.Exec(compactStatement).Error
+		if err != nil {
+			return responseDto, errors.New("VacuumError: " + err.Error())
+		}
 	}
 
-	return repo.trailDbSvc.Handler.Delete(&tkInfraDbModel.ActivityRecord{}, recordIds).Error
+	return responseDto, nil
 }
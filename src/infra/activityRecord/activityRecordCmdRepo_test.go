@@ -2,8 +2,6 @@ package tkInfraActivityRecord
 
 import (
 	"errors"
-	"os"
-	"path/filepath"
 	"strconv"
 	"strings"
 	"testing"
@@ -13,6 +11,7 @@ import (
 	tkEntity "github.com/goinfinite/tk/src/domain/entity"
 	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
 )
 
 func TestActivityRecordCmdRepoCreate(t *testing.T) {
@@ -133,7 +132,7 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			nil, nil, nil, nil,
 		)
 
-		err = cmdRepo.Delete(deleteDto)
+		_, err = cmdRepo.Delete(deleteDto)
 		if err != nil {
 			t.Errorf("DeleteWithRecordIdFailed: %v", err)
 		}
@@ -192,7 +191,7 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			nil, nil, nil, nil,
 		)
 
-		err = cmdRepo.Delete(deleteDto)
+		_, err = cmdRepo.Delete(deleteDto)
 		if err != nil {
 			t.Errorf("DeleteWithRecordLevelFailed: %v", err)
 		}
@@ -261,7 +260,7 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			nil, nil, nil, nil,
 		)
 
-		err = cmdRepo.Delete(deleteDto)
+		_, err = cmdRepo.Delete(deleteDto)
 		if err != nil {
 			t.Errorf("DeleteWithRecordCodeFailed: %v", err)
 		}
@@ -315,7 +314,7 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			nil, nil, nil, &futureTime,
 		)
 
-		err = cmdRepo.Delete(deleteDto)
+		_, err = cmdRepo.Delete(deleteDto)
 		if err != nil {
 			t.Errorf("DeleteWithTimeFiltersFailed: %v", err)
 		}
@@ -364,7 +363,7 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			nil, nil, nil, nil,
 		)
 
-		err = cmdRepo.Delete(deleteDto)
+		_, err = cmdRepo.Delete(deleteDto)
 		if err != nil {
 			t.Errorf("DeleteWithMultipleMatchingAffectedResourcesFailed: %v", err)
 		}
@@ -381,30 +380,70 @@ func TestActivityRecordCmdRepoDelete(t *testing.T) {
 			t.Errorf("ExpectedZeroRecords: got %d", len(responseDto.ActivityRecords))
 		}
 	})
-}
 
-func SetupTestTrailDatabaseService(t *testing.T) *tkInfraDb.TrailDatabaseService {
-	t.Helper()
-	tempDir := t.TempDir()
-	rawDatabaseFilePath := filepath.Join(tempDir, strings.ReplaceAll(t.Name(), "/", "_")+".db")
-	rawDatabaseFilePath, err := filepath.Abs(rawDatabaseFilePath)
-	if err != nil {
-		t.Fatalf("ResolveRawDatabaseFilePathFailed: %v", err)
-	}
+	t.Run("DeleteBeyondBatchAndPageSizes", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		cmdRepo := NewActivityRecordCmdRepo(dbSvc)
 
-	databaseFilePath, err := tkValueObject.NewUnixAbsoluteFilePath(rawDatabaseFilePath, false)
-	if err != nil {
-		t.Fatalf("ResolveDatabaseFilePathFailed: %v", err)
-	}
+		testSri := tkValueObject.NewSystemResourceIdentifierMustCreate("sri://0:test/bulk")
+		createDtos := []tkDto.CreateActivityRecord{}
+		for recordIndex := range 1250 {
+			recordCode := tkValueObject.ActivityRecordCode("BulkCreated")
+			if recordIndex%5 == 0 {
+				recordCode = "BulkKept"
+			}
+			createDtos = append(createDtos, tkDto.CreateActivityRecord{
+				RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+				RecordCode:        recordCode,
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{testSri},
+			})
+		}
+		err := cmdRepo.CreateMany(createDtos)
+		if err != nil {
+			t.Fatalf("CreateManyFailed: %v", err)
+		}
 
-	originalValue := os.Getenv(tkInfraDb.TrailDatabaseFilePathEnvVarName)
-	t.Cleanup(func() { os.Setenv(tkInfraDb.TrailDatabaseFilePathEnvVarName, originalValue) })
-	os.Setenv(tkInfraDb.TrailDatabaseFilePathEnvVarName, databaseFilePath.String())
+		recordCodeVo := tkValueObject.ActivityRecordCode("BulkCreated")
+		responseDto, err := cmdRepo.Delete(tkDto.NewDeleteActivityRecord(
+			nil, nil, &recordCodeVo, nil, nil, nil, nil, nil,
+		))
+		if err != nil {
+			t.Fatalf("DeleteFailed: %v", err)
+		}
+		if responseDto.DeletedRecordsCount != 1000 {
+			t.Errorf(
+				"DeletedRecordsCountMismatch: expected 1000, got %d",
+				responseDto.DeletedRecordsCount,
+			)
+		}
 
-	dbSvc, err := tkInfraDb.NewTrailDatabaseService([]any{})
+		var remainingRecordsCount, remainingResourcesCount int64
+		dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).Count(&remainingRecordsCount)
+		dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecordAffectedResource{}).
+			Count(&remainingResourcesCount)
+		if remainingRecordsCount != 250 || remainingResourcesCount != 250 {
+			t.Errorf(
+				"RemainingRowsMismatch: expected 250 records and affected resources, got %d and %d",
+				remainingRecordsCount, remainingResourcesCount,
+			)
+		}
+	})
+}
+
+func SetupTestTrailDatabaseService(t *testing.T) *tkInfraDb.TrailDatabaseService {
+	t.Helper()
+	dbSvc, err := tkInfraDb.NewTrailDatabaseServiceWithSettings(
+		tkInfraDb.TrailDatabaseServiceSettings{Dsn: tkInfraDb.TrailDatabaseInMemoryDsn},
+	)
 	if err != nil {
 		t.Fatalf("SetupTrailDatabaseServiceFailed: %v", err)
 	}
+	t.Cleanup(func() {
+		rawDbHandler, err := dbSvc.Handler.DB()
+		if err == nil {
+			_ = rawDbHandler.Close()
+		}
+	})
 
 	return dbSvc
 }
@@ -436,3 +475,126 @@ func createTestActivityRecord(
 
 	return responseDto.ActivityRecords[0], nil
 }
+
+func TestActivityRecordCmdRepoPurge(t *testing.T) {
+	createAgedRecord := func(
+		t *testing.T,
+		trailDbSvc *tkInfraDb.TrailDatabaseService,
+		recordLevel tkValueObject.ActivityRecordLevel,
+		recordCode string,
+		recordAge time.Duration,
+	) {
+		t.Helper()
+		activityRecordModel := tkInfraDbModel.NewActivityRecord(
+			0, recordLevel.String(), recordCode,
+			[]tkInfraDbModel.ActivityRecordAffectedResource{
+				{SystemResourceIdentifier: "sri://0:test/purge"},
+			}, nil, nil, nil,
+		)
+		activityRecordModel.CreatedAt = time.Now().Add(-recordAge).UTC()
+		err := trailDbSvc.Handler.Create(&activityRecordModel).Error
+		if err != nil {
+			t.Fatalf("CreateAgedRecordFailed: %v", err)
+		}
+	}
+
+	durationPtr := func(duration time.Duration) *time.Duration { return &duration }
+	uint64Ptr := func(value uint64) *uint64 { return &value }
+	securityLevel := tkValueObject.ActivityRecordLevelSecurity
+	debugLevel := tkValueObject.ActivityRecordLevelDebug
+	day := 24 * time.Hour
+
+	t.Run("FirstMatchingRuleGovernsRecord", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		cmdRepo := NewActivityRecordCmdRepo(dbSvc)
+
+		createAgedRecord(t, dbSvc, debugLevel, "DebugOld", 10*day)
+		createAgedRecord(t, dbSvc, debugLevel, "DebugNew", 1*day)
+		createAgedRecord(t, dbSvc, securityLevel, "SecurityOld", 100*day)
+		createAgedRecord(t, dbSvc, securityLevel, "SecurityAncient", 400*day)
+		createAgedRecord(t, dbSvc, tkValueObject.ActivityRecordLevelInfo, "InfoOld", 100*day)
+
+		responseDto, err := cmdRepo.Purge(tkDto.PurgeActivityRecordsRequest{
+			RetentionRules: []tkDto.ActivityRecordRetentionRule{
+				{RecordLevel: &debugLevel, MaxAge: durationPtr(7 * day)},
+				{RecordLevel: &securityLevel, MaxAge: durationPtr(365 * day)},
+				{MaxAge: durationPtr(90 * day)},
+			},
+			BatchSize:    1,
+			ShouldVacuum: true,
+		})
+		if err != nil {
+			t.Fatalf("PurgeFailed: %v", err)
+		}
+
+		if responseDto.PurgedRecordsCount != 3 {
+			t.Errorf("PurgedRecordsCountMismatch: expected 3, got %d", responseDto.PurgedRecordsCount)
+		}
+		expectedCountPerRule := []uint64{1, 1, 1}
+		for ruleIndex, expectedCount := range expectedCountPerRule {
+			if responseDto.PurgedRecordsCountPerRule[ruleIndex] != expectedCount {
+				t.Errorf(
+					"PurgedRecordsCountPerRuleMismatch: rule %d expected %d, got %d",
+					ruleIndex, expectedCount, responseDto.PurgedRecordsCountPerRule[ruleIndex],
+				)
+			}
+		}
+
+		remainingCodes := []string{}
+		err = dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).
+			Order("record_code ASC").Pluck("record_code", &remainingCodes).Error
+		if err != nil {
+			t.Fatalf("ReadRemainingCodesFailed: %v", err)
+		}
+		if strings.Join(remainingCodes, ",") != "DebugNew,SecurityOld" {
+			t.Errorf("RemainingCodesMismatch: got %v", remainingCodes)
+		}
+
+		var orphanResourcesCount int64
+		err = dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecordAffectedResource{}).
+			Where("activity_record_id NOT IN (?)", dbSvc.Handler.
+				Model(&tkInfraDbModel.ActivityRecord{}).Select("id"),
+			).Count(&orphanResourcesCount).Error
+		if err != nil {
+			t.Fatalf("CountOrphanResourcesFailed: %v", err)
+		}
+		if orphanResourcesCount != 0 {
+			t.Errorf("AffectedResourcesNotPurged: got %d orphans", orphanResourcesCount)
+		}
+	})
+
+	t.Run("MaxRecordsCountKeepsNewest", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		cmdRepo := NewActivityRecordCmdRepo(dbSvc)
+
+		for recordIndex := range 5 {
+			createAgedRecord(
+				t, dbSvc, debugLevel, "Record"+strconv.Itoa(recordIndex),
+				time.Duration(5-recordIndex)*time.Hour,
+			)
+		}
+
+		responseDto, err := cmdRepo.Purge(tkDto.PurgeActivityRecordsRequest{
+			RetentionRules: []tkDto.ActivityRecordRetentionRule{
+				{MaxRecordsCount: uint64Ptr(2)},
+			},
+			BatchSize: 2,
+		})
+		if err != nil {
+			t.Fatalf("PurgeFailed: %v", err)
+		}
+		if responseDto.PurgedRecordsCount != 3 {
+			t.Errorf("PurgedRecordsCountMismatch: expected 3, got %d", responseDto.PurgedRecordsCount)
+		}
+
+		remainingCodes := []string{}
+		err = dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).
+			Order("id ASC").Pluck("record_code", &remainingCodes).Error
+		if err != nil {
+			t.Fatalf("ReadRemainingCodesFailed: %v", err)
+		}
+		if strings.Join(remainingCodes, ",") != "Record3,Record4" {
+			t.Errorf("RemainingCodesMismatch: got %v", remainingCodes)
+		}
+	})
+}
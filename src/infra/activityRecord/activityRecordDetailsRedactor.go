@@ -0,0 +1,372 @@
+package tkInfraActivityRecord
+
+import (
+	"encoding"
+	"encoding/json"
+	"errors"
+	"path"
+	"reflect"
+	"strconv"
+	"strings"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+const (
+	// ActivityRecordDetailsRedactedValue replaces the sensitive RecordDetails values.
+	ActivityRecordDetailsRedactedValue string = "[REDACTED]"
+
+	// ActivityRecordDetailsRedactTagName is the struct tag opting a field in
+	// (`redact:"true"`) or out (`redact:"false"`) of the redaction.
+	ActivityRecordDetailsRedactTagName string = "redact"
+
+	activityRecordDetailsRedactorMaxDepth int = 32
+)
+
+// ActivityRecordDetailsSensitiveKeyPatternsDefault are the key name patterns redacted
+// when ActivityRecordDetailsRedactorSettings.SensitiveKeyPatterns is nil.
+var ActivityRecordDetailsSensitiveKeyPatternsDefault = []string{
+	"*password*", "*passphrase*", "*secret*", "*token*", "*apikey*", "*api_key*",
+	"*privatekey*", "*private_key*", "authorization", "cookie",
+}
+
+// activityRecordDetailsSensitiveTypes are always redacted, whatever their key name.
+var activityRecordDetailsSensitiveTypes = map[reflect.Type]struct{}{
+	reflect.TypeFor[tkValueObject.Password]():            {},
+	reflect.TypeFor[tkValueObject.WeakPassword]():        {},
+	reflect.TypeFor[tkValueObject.AccessTokenValue]():    {},
+	reflect.TypeFor[tkValueObject.EnvelopedPrivateKey](): {},
+}
+
+var (
+	activityRecordDetailsJsonMarshalerType = reflect.TypeFor[json.Marshaler]()
+	activityRecordDetailsTextMarshalerType = reflect.TypeFor[encoding.TextMarshaler]()
+)
+
+// ActivityRecordDetailsRedactorSettings.SensitiveKeyPatterns are case-insensitive glob
+// patterns (path.Match syntax) matched against the map keys and the JSON names of the
+// struct fields. nil uses ActivityRecordDetailsSensitiveKeyPatternsDefault, while an
+// empty slice disables the key name matching.
+type ActivityRecordDetailsRedactorSettings struct {
+	SensitiveKeyPatterns []string
+}
+
+// ActivityRecordDetailsRedactor masks the sensitive values of the RecordDetails before
+// they are persisted: the values of the sensitive value object types (Password,
+// WeakPassword, AccessTokenValue and EnvelopedPrivateKey), the values whose key name
+// matches a sensitive key pattern and the struct fields tagged `redact:"true"`. Fields
+// tagged `redact:"false"` are never masked themselves, though their content still is.
+//
+// Types implementing json.Marshaler or encoding.TextMarshaler (other than the sensitive
+// ones) are kept as they are, since their JSON form can't be walked.
+type ActivityRecordDetailsRedactor struct {
+	sensitiveKeyPatterns []string
+}
+
+func NewActivityRecordDetailsRedactor(
+	settings ActivityRecordDetailsRedactorSettings,
+) (*ActivityRecordDetailsRedactor, error) {
+	rawKeyPatterns := settings.SensitiveKeyPatterns
+	if rawKeyPatterns == nil {
+		rawKeyPatterns = ActivityRecordDetailsSensitiveKeyPatternsDefault
+	}
+
+	sensitiveKeyPatterns := make([]string, 0, len(rawKeyPatterns))
+	for _, rawKeyPattern := range rawKeyPatterns {
+		keyPattern := strings.ToLower(strings.TrimSpace(rawKeyPattern))
+		if keyPattern == "" {
+			continue
+		}
+		_, err := path.Match(keyPattern, "")
+		if err != nil {
+			return nil, errors.New("InvalidSensitiveKeyPattern: " + rawKeyPattern)
+		}
+		sensitiveKeyPatterns = append(sensitiveKeyPatterns, keyPattern)
+	}
+
+	return &ActivityRecordDetailsRedactor{sensitiveKeyPatterns: sensitiveKeyPatterns}, nil
+}
+
+// activityRecordDetailsRedactorDefault is used by the repositories when no redactor
+// is given; the default patterns are valid, hence the ignored error.
+var activityRecordDetailsRedactorDefault, _ = NewActivityRecordDetailsRedactor(
+	ActivityRecordDetailsRedactorSettings{},
+)
+
+func (redactor *ActivityRecordDetailsRedactor) isSensitiveKey(keyName string) bool {
+	lowerKeyName := strings.ToLower(keyName)
+	for _, keyPattern := range redactor.sensitiveKeyPatterns {
+		isMatch, _ := path.Match(keyPattern, lowerKeyName)
+		if isMatch {
+			return true
+		}
+	}
+	return false
+}
+
+// Redact returns the RecordDetails with the sensitive values masked. Structs and maps
+// holding sensitive values are returned as JSON objects (map[string]any) honoring the
+// json struct tags; values without anything to mask are returned untouched.
+func (redactor *ActivityRecordDetailsRedactor) Redact(recordDetails any) any {
+	if recordDetails == nil {
+		return nil
+	}
+
+	redactedValue, isRedacted := redactor.redactValue(reflect.ValueOf(recordDetails), 0)
+	if !isRedacted {
+		return recordDetails
+	}
+	return redactedValue
+}
+
+// redactValue returns the redacted value and whether anything was masked. When nothing
+// was, the returned value must be ignored in favor of the original one.
+func (redactor *ActivityRecordDetailsRedactor) redactValue(
+	rawValue reflect.Value, depth int,
+) (redactedValue any, isRedacted bool) {
+	if depth > activityRecordDetailsRedactorMaxDepth {
+		return nil, false
+	}
+
+	for rawValue.Kind() == reflect.Pointer || rawValue.Kind() == reflect.Interface {
+		if rawValue.IsNil() {
+			return nil, false
+		}
+		rawValue = rawValue.Elem()
+	}
+
+	if _, isSensitiveType := activityRecordDetailsSensitiveTypes[rawValue.Type()]; isSensitiveType {
+		return ActivityRecordDetailsRedactedValue, true
+	}
+
+	if activityRecordDetailsIsMarshaler(rawValue.Type()) {
+		return nil, false
+	}
+
+	switch rawValue.Kind() {
+	case reflect.Struct:
+		return redactor.redactStruct(rawValue, depth)
+	case reflect.Map:
+		return redactor.redactMap(rawValue, depth)
+	case reflect.Slice, reflect.Array:
+		if rawValue.Kind() == reflect.Slice && rawValue.IsNil() {
+			return nil, false
+		}
+		if rawValue.Type().Elem().Kind() == reflect.Uint8 {
+			return nil, false
+		}
+
+		redactedItems := make([]any, rawValue.Len())
+		for itemIndex := range rawValue.Len() {
+			itemValue := rawValue.Index(itemIndex)
+			redactedItem, isItemRedacted := redactor.redactValue(itemValue, depth+1)
+			if !isItemRedacted {
+				redactedItem = itemValue.Interface()
+			}
+			redactedItems[itemIndex] = redactedItem
+			isRedacted = isRedacted || isItemRedacted
+		}
+		return redactedItems, isRedacted
+	default:
+		return nil, false
+	}
+}
+
+// redactKeyedValue redacts the value of a map key or struct field.
+func (redactor *ActivityRecordDetailsRedactor) redactKeyedValue(
+	keyName string, keyedValue reflect.Value, redactTagValue string, depth int,
+) (redactedValue any, isRedacted bool) {
+	isNil := !keyedValue.IsValid() ||
+		(activityRecordDetailsIsNillableKind(keyedValue.Kind()) && keyedValue.IsNil())
+	if isNil {
+		return nil, false
+	}
+
+	switch redactTagValue {
+	case "true":
+		return ActivityRecordDetailsRedactedValue, true
+	case "false":
+		for keyedValue.Kind() == reflect.Pointer || keyedValue.Kind() == reflect.Interface {
+			if keyedValue.IsNil() {
+				return nil, false
+			}
+			keyedValue = keyedValue.Elem()
+		}
+		if _, isSensitiveType := activityRecordDetailsSensitiveTypes[keyedValue.Type()]; isSensitiveType {
+			return nil, false
+		}
+	default:
+		if redactor.isSensitiveKey(keyName) {
+			return ActivityRecordDetailsRedactedValue, true
+		}
+	}
+
+	redactedValue, isRedacted = redactor.redactValue(keyedValue, depth+1)
+	if !isRedacted {
+		return nil, false
+	}
+	return redactedValue, true
+}
+
+// activityRecordDetailsIsNillableKind reports whether the kind has a nil value.
+func activityRecordDetailsIsNillableKind(valueKind reflect.Kind) bool {
+	switch valueKind {
+	case reflect.Pointer, reflect.Interface, reflect.Map, reflect.Slice,
+		reflect.Func, reflect.Chan:
+		return true
+	}
+	return false
+}
+
+// activityRecordDetailsIsMarshaler reports whether encoding/json encodes the type with
+// its own MarshalJSON or MarshalText method.
+func activityRecordDetailsIsMarshaler(valueType reflect.Type) bool {
+	for _, marshalerType := range []reflect.Type{
+		activityRecordDetailsJsonMarshalerType, activityRecordDetailsTextMarshalerType,
+	} {
+		if valueType.Implements(marshalerType) ||
+			reflect.PointerTo(valueType).Implements(marshalerType) {
+			return true
+		}
+	}
+	return false
+}
+
+// activityRecordDetailsMapKeyReader returns the key as encoding/json would name it.
+func activityRecordDetailsMapKeyReader(mapKey reflect.Value) (string, bool) {
+	if mapKey.Kind() == reflect.String {
+		return mapKey.String(), true
+	}
+	if mapKey.Type().Implements(activityRecordDetailsTextMarshalerType) {
+		keyBytes, err := mapKey.Interface().(encoding.TextMarshaler).MarshalText()
+		return string(keyBytes), err == nil
+	}
+
+	switch mapKey.Kind() {
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return strconv.FormatInt(mapKey.Int(), 10), true
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64,
+		reflect.Uintptr:
+		return strconv.FormatUint(mapKey.Uint(), 10), true
+	}
+	return "", false
+}
+
+func (redactor *ActivityRecordDetailsRedactor) redactMap(
+	rawMap reflect.Value, depth int,
+) (redactedValue any, isRedacted bool) {
+	if rawMap.IsNil() {
+		return nil, false
+	}
+
+	redactedMap := make(map[string]any, rawMap.Len())
+	mapIterator := rawMap.MapRange()
+	for mapIterator.Next() {
+		keyName, isKeySupported := activityRecordDetailsMapKeyReader(mapIterator.Key())
+		if !isKeySupported {
+			return nil, false
+		}
+
+		mapValue := mapIterator.Value()
+		redactedMapValue, isMapValueRedacted := redactor.redactKeyedValue(
+			keyName, mapValue, "", depth,
+		)
+		if !isMapValueRedacted {
+			redactedMapValue = mapValue.Interface()
+		}
+		redactedMap[keyName] = redactedMapValue
+		isRedacted = isRedacted || isMapValueRedacted
+	}
+
+	return redactedMap, isRedacted
+}
+
+// activityRecordDetailsIsEmptyValue mirrors the encoding/json omitempty rule.
+func activityRecordDetailsIsEmptyValue(rawValue reflect.Value) bool {
+	switch rawValue.Kind() {
+	case reflect.Array, reflect.Map, reflect.Slice, reflect.String:
+		return rawValue.Len() == 0
+	case reflect.Bool, reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32,
+		reflect.Int64, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32,
+		reflect.Uint64, reflect.Uintptr, reflect.Float32, reflect.Float64,
+		reflect.Interface, reflect.Pointer:
+		return rawValue.IsZero()
+	}
+	return false
+}
+
+// redactStruct walks the exported fields the way encoding/json encodes them: json tag
+// names, "-" and omitempty are honored and the fields of untagged embedded structs are
+// promoted, unless shadowed by a field of the outer struct.
+func (redactor *ActivityRecordDetailsRedactor) redactStruct(
+	rawStruct reflect.Value, depth int,
+) (redactedValue any, isRedacted bool) {
+	redactedStruct := map[string]any{}
+	promotedFields := map[string]any{}
+
+	structType := rawStruct.Type()
+	for fieldIndex := range structType.NumField() {
+		structField := structType.Field(fieldIndex)
+		if !structField.IsExported() && !structField.Anonymous {
+			continue
+		}
+
+		jsonTag := structField.Tag.Get("json")
+		if jsonTag == "-" {
+			continue
+		}
+		jsonName, jsonOptions, _ := strings.Cut(jsonTag, ",")
+		fieldValue := rawStruct.Field(fieldIndex)
+
+		if structField.Anonymous && jsonName == "" {
+			embeddedValue := fieldValue
+			if embeddedValue.Kind() == reflect.Pointer {
+				if embeddedValue.IsNil() {
+					continue
+				}
+				embeddedValue = embeddedValue.Elem()
+			}
+			if embeddedValue.Kind() == reflect.Struct &&
+				!activityRecordDetailsIsMarshaler(embeddedValue.Type()) {
+				redactedEmbedded, isEmbeddedRedacted := redactor.redactStruct(
+					embeddedValue, depth+1,
+				)
+				for keyName, keyValue := range redactedEmbedded.(map[string]any) {
+					promotedFields[keyName] = keyValue
+				}
+				isRedacted = isRedacted || isEmbeddedRedacted
+				continue
+			}
+			if !structField.IsExported() {
+				continue
+			}
+		}
+
+		if jsonName == "" {
+			jsonName = structField.Name
+		}
+		if strings.Contains(","+jsonOptions+",", ",omitempty,") &&
+			activityRecordDetailsIsEmptyValue(fieldValue) {
+			continue
+		}
+
+		redactedFieldValue, isFieldRedacted := redactor.redactKeyedValue(
+			jsonName, fieldValue,
+			structField.Tag.Get(ActivityRecordDetailsRedactTagName), depth,
+		)
+		if !isFieldRedacted {
+			redactedFieldValue = fieldValue.Interface()
+		}
+		redactedStruct[jsonName] = redactedFieldValue
+		isRedacted = isRedacted || isFieldRedacted
+	}
+
+	for keyName, keyValue := range promotedFields {
+		if _, isShadowed := redactedStruct[keyName]; isShadowed {
+			continue
+		}
+		redactedStruct[keyName] = keyValue
+	}
+
+	return redactedStruct, isRedacted
+}
@@ -0,0 +1,176 @@
+package tkInfraActivityRecord
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"strings"
+
+	tkInfra "github.com/goinfinite/tk/src/infra"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+)
+
+const (
+	ActivityRecordEncryptionSecretKeyEnvVarName          string = "ACTIVITY_RECORD_ENCRYPTION_SECRET_KEY"
+	ActivityRecordEncryptionPreviousSecretKeysEnvVarName string = "ACTIVITY_RECORD_ENCRYPTION_PREVIOUS_SECRET_KEYS"
+	// ActivityRecordEncryptedValuePrefix starts every encrypted column value, followed
+	// by the key ID, a colon and the tkInfra.Cypher output. Values without it are
+	// plain, e.g. written before the encryption was enabled.
+	ActivityRecordEncryptedValuePrefix string = "tkenc:"
+)
+
+const (
+	activityRecordEncryptionKeyIdHexLength        int    = 8
+	activityRecordEncryptionPreviousKeysSeparator string = ","
+
+	errActivityRecordEncryptionSecretKeyNotSet string = "ActivityRecordEncryptionSecretKeyNotSet"
+	errActivityRecordEncryptionKeyNotFound     string = "ActivityRecordEncryptionKeyNotFound"
+	errOperatorIpAddressFilterEncrypted        string = "OperatorIpAddressFilterNotSupportedWhenEncrypted"
+	errRecordDetailsFiltersEncrypted           string = "RecordDetailsFiltersNotSupportedWhenEncrypted"
+)
+
+// activityRecordFieldsEncryptor encrypts the RecordDetails and/or OperatorIpAddress
+// columns with the current key, tagging each value with the key ID, and decrypts them
+// with whichever key, current or previous, the tag points to.
+type activityRecordFieldsEncryptor struct {
+	shouldEncryptRecordDetails     bool
+	shouldEncryptOperatorIpAddress bool
+	currentKeyId                   string
+	cyphersByKeyId                 map[string]*tkInfra.Cypher
+}
+
+// ActivityRecordEncryptionKeyIdFactory returns the ID tagging the values encrypted
+// with the key: the first hex chars of the key SHA-256, so it doesn't need to be
+// configured and doesn't expose the key.
+func ActivityRecordEncryptionKeyIdFactory(encodedSecretKey string) string {
+	secretKeyHash := sha256.Sum256([]byte(encodedSecretKey))
+	return hex.EncodeToString(secretKeyHash[:])[:activityRecordEncryptionKeyIdHexLength]
+}
+
+func newActivityRecordFieldsEncryptor(
+	settings ActivityRecordRepoSettings,
+) (*activityRecordFieldsEncryptor, error) {
+	isEncryptionEnabled := settings.ShouldEncryptRecordDetails ||
+		settings.ShouldEncryptOperatorIpAddress
+	if !isEncryptionEnabled && settings.EncryptionSecretKey == "" {
+		return nil, nil
+	}
+
+	currentSecretKey, previousSecretKeys, err := settings.readEncryptionSecretKeys()
+	if err != nil {
+		return nil, err
+	}
+
+	encryptor := &activityRecordFieldsEncryptor{
+		shouldEncryptRecordDetails:     settings.ShouldEncryptRecordDetails,
+		shouldEncryptOperatorIpAddress: settings.ShouldEncryptOperatorIpAddress,
+		currentKeyId:                   ActivityRecordEncryptionKeyIdFactory(currentSecretKey),
+		cyphersByKeyId:                 map[string]*tkInfra.Cypher{},
+	}
+	for _, secretKey := range append([]string{currentSecretKey}, previousSecretKeys...) {
+		cypher, err := tkInfra.NewCypher(secretKey)
+		if err != nil {
+			return nil, errors.New("InvalidActivityRecordEncryptionSecretKey: " + err.Error())
+		}
+
+		keyId := ActivityRecordEncryptionKeyIdFactory(secretKey)
+		if _, isKeyIdTaken := encryptor.cyphersByKeyId[keyId]; isKeyIdTaken {
+			continue
+		}
+		encryptor.cyphersByKeyId[keyId] = cypher
+	}
+
+	return encryptor, nil
+}
+
+func (encryptor *activityRecordFieldsEncryptor) encryptValue(
+	plainValue *string,
+) (*string, error) {
+	if plainValue == nil {
+		return nil, nil
+	}
+
+	encryptedText, err := encryptor.cyphersByKeyId[encryptor.currentKeyId].Encrypt(*plainValue)
+	if err != nil {
+		return nil, err
+	}
+
+	encryptedValue := ActivityRecordEncryptedValuePrefix + encryptor.currentKeyId + ":" +
+		encryptedText
+	return &encryptedValue, nil
+}
+
+func (encryptor *activityRecordFieldsEncryptor) decryptValue(
+	storedValue *string,
+) (*string, error) {
+	if storedValue == nil {
+		return nil, nil
+	}
+
+	taggedEncryptedText, isEncrypted := strings.CutPrefix(
+		*storedValue, ActivityRecordEncryptedValuePrefix,
+	)
+	if !isEncrypted {
+		return storedValue, nil
+	}
+
+	keyId, encryptedText, _ := strings.Cut(taggedEncryptedText, ":")
+	cypher, isKeyFound := encryptor.cyphersByKeyId[keyId]
+	if !isKeyFound {
+		return nil, errors.New(errActivityRecordEncryptionKeyNotFound + ": " + keyId)
+	}
+
+	plainValue, err := cypher.Decrypt(encryptedText)
+	if err != nil {
+		return nil, err
+	}
+
+	return &plainValue, nil
+}
+
+// encryptModel encrypts the enabled columns of a model about to be inserted.
+func (encryptor *activityRecordFieldsEncryptor) encryptModel(
+	activityRecordModel *tkInfraDbModel.ActivityRecord,
+) (err error) {
+	if encryptor.shouldEncryptRecordDetails {
+		activityRecordModel.RecordDetails, err = encryptor.encryptValue(
+			activityRecordModel.RecordDetails,
+		)
+		if err != nil {
+			return errors.New("EncryptRecordDetailsError: " + err.Error())
+		}
+	}
+
+	if encryptor.shouldEncryptOperatorIpAddress {
+		activityRecordModel.OperatorIpAddress, err = encryptor.encryptValue(
+			activityRecordModel.OperatorIpAddress,
+		)
+		if err != nil {
+			return errors.New("EncryptOperatorIpAddressError: " + err.Error())
+		}
+	}
+
+	return nil
+}
+
+// decryptModel returns a copy of the model with every encrypted column decrypted,
+// whether its encryption is still enabled or not.
+func (encryptor *activityRecordFieldsEncryptor) decryptModel(
+	activityRecordModel tkInfraDbModel.ActivityRecord,
+) (decryptedModel tkInfraDbModel.ActivityRecord, err error) {
+	decryptedModel = activityRecordModel
+
+	decryptedModel.RecordDetails, err = encryptor.decryptValue(activityRecordModel.RecordDetails)
+	if err != nil {
+		return decryptedModel, errors.New("DecryptRecordDetailsError: " + err.Error())
+	}
+
+	decryptedModel.OperatorIpAddress, err = encryptor.decryptValue(
+		activityRecordModel.OperatorIpAddress,
+	)
+	if err != nil {
+		return decryptedModel, errors.New("DecryptOperatorIpAddressError: " + err.Error())
+	}
+
+	return decryptedModel, nil
+}
@@ -0,0 +1,267 @@
+package tkInfraActivityRecord
+
+import (
+	"strings"
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfra "github.com/goinfinite/tk/src/infra"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+)
+
+func TestActivityRecordFieldsEncryptor(t *testing.T) {
+	oldSecretKey, _ := tkInfra.NewCypherSecretKey()
+	newSecretKey, _ := tkInfra.NewCypherSecretKey()
+
+	setupEncryptedRepos := func(
+		t *testing.T, dbSvc *tkInfraDb.TrailDatabaseService,
+		repoSettings ActivityRecordRepoSettings,
+	) (*ActivityRecordCmdRepo, *ActivityRecordQueryRepo) {
+		t.Helper()
+		cmdRepo, err := NewActivityRecordCmdRepoWithSettings(dbSvc, repoSettings)
+		if err != nil {
+			t.Fatalf("CreateCmdRepoFailed: %v", err)
+		}
+		queryRepo, err := NewActivityRecordQueryRepoWithSettings(dbSvc, repoSettings)
+		if err != nil {
+			t.Fatalf("CreateQueryRepoFailed: %v", err)
+		}
+		return cmdRepo, queryRepo
+	}
+
+	createEncryptionTestRecord := func(
+		t *testing.T, cmdRepo *ActivityRecordCmdRepo, rawRecordCode string,
+	) {
+		t.Helper()
+		operatorIpAddress := tkValueObject.IpAddress("203.0.113.7")
+		err := cmdRepo.Create(tkDto.CreateActivityRecord{
+			RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:        tkValueObject.ActivityRecordCode(rawRecordCode),
+			RecordDetails:     map[string]any{"email": "jane@example.com"},
+			OperatorIpAddress: &operatorIpAddress,
+		})
+		if err != nil {
+			t.Fatalf("CreateFailed: %v", err)
+		}
+	}
+
+	readStoredModels := func(
+		t *testing.T, dbSvc *tkInfraDb.TrailDatabaseService,
+	) []tkInfraDbModel.ActivityRecord {
+		t.Helper()
+		recordModels := []tkInfraDbModel.ActivityRecord{}
+		err := dbSvc.Handler.Order("id ASC").Find(&recordModels).Error
+		if err != nil {
+			t.Fatalf("ReadStoredModelsFailed: %v", err)
+		}
+		return recordModels
+	}
+
+	readRecordsContent := func(
+		t *testing.T, queryRepo *ActivityRecordQueryRepo,
+	) (recordsContent []string, err error) {
+		t.Helper()
+		responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+			Pagination: tkDto.PaginationUnpaginated,
+		})
+		if err != nil {
+			return recordsContent, err
+		}
+
+		for _, activityRecord := range responseDto.ActivityRecords {
+			recordsContent = append(
+				recordsContent,
+				activityRecord.RecordDetails.(string)+" "+activityRecord.OperatorIpAddress.String(),
+			)
+		}
+		return recordsContent, nil
+	}
+
+	expectedRecordContent := `{"email":"jane@example.com"} 203.0.113.7`
+
+	t.Run("EncryptsAtRestAndDecryptsOnRead", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		plainCmdRepo, _ := setupEncryptedRepos(t, dbSvc, ActivityRecordRepoSettings{})
+		createEncryptionTestRecord(t, plainCmdRepo, "WrittenBeforeEncryption")
+
+		cmdRepo, queryRepo := setupEncryptedRepos(t, dbSvc, ActivityRecordRepoSettings{
+			ShouldEncryptRecordDetails:     true,
+			ShouldEncryptOperatorIpAddress: true,
+			EncryptionSecretKey:            newSecretKey,
+		})
+		createEncryptionTestRecord(t, cmdRepo, "WrittenAfterEncryption")
+
+		storedModels := readStoredModels(t, dbSvc)
+		if strings.HasPrefix(*storedModels[0].RecordDetails, ActivityRecordEncryptedValuePrefix) {
+			t.Errorf("PlainRecordEncrypted: %s", *storedModels[0].RecordDetails)
+		}
+		expectedTag := ActivityRecordEncryptedValuePrefix +
+			ActivityRecordEncryptionKeyIdFactory(newSecretKey) + ":"
+		for _, storedValue := range []string{
+			*storedModels[1].RecordDetails, *storedModels[1].OperatorIpAddress,
+		} {
+			if !strings.HasPrefix(storedValue, expectedTag) || strings.Contains(storedValue, "jane") {
+				t.Errorf("ValueNotEncrypted: %s", storedValue)
+			}
+		}
+
+		recordsContent, err := readRecordsContent(t, queryRepo)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		for _, recordContent := range recordsContent {
+			if recordContent != expectedRecordContent {
+				t.Errorf("RecordContentMismatch: %s", recordContent)
+			}
+		}
+	})
+
+	t.Run("KeyRotation", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		oldKeyCmdRepo, _ := setupEncryptedRepos(t, dbSvc, ActivityRecordRepoSettings{
+			ShouldEncryptRecordDetails: true,
+			EncryptionSecretKey:        oldSecretKey,
+		})
+		createEncryptionTestRecord(t, oldKeyCmdRepo, "WrittenWithOldKey")
+
+		rotatedSettings := ActivityRecordRepoSettings{
+			ShouldEncryptRecordDetails:   true,
+			EncryptionSecretKey:          newSecretKey,
+			EncryptionPreviousSecretKeys: []string{oldSecretKey},
+		}
+		rotatedCmdRepo, rotatedQueryRepo := setupEncryptedRepos(t, dbSvc, rotatedSettings)
+		createEncryptionTestRecord(t, rotatedCmdRepo, "WrittenWithNewKey")
+
+		storedModels := readStoredModels(t, dbSvc)
+		for modelIndex, secretKey := range []string{oldSecretKey, newSecretKey} {
+			expectedTag := ActivityRecordEncryptedValuePrefix +
+				ActivityRecordEncryptionKeyIdFactory(secretKey) + ":"
+			if !strings.HasPrefix(*storedModels[modelIndex].RecordDetails, expectedTag) {
+				t.Errorf("KeyIdTagMismatch: %s", *storedModels[modelIndex].RecordDetails)
+			}
+		}
+
+		recordsContent, err := readRecordsContent(t, rotatedQueryRepo)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if len(recordsContent) != 2 || recordsContent[0] != expectedRecordContent {
+			t.Errorf("RotatedRecordsContentMismatch: %v", recordsContent)
+		}
+
+		_, forgetfulQueryRepo := setupEncryptedRepos(t, dbSvc, ActivityRecordRepoSettings{
+			ShouldEncryptRecordDetails: true,
+			EncryptionSecretKey:        newSecretKey,
+		})
+		_, err = readRecordsContent(t, forgetfulQueryRepo)
+		if err == nil || !strings.Contains(err.Error(), errActivityRecordEncryptionKeyNotFound) {
+			t.Errorf("MissingExpectedError: %s (got %v)", errActivityRecordEncryptionKeyNotFound, err)
+		}
+	})
+
+	t.Run("EncryptedColumnsNotFilterable", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		cmdRepo, queryRepo := setupEncryptedRepos(t, dbSvc, ActivityRecordRepoSettings{
+			ShouldEncryptRecordDetails:     true,
+			ShouldEncryptOperatorIpAddress: true,
+			EncryptionSecretKey:            newSecretKey,
+		})
+		createEncryptionTestRecord(t, cmdRepo, "NotFilterable")
+
+		operatorIpAddress := tkValueObject.IpAddress("203.0.113.7")
+		operatorIpAddressSortBy := tkValueObject.PaginationSortBy("operatorIpAddress")
+		testCaseStructs := []struct {
+			name          string
+			requestDto    tkDto.ReadActivityRecordsRequest
+			expectedError string
+		}{
+			{
+				"OperatorIpAddress",
+				tkDto.ReadActivityRecordsRequest{OperatorIpAddress: &operatorIpAddress},
+				errOperatorIpAddressFilterEncrypted,
+			},
+			{
+				"RecordDetailsFilters",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: []tkDto.ActivityRecordDetailsFilter{{
+						Path:     "email",
+						Operator: tkValueObject.ComparisonOperatorEqual,
+						Value:    "jane@example.com",
+					}},
+				},
+				errRecordDetailsFiltersEncrypted,
+			},
+			{
+				"SortByOperatorIpAddress",
+				tkDto.ReadActivityRecordsRequest{
+					Pagination: tkDto.Pagination{
+						ItemsPerPage: 10, SortBy: &operatorIpAddressSortBy,
+					},
+				},
+				"PaginationSortByNotAllowed",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				_, err := queryRepo.Read(testCase.requestDto)
+				if err == nil || !strings.Contains(err.Error(), testCase.expectedError) {
+					t.Errorf("MissingExpectedError: %s (got %v)", testCase.expectedError, err)
+				}
+			})
+		}
+
+		_, err := cmdRepo.Delete(tkDto.DeleteActivityRecord{OperatorIpAddress: &operatorIpAddress})
+		if err == nil || err.Error() != errOperatorIpAddressFilterEncrypted {
+			t.Errorf("MissingExpectedError: %s (got %v)", errOperatorIpAddressFilterEncrypted, err)
+		}
+		if len(readStoredModels(t, dbSvc)) != 1 {
+			t.Error("RecordDeletedByUnsupportedFilter")
+		}
+	})
+
+	t.Run("SecretKeyFromEnv", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		repoSettings := ActivityRecordRepoSettings{ShouldEncryptRecordDetails: true}
+
+		t.Setenv(ActivityRecordEncryptionSecretKeyEnvVarName, "")
+		_, err := NewActivityRecordQueryRepoWithSettings(dbSvc, repoSettings)
+		if err == nil || err.Error() != errActivityRecordEncryptionSecretKeyNotSet {
+			t.Errorf("MissingExpectedError: %s (got %v)", errActivityRecordEncryptionSecretKeyNotSet, err)
+		}
+
+		t.Setenv(ActivityRecordEncryptionSecretKeyEnvVarName, "notBase64!")
+		_, err = NewActivityRecordQueryRepoWithSettings(dbSvc, repoSettings)
+		if err == nil {
+			t.Error("MissingExpectedError: InvalidActivityRecordEncryptionSecretKey")
+		}
+
+		t.Setenv(ActivityRecordEncryptionSecretKeyEnvVarName, newSecretKey)
+		t.Setenv(ActivityRecordEncryptionPreviousSecretKeysEnvVarName, " "+oldSecretKey+", ")
+		queryRepo, err := NewActivityRecordQueryRepoWithSettings(dbSvc, repoSettings)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if len(queryRepo.fieldsEncryptor.cyphersByKeyId) != 2 {
+			t.Errorf("UnexpectedKeysCount: %d", len(queryRepo.fieldsEncryptor.cyphersByKeyId))
+		}
+	})
+
+	t.Run("InvalidEncryptedValue", func(t *testing.T) {
+		encryptor, err := newActivityRecordFieldsEncryptor(ActivityRecordRepoSettings{
+			EncryptionSecretKey: newSecretKey,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		tamperedValue := ActivityRecordEncryptedValuePrefix +
+			ActivityRecordEncryptionKeyIdFactory(newSecretKey) + ":dGFtcGVyZWQ="
+		_, err = encryptor.decryptValue(&tamperedValue)
+		if err == nil {
+			t.Error("MissingExpectedError: TamperedValueDecrypted")
+		}
+	})
+}
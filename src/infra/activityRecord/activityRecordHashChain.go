@@ -0,0 +1,246 @@
+package tkInfraActivityRecord
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"strconv"
+	"sync"
+	"time"
+
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+const activityRecordChainHeadId uint64 = 1
+
+// activityRecordHashChainMutex serializes the chained writes of the process, since
+// reading the last hash and inserting the next record must happen atomically. Across
+// processes, they're serialized by the chain head row lock (see lockChainHead).
+var activityRecordHashChainMutex sync.Mutex
+
+type activityRecordHashChainer struct {
+	secretKeyBytes []byte
+}
+
+func newActivityRecordHashChainer(
+	settings ActivityRecordRepoSettings,
+) (*activityRecordHashChainer, error) {
+	if !settings.ShouldChainHashes {
+		return nil, nil
+	}
+
+	secretKey, err := settings.readHashChainSecretKey()
+	if err != nil {
+		return nil, err
+	}
+
+	return &activityRecordHashChainer{secretKeyBytes: []byte(secretKey)}, nil
+}
+
+func (chainer *activityRecordHashChainer) signCheckpoint(
+	recordId uint64, previousHash, anchorHash string,
+) string {
+	checkpointMac := hmac.New(sha256.New, chainer.secretKeyBytes)
+	checkpointMac.Write([]byte(
+		strconv.FormatUint(recordId, 10) + "\n" + previousHash + "\n" + anchorHash,
+	))
+	return hex.EncodeToString(checkpointMac.Sum(nil))
+}
+
+func (chainer *activityRecordHashChainer) isCheckpointValid(
+	checkpointModel tkInfraDbModel.ActivityRecordCheckpoint,
+) bool {
+	expectedSignature := chainer.signCheckpoint(
+		checkpointModel.ActivityRecordID,
+		checkpointModel.PreviousHash, checkpointModel.AnchorHash,
+	)
+	return hmac.Equal([]byte(expectedSignature), []byte(checkpointModel.Signature))
+}
+
+func (chainer *activityRecordHashChainer) signChainHead(
+	lastRecordId uint64, lastHash string,
+) string {
+	chainHeadMac := hmac.New(sha256.New, chainer.secretKeyBytes)
+	chainHeadMac.Write([]byte(
+		"chainHead\n" + strconv.FormatUint(lastRecordId, 10) + "\n" + lastHash,
+	))
+	return hex.EncodeToString(chainHeadMac.Sum(nil))
+}
+
+func (chainer *activityRecordHashChainer) isChainHeadValid(
+	chainHeadModel tkInfraDbModel.ActivityRecordChainHead,
+) bool {
+	expectedSignature := chainer.signChainHead(
+		chainHeadModel.LastRecordId, chainHeadModel.LastHash,
+	)
+	return hmac.Equal([]byte(expectedSignature), []byte(chainHeadModel.Signature))
+}
+
+// lockChainHead must be the first statement of the chained write transaction. It
+// creates the chain head row when missing and locks it (SELECT ... FOR UPDATE) until
+// the transaction ends, serializing the chained writes of every process sharing a
+// PostgreSQL or MySQL database. SQLite has no row locks but a single writer, so a
+// concurrent chained write from another process fails as busy instead of forking the
+// chain.
+func (chainer *activityRecordHashChainer) lockChainHead(
+	dbTx *gorm.DB,
+) (chainHeadModel tkInfraDbModel.ActivityRecordChainHead, err error) {
+	initialChainHeadModel := tkInfraDbModel.ActivityRecordChainHead{
+		ID:        activityRecordChainHeadId,
+		Signature: chainer.signChainHead(0, ""),
+	}
+	err = dbTx.Clauses(clause.OnConflict{DoNothing: true}).
+		Create(&initialChainHeadModel).Error
+	if err != nil {
+		return chainHeadModel, err
+	}
+
+	err = dbTx.Clauses(clause.Locking{Strength: clause.LockingStrengthUpdate}).
+		Where("id = ?", activityRecordChainHeadId).
+		Take(&chainHeadModel).Error
+	return chainHeadModel, err
+}
+
+// updateChainHead must run at the end of the chained write transaction, pointing the
+// chain head to the newest chained record left.
+func (chainer *activityRecordHashChainer) updateChainHead(dbTx *gorm.DB) error {
+	lastRecordModel := tkInfraDbModel.ActivityRecord{}
+	err := dbTx.Model(&tkInfraDbModel.ActivityRecord{}).
+		Select("id", "content_hash").
+		Where("content_hash IS NOT NULL").
+		Order("id DESC").Limit(1).
+		Find(&lastRecordModel).Error
+	if err != nil {
+		return err
+	}
+
+	lastHash := ""
+	if lastRecordModel.ContentHash != nil {
+		lastHash = *lastRecordModel.ContentHash
+	}
+
+	return dbTx.Model(&tkInfraDbModel.ActivityRecordChainHead{}).
+		Where("id = ?", activityRecordChainHeadId).
+		Updates(map[string]any{
+			"last_record_id": lastRecordModel.ID,
+			"last_hash":      lastHash,
+			"signature":      chainer.signChainHead(lastRecordModel.ID, lastHash),
+			"updated_at":     time.Now().UTC(),
+		}).Error
+}
+
+// readLastHash returns the content hash of the newest chained record, or an empty
+// string when the chain is yet to start.
+func (chainer *activityRecordHashChainer) readLastHash(
+	dbTx *gorm.DB, beforeRecordId *uint64,
+) (lastHash string, err error) {
+	lastHashes := []string{}
+	lastHashQuery := dbTx.Model(&tkInfraDbModel.ActivityRecord{}).
+		Where("content_hash IS NOT NULL")
+	if beforeRecordId != nil {
+		lastHashQuery = lastHashQuery.Where("id < ?", *beforeRecordId)
+	}
+	err = lastHashQuery.Order("id DESC").Limit(1).
+		Pluck("content_hash", &lastHashes).Error
+	if err != nil || len(lastHashes) == 0 {
+		return lastHash, err
+	}
+
+	return lastHashes[0], nil
+}
+
+// chainModels fills the CreatedAt, PreviousHash and ContentHash of the models about to
+// be inserted by dbTx, linking them to the chain head (or, until the chain head points
+// to a record, to the newest chained record) and to each other. Linking to the chain
+// head keeps a deleted tail noticeable even after new records are chained.
+func (chainer *activityRecordHashChainer) chainModels(
+	dbTx *gorm.DB,
+	chainHeadModel tkInfraDbModel.ActivityRecordChainHead,
+	activityRecordModels []tkInfraDbModel.ActivityRecord,
+) error {
+	previousHash := chainHeadModel.LastHash
+	if chainHeadModel.LastRecordId == 0 {
+		var err error
+		previousHash, err = chainer.readLastHash(dbTx, nil)
+		if err != nil {
+			return err
+		}
+	}
+
+	createdAt := time.Now().UTC().Truncate(time.Microsecond)
+	for modelIndex := range activityRecordModels {
+		activityRecordModels[modelIndex].CreatedAt = createdAt
+		linkedPreviousHash := previousHash
+		activityRecordModels[modelIndex].PreviousHash = &linkedPreviousHash
+		contentHash := activityRecordModels[modelIndex].ComputeContentHash(
+			chainer.secretKeyBytes, previousHash,
+		)
+		activityRecordModels[modelIndex].ContentHash = &contentHash
+		previousHash = contentHash
+	}
+
+	return nil
+}
+
+// checkpointSuccessors must run inside the deletion transaction, right after the
+// records were deleted. It (re)signs a checkpoint for every surviving record whose
+// link pointed to one of the deleted hashes, either directly via PreviousHash or via
+// the AnchorHash of an existing checkpoint.
+func (chainer *activityRecordHashChainer) checkpointSuccessors(
+	dbTx *gorm.DB, deletedRecordIds []uint64, deletedHashes []string,
+) error {
+	err := dbTx.Where("activity_record_id IN ?", deletedRecordIds).
+		Delete(&tkInfraDbModel.ActivityRecordCheckpoint{}).Error
+	if err != nil {
+		return err
+	}
+	if len(deletedHashes) == 0 {
+		return nil
+	}
+
+	successorModels := []tkInfraDbModel.ActivityRecord{}
+	err = dbTx.Model(&tkInfraDbModel.ActivityRecord{}).
+		Where(
+			"previous_hash IN ? OR id IN (?)", deletedHashes,
+			dbTx.Model(&tkInfraDbModel.ActivityRecordCheckpoint{}).
+				Select("activity_record_id").Where("anchor_hash IN ?", deletedHashes),
+		).
+		Order("id ASC").Find(&successorModels).Error
+	if err != nil {
+		return err
+	}
+
+	for _, successorModel := range successorModels {
+		if successorModel.PreviousHash == nil {
+			continue
+		}
+
+		anchorHash, err := chainer.readLastHash(dbTx, &successorModel.ID)
+		if err != nil {
+			return err
+		}
+
+		err = dbTx.Where("activity_record_id = ?", successorModel.ID).
+			Delete(&tkInfraDbModel.ActivityRecordCheckpoint{}).Error
+		if err != nil {
+			return err
+		}
+
+		checkpointModel := tkInfraDbModel.ActivityRecordCheckpoint{
+			ActivityRecordID: successorModel.ID,
+			PreviousHash:     *successorModel.PreviousHash,
+			AnchorHash:       anchorHash,
+			Signature: chainer.signCheckpoint(
+				successorModel.ID, *successorModel.PreviousHash, anchorHash,
+			),
+		}
+		err = dbTx.Create(&checkpointModel).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
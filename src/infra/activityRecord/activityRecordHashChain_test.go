@@ -0,0 +1,385 @@
+package tkInfraActivityRecord
+
+import (
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+)
+
+func TestActivityRecordHashChain(t *testing.T) {
+	repoSettings := ActivityRecordRepoSettings{
+		ShouldChainHashes:  true,
+		HashChainSecretKey: "hashChainTestSecretKey",
+	}
+
+	setupChainedRepos := func(t *testing.T) (
+		*tkInfraDb.TrailDatabaseService, *ActivityRecordCmdRepo, *ActivityRecordQueryRepo,
+	) {
+		t.Helper()
+		dbSvc := SetupTestTrailDatabaseService(t)
+		cmdRepo, err := NewActivityRecordCmdRepoWithSettings(dbSvc, repoSettings)
+		if err != nil {
+			t.Fatalf("CreateCmdRepoFailed: %v", err)
+		}
+		queryRepo, err := NewActivityRecordQueryRepoWithSettings(dbSvc, repoSettings)
+		if err != nil {
+			t.Fatalf("CreateQueryRepoFailed: %v", err)
+		}
+		return dbSvc, cmdRepo, queryRepo
+	}
+
+	createChainedRecords := func(
+		t *testing.T, cmdRepo *ActivityRecordCmdRepo, recordsCount int,
+	) {
+		t.Helper()
+		recordCodeVo, _ := tkValueObject.NewActivityRecordCode("CHAIN_TEST")
+		testSri, _ := tkValueObject.NewSystemResourceIdentifier("sri://0:test/chain")
+		for recordIndex := range recordsCount {
+			err := cmdRepo.Create(tkDto.CreateActivityRecord{
+				RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+				RecordCode:        recordCodeVo,
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{testSri},
+				RecordDetails:     map[string]any{"recordIndex": recordIndex},
+			})
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+	}
+
+	deleteRecordById := func(
+		t *testing.T, cmdRepo *ActivityRecordCmdRepo, recordId uint64,
+	) {
+		t.Helper()
+		recordIdVo, _ := tkValueObject.NewActivityRecordId(recordId)
+		_, err := cmdRepo.Delete(tkDto.NewDeleteActivityRecord(
+			&recordIdVo, nil, nil, nil, nil, nil, nil, nil,
+		))
+		if err != nil {
+			t.Fatalf("DeleteFailed: %v", err)
+		}
+	}
+
+	verifyChain := func(
+		t *testing.T, queryRepo *ActivityRecordQueryRepo,
+	) tkDto.VerifyActivityRecordsHashChainResponse {
+		t.Helper()
+		responseDto, err := queryRepo.VerifyHashChain()
+		if err != nil {
+			t.Fatalf("VerifyHashChainFailed: %v", err)
+		}
+		return responseDto
+	}
+
+	t.Run("IntactAfterCreates", func(t *testing.T) {
+		_, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 5)
+
+		responseDto := verifyChain(t, queryRepo)
+		if !responseDto.IsIntact {
+			t.Fatalf("ChainShouldBeIntact: %v", *responseDto.BrokenLinkReason)
+		}
+		if responseDto.VerifiedRecordsCount != 5 {
+			t.Errorf(
+				"VerifiedRecordsCountMismatch: expected 5, got %d",
+				responseDto.VerifiedRecordsCount,
+			)
+		}
+	})
+
+	t.Run("SkipsRecordsCreatedBeforeChaining", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		legacyCmdRepo, _ := NewActivityRecordCmdRepoWithSettings(
+			dbSvc, ActivityRecordRepoSettings{},
+		)
+		createChainedRecords(t, legacyCmdRepo, 3)
+		createChainedRecords(t, cmdRepo, 2)
+
+		responseDto := verifyChain(t, queryRepo)
+		if !responseDto.IsIntact || responseDto.VerifiedRecordsCount != 2 {
+			t.Errorf(
+				"UnexpectedVerification: intact %t, verified %d",
+				responseDto.IsIntact, responseDto.VerifiedRecordsCount,
+			)
+		}
+	})
+
+	t.Run("DetectsTamperedContent", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 5)
+
+		err := dbSvc.Handler.Exec(
+			"UPDATE activity_records SET record_details = ? WHERE id = ?",
+			`{"recordIndex":99}`, 3,
+		).Error
+		if err != nil {
+			t.Fatalf("TamperFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if responseDto.IsIntact {
+			t.Fatal("TamperNotDetected")
+		}
+		if responseDto.FirstBrokenRecordId.Uint64() != 3 {
+			t.Errorf(
+				"FirstBrokenRecordIdMismatch: expected 3, got %d",
+				responseDto.FirstBrokenRecordId.Uint64(),
+			)
+		}
+		if *responseDto.BrokenLinkReason != "ContentHashMismatch" {
+			t.Errorf("BrokenLinkReasonMismatch: got %s", *responseDto.BrokenLinkReason)
+		}
+	})
+
+	t.Run("DetectsChainRecomputedWithoutKey", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 5)
+
+		recordModels := []tkInfraDbModel.ActivityRecord{}
+		err := dbSvc.Handler.Preload("AffectedResources").Order("id ASC").
+			Find(&recordModels).Error
+		if err != nil {
+			t.Fatalf("ReadRecordsFailed: %v", err)
+		}
+
+		// Whoever only has database access rewrites the record 3 and relinks the
+		// following ones with hashes computed without the secret key.
+		tamperedDetails := `{"recordIndex":99}`
+		previousHash := *recordModels[1].ContentHash
+		for _, recordModel := range recordModels[2:] {
+			if recordModel.ID == 3 {
+				recordModel.RecordDetails = &tamperedDetails
+			}
+			contentHash := recordModel.ComputeContentHash(nil, previousHash)
+			err = dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).
+				Where("id = ?", recordModel.ID).
+				Updates(map[string]any{
+					"record_details": recordModel.RecordDetails,
+					"previous_hash":  previousHash,
+					"content_hash":   contentHash,
+				}).Error
+			if err != nil {
+				t.Fatalf("TamperFailed: %v", err)
+			}
+			previousHash = contentHash
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if responseDto.IsIntact {
+			t.Fatal("RecomputedChainNotDetected")
+		}
+		if responseDto.FirstBrokenRecordId.Uint64() != 3 {
+			t.Errorf(
+				"FirstBrokenRecordIdMismatch: expected 3, got %d",
+				responseDto.FirstBrokenRecordId.Uint64(),
+			)
+		}
+		if *responseDto.BrokenLinkReason != "ContentHashMismatch" {
+			t.Errorf("BrokenLinkReasonMismatch: got %s", *responseDto.BrokenLinkReason)
+		}
+	})
+
+	t.Run("DetectsDeletedTail", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name                        string
+			newRecordsCount             int
+			expectedFirstBrokenRecordId uint64
+			expectedBrokenLinkReason    string
+		}{
+			{"WithoutNewRecords", 0, 5, "ChainHeadMismatch"},
+			{"WithNewRecords", 2, 6, "PreviousHashMismatch"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+				createChainedRecords(t, cmdRepo, 5)
+
+				err := dbSvc.Handler.Exec(
+					"DELETE FROM activity_records WHERE id >= ?", 4,
+				).Error
+				if err != nil {
+					t.Fatalf("RawDeleteFailed: %v", err)
+				}
+				createChainedRecords(t, cmdRepo, testCase.newRecordsCount)
+
+				responseDto := verifyChain(t, queryRepo)
+				if responseDto.IsIntact {
+					t.Fatal("DeletedTailNotDetected")
+				}
+				if responseDto.FirstBrokenRecordId.Uint64() != testCase.expectedFirstBrokenRecordId {
+					t.Errorf(
+						"FirstBrokenRecordIdMismatch: expected %d, got %d",
+						testCase.expectedFirstBrokenRecordId,
+						responseDto.FirstBrokenRecordId.Uint64(),
+					)
+				}
+				if *responseDto.BrokenLinkReason != testCase.expectedBrokenLinkReason {
+					t.Errorf("BrokenLinkReasonMismatch: got %s", *responseDto.BrokenLinkReason)
+				}
+			})
+		}
+	})
+
+	t.Run("DetectsDeletedChainHead", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 3)
+
+		err := dbSvc.Handler.Exec("DELETE FROM activity_record_chain_heads").Error
+		if err != nil {
+			t.Fatalf("RawDeleteFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if responseDto.IsIntact || *responseDto.BrokenLinkReason != "ChainHeadNotFound" {
+			t.Errorf("DeletedChainHeadNotDetected: %+v", responseDto)
+		}
+	})
+
+	t.Run("DetectsRawDeletion", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 5)
+
+		err := dbSvc.Handler.Exec("DELETE FROM activity_records WHERE id = ?", 2).Error
+		if err != nil {
+			t.Fatalf("RawDeleteFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if responseDto.IsIntact {
+			t.Fatal("RawDeletionNotDetected")
+		}
+		if responseDto.FirstBrokenRecordId.Uint64() != 3 {
+			t.Errorf(
+				"FirstBrokenRecordIdMismatch: expected 3, got %d",
+				responseDto.FirstBrokenRecordId.Uint64(),
+			)
+		}
+		if *responseDto.BrokenLinkReason != "PreviousHashMismatch" {
+			t.Errorf("BrokenLinkReasonMismatch: got %s", *responseDto.BrokenLinkReason)
+		}
+	})
+
+	t.Run("IntactAfterRepoDeletions", func(t *testing.T) {
+		_, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 6)
+
+		// The record 3 anchors the checkpoint of the record 5 after the first deletion,
+		// so deleting it afterwards must re-sign that checkpoint. Deleting the tail
+		// moves the chain head.
+		deleteRecordById(t, cmdRepo, 4)
+		deleteRecordById(t, cmdRepo, 3)
+		deleteRecordById(t, cmdRepo, 1)
+		deleteRecordById(t, cmdRepo, 6)
+
+		responseDto := verifyChain(t, queryRepo)
+		if !responseDto.IsIntact {
+			t.Fatalf("ChainShouldBeIntact: %v", *responseDto.BrokenLinkReason)
+		}
+		if responseDto.VerifiedRecordsCount != 2 || responseDto.CheckpointsCount != 2 {
+			t.Errorf(
+				"UnexpectedVerification: verified %d, checkpoints %d",
+				responseDto.VerifiedRecordsCount, responseDto.CheckpointsCount,
+			)
+		}
+	})
+
+	t.Run("IntactAfterPurge", func(t *testing.T) {
+		_, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 10)
+
+		maxRecordsCount := uint64(4)
+		_, err := cmdRepo.Purge(tkDto.PurgeActivityRecordsRequest{
+			RetentionRules: []tkDto.ActivityRecordRetentionRule{
+				{MaxRecordsCount: &maxRecordsCount},
+			},
+			BatchSize: 3,
+		})
+		if err != nil {
+			t.Fatalf("PurgeFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if !responseDto.IsIntact || responseDto.VerifiedRecordsCount != 4 {
+			t.Errorf(
+				"UnexpectedVerification: intact %t, verified %d",
+				responseDto.IsIntact, responseDto.VerifiedRecordsCount,
+			)
+		}
+	})
+
+	t.Run("DetectsForgedCheckpoint", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		createChainedRecords(t, cmdRepo, 4)
+		deleteRecordById(t, cmdRepo, 2)
+
+		err := dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecordCheckpoint{}).
+			Where("activity_record_id = ?", 3).
+			Update("signature", "forged").Error
+		if err != nil {
+			t.Fatalf("ForgeCheckpointFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if responseDto.IsIntact {
+			t.Fatal("ForgedCheckpointNotDetected")
+		}
+		if responseDto.FirstBrokenRecordId.Uint64() != 3 {
+			t.Errorf(
+				"FirstBrokenRecordIdMismatch: expected 3, got %d",
+				responseDto.FirstBrokenRecordId.Uint64(),
+			)
+		}
+	})
+
+	t.Run("AsyncCmdRepoKeepsChain", func(t *testing.T) {
+		dbSvc, cmdRepo, queryRepo := setupChainedRepos(t)
+		asyncCmdRepo := NewActivityRecordAsyncCmdRepo(
+			dbSvc, ActivityRecordAsyncCmdRepoSettings{SyncCmdRepo: cmdRepo, BatchSize: 4},
+		)
+
+		recordCodeVo, _ := tkValueObject.NewActivityRecordCode("ASYNC_CHAIN_TEST")
+		for range 10 {
+			err := asyncCmdRepo.Create(tkDto.CreateActivityRecord{
+				RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+				RecordCode:        recordCodeVo,
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{},
+			})
+			if err != nil {
+				t.Fatalf("CreateFailed: %v", err)
+			}
+		}
+		err := asyncCmdRepo.Close()
+		if err != nil {
+			t.Fatalf("CloseFailed: %v", err)
+		}
+
+		responseDto := verifyChain(t, queryRepo)
+		if !responseDto.IsIntact || responseDto.VerifiedRecordsCount != 10 {
+			t.Errorf(
+				"UnexpectedVerification: intact %t, verified %d",
+				responseDto.IsIntact, responseDto.VerifiedRecordsCount,
+			)
+		}
+	})
+
+	t.Run("RequiresSecretKey", func(t *testing.T) {
+		t.Setenv(ActivityRecordHashChainSecretKeyEnvVarName, "")
+		dbSvc := SetupTestTrailDatabaseService(t)
+
+		_, err := NewActivityRecordCmdRepoWithSettings(
+			dbSvc, ActivityRecordRepoSettings{ShouldChainHashes: true},
+		)
+		if err == nil || err.Error() != errActivityRecordHashChainSecretKeyNotSet {
+			t.Errorf("MissingExpectedError: %s", errActivityRecordHashChainSecretKeyNotSet)
+		}
+
+		_, err = NewActivityRecordQueryRepo(dbSvc).VerifyHashChain()
+		if err == nil {
+			t.Error("MissingExpectedError: ActivityRecordHashChainNotEnabled")
+		}
+	})
+}
@@ -0,0 +1,194 @@
+package tkInfraActivityRecord
+
+import (
+	"encoding/json"
+	"slices"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+// ActivityRecordInMemoryCmdRepo is a tkRepository.ActivityRecordCmdRepo writing to the
+// records of an ActivityRecordInMemoryQueryRepo, with the ActivityRecordCmdRepo
+// semantics: sequential IDs, RecordDetails redacted (with the default redactor) and
+// stored as JSON, and account scoped deletions.
+type ActivityRecordInMemoryCmdRepo struct {
+	queryRepo *ActivityRecordInMemoryQueryRepo
+}
+
+func NewActivityRecordInMemoryCmdRepo(
+	queryRepo *ActivityRecordInMemoryQueryRepo,
+) *ActivityRecordInMemoryCmdRepo {
+	return &ActivityRecordInMemoryCmdRepo{queryRepo: queryRepo}
+}
+
+// createDtoToEntity returns the entity as it would be read back from the database,
+// i.e. with the RecordDetails JSON encoded. The record ID is set on insertion.
+func (repo *ActivityRecordInMemoryCmdRepo) createDtoToEntity(
+	createDto tkDto.CreateActivityRecord,
+) (activityRecord tkEntity.ActivityRecord, err error) {
+	var recordDetails any
+	if createDto.RecordDetails != nil {
+		recordDetailsBytes, err := json.Marshal(
+			activityRecordDetailsRedactorDefault.Redact(createDto.RecordDetails),
+		)
+		if err != nil {
+			return activityRecord, err
+		}
+		recordDetails = string(recordDetailsBytes)
+	}
+
+	affectedResources := slices.Clone(createDto.AffectedResources)
+	if affectedResources == nil {
+		affectedResources = []tkValueObject.SystemResourceIdentifier{}
+	}
+
+	activityRecord = tkEntity.NewActivityRecord(
+		0, createDto.RecordLevel, createDto.RecordCode, affectedResources, recordDetails,
+		createDto.OperatorSri, createDto.OperatorIpAddress, 0,
+	)
+	activityRecord.CorrelationId = createDto.CorrelationId
+
+	return activityRecordInMemoryClone(activityRecord), nil
+}
+
+func (repo *ActivityRecordInMemoryCmdRepo) CreateMany(
+	createDtos []tkDto.CreateActivityRecord,
+) error {
+	activityRecords := make([]tkEntity.ActivityRecord, 0, len(createDtos))
+	for _, createDto := range createDtos {
+		activityRecord, err := repo.createDtoToEntity(createDto)
+		if err != nil {
+			return err
+		}
+		activityRecords = append(activityRecords, activityRecord)
+	}
+
+	repo.queryRepo.mutex.Lock()
+	defer repo.queryRepo.mutex.Unlock()
+
+	createdAt := time.Now()
+	for _, activityRecord := range activityRecords {
+		repo.queryRepo.lastRecordId++
+		activityRecord.RecordId = tkValueObject.ActivityRecordId(repo.queryRepo.lastRecordId)
+		activityRecord.CreatedAt = tkValueObject.NewUnixTimeWithGoTime(createdAt)
+		repo.queryRepo.entries = append(
+			repo.queryRepo.entries,
+			activityRecordInMemoryEntry{activityRecord: activityRecord, createdAt: createdAt},
+		)
+	}
+
+	return nil
+}
+
+func (repo *ActivityRecordInMemoryCmdRepo) Create(createDto tkDto.CreateActivityRecord) error {
+	return repo.CreateMany([]tkDto.CreateActivityRecord{createDto})
+}
+
+// deleteEntries removes the entries the matcher returns true for, returning how many.
+func (repo *ActivityRecordInMemoryCmdRepo) deleteEntries(
+	entryMatcher func(entry activityRecordInMemoryEntry) bool,
+) (deletedRecordsCount uint64) {
+	repo.queryRepo.mutex.Lock()
+	defer repo.queryRepo.mutex.Unlock()
+
+	entriesCount := len(repo.queryRepo.entries)
+	repo.queryRepo.entries = slices.DeleteFunc(repo.queryRepo.entries, entryMatcher)
+	return uint64(entriesCount - len(repo.queryRepo.entries))
+}
+
+func (repo *ActivityRecordInMemoryCmdRepo) Delete(
+	deleteDto tkDto.DeleteActivityRecord,
+) (responseDto tkDto.DeleteActivityRecordResponse, err error) {
+	readRequestDto := tkDto.ReadActivityRecordsRequest{
+		RecordId:          deleteDto.RecordId,
+		RecordLevel:       deleteDto.RecordLevel,
+		RecordCode:        deleteDto.RecordCode,
+		AffectedResources: deleteDto.AffectedResources,
+		OperatorSri:       deleteDto.OperatorSri,
+		OperatorIpAddress: deleteDto.OperatorIpAddress,
+		CreatedBeforeAt:   deleteDto.CreatedBeforeAt,
+		CreatedAfterAt:    deleteDto.CreatedAfterAt,
+		CorrelationId:     deleteDto.CorrelationId,
+		AccountId:         deleteDto.AccountId,
+	}
+
+	responseDto.DeletedRecordsCount = repo.deleteEntries(
+		func(entry activityRecordInMemoryEntry) bool {
+			return activityRecordReadFiltersMatcher(
+				readRequestDto, entry.activityRecord, entry.createdAt,
+			)
+		},
+	)
+	return responseDto, nil
+}
+
+func activityRecordInMemoryRetentionRuleMatches(
+	retentionRule tkDto.ActivityRecordRetentionRule,
+	activityRecord tkEntity.ActivityRecord,
+) bool {
+	if retentionRule.RecordLevel != nil && *retentionRule.RecordLevel != activityRecord.RecordLevel {
+		return false
+	}
+	if retentionRule.RecordCode != nil && *retentionRule.RecordCode != activityRecord.RecordCode {
+		return false
+	}
+	return true
+}
+
+// Purge applies the retention rules as the ActivityRecordCmdRepo Purge does; BatchSize
+// and ShouldVacuum have no effect in memory.
+func (repo *ActivityRecordInMemoryCmdRepo) Purge(
+	purgeDto tkDto.PurgeActivityRecordsRequest,
+) (responseDto tkDto.PurgeActivityRecordsResponse, err error) {
+	isGovernedByRule := func(ruleIndex int, activityRecord tkEntity.ActivityRecord) bool {
+		for _, previousRule := range purgeDto.RetentionRules[:ruleIndex] {
+			if activityRecordInMemoryRetentionRuleMatches(previousRule, activityRecord) {
+				return false
+			}
+		}
+		return activityRecordInMemoryRetentionRuleMatches(
+			purgeDto.RetentionRules[ruleIndex], activityRecord,
+		)
+	}
+
+	responseDto.PurgedRecordsCountPerRule = make([]uint64, len(purgeDto.RetentionRules))
+	for ruleIndex, retentionRule := range purgeDto.RetentionRules {
+		if retentionRule.MaxAge != nil {
+			expiredBeforeAt := time.Now().Add(-*retentionRule.MaxAge)
+			purgedRecordsCount := repo.deleteEntries(func(entry activityRecordInMemoryEntry) bool {
+				return isGovernedByRule(ruleIndex, entry.activityRecord) &&
+					entry.createdAt.Before(expiredBeforeAt)
+			})
+			responseDto.PurgedRecordsCountPerRule[ruleIndex] += purgedRecordsCount
+			responseDto.PurgedRecordsCount += purgedRecordsCount
+		}
+
+		if retentionRule.MaxRecordsCount != nil {
+			repo.queryRepo.mutex.RLock()
+			governedRecordIds := []tkValueObject.ActivityRecordId{}
+			for _, entry := range slices.Backward(repo.queryRepo.entries) {
+				if isGovernedByRule(ruleIndex, entry.activityRecord) {
+					governedRecordIds = append(governedRecordIds, entry.activityRecord.RecordId)
+				}
+			}
+			repo.queryRepo.mutex.RUnlock()
+
+			keptRecordsCount := min(int(*retentionRule.MaxRecordsCount), len(governedRecordIds))
+			expiredRecordIds := map[tkValueObject.ActivityRecordId]struct{}{}
+			for _, expiredRecordId := range governedRecordIds[keptRecordsCount:] {
+				expiredRecordIds[expiredRecordId] = struct{}{}
+			}
+			purgedRecordsCount := repo.deleteEntries(func(entry activityRecordInMemoryEntry) bool {
+				_, isExpired := expiredRecordIds[entry.activityRecord.RecordId]
+				return isExpired
+			})
+			responseDto.PurgedRecordsCountPerRule[ruleIndex] += purgedRecordsCount
+			responseDto.PurgedRecordsCount += purgedRecordsCount
+		}
+	}
+
+	return responseDto, nil
+}
@@ -0,0 +1,566 @@
+package tkInfraActivityRecord
+
+import (
+	"cmp"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+)
+
+// activityRecordInMemoryEntry keeps the full precision creation time alongside the
+// entity, as the database does, since the time filters and buckets rely on it.
+type activityRecordInMemoryEntry struct {
+	activityRecord tkEntity.ActivityRecord
+	createdAt      time.Time
+}
+
+var activityRecordInMemoryPaginationSettings = tkInfraDb.PaginationSliceBuilderSettings[activityRecordInMemoryEntry]{
+	SortableFields: activityRecordPaginationSettings.SortableFields,
+	SortValueReaders: map[string]func(activityRecordInMemoryEntry) any{
+		"id": func(entry activityRecordInMemoryEntry) any {
+			return entry.activityRecord.RecordId.Uint64()
+		},
+		"recordId": func(entry activityRecordInMemoryEntry) any {
+			return entry.activityRecord.RecordId.Uint64()
+		},
+		"recordLevel": func(entry activityRecordInMemoryEntry) any {
+			return entry.activityRecord.RecordLevel.String()
+		},
+		"recordCode": func(entry activityRecordInMemoryEntry) any {
+			return entry.activityRecord.RecordCode.String()
+		},
+		"operatorSri": func(entry activityRecordInMemoryEntry) any {
+			if entry.activityRecord.OperatorSri == nil {
+				return nil
+			}
+			return entry.activityRecord.OperatorSri.String()
+		},
+		"operatorIpAddress": func(entry activityRecordInMemoryEntry) any {
+			if entry.activityRecord.OperatorIpAddress == nil {
+				return nil
+			}
+			return entry.activityRecord.OperatorIpAddress.String()
+		},
+		"createdAt": func(entry activityRecordInMemoryEntry) any {
+			return entry.createdAt
+		},
+	},
+	PrimaryKeyReader: func(entry activityRecordInMemoryEntry) uint64 {
+		return entry.activityRecord.RecordId.Uint64()
+	},
+}
+
+// ActivityRecordInMemoryQueryRepo is a tkRepository.ActivityRecordQueryRepo keeping the
+// records in memory, meant for unit tests and ephemeral tools. The records are written
+// by the ActivityRecordInMemoryCmdRepo built on top of it. Filters, pagination, sorting
+// and aggregations follow the ActivityRecordQueryRepo semantics (on SQLite); the hash
+// chain isn't supported.
+type ActivityRecordInMemoryQueryRepo struct {
+	mutex        sync.RWMutex
+	entries      []activityRecordInMemoryEntry
+	lastRecordId uint64
+}
+
+func NewActivityRecordInMemoryQueryRepo() *ActivityRecordInMemoryQueryRepo {
+	return &ActivityRecordInMemoryQueryRepo{
+		entries: []activityRecordInMemoryEntry{},
+	}
+}
+
+// activityRecordInMemoryClone copies the slices and pointers of the entity, so the
+// stored records can't be changed through the returned ones.
+func activityRecordInMemoryClone(
+	activityRecord tkEntity.ActivityRecord,
+) tkEntity.ActivityRecord {
+	activityRecord.AffectedResources = slices.Clone(activityRecord.AffectedResources)
+	if activityRecord.OperatorSri != nil {
+		operatorSri := *activityRecord.OperatorSri
+		activityRecord.OperatorSri = &operatorSri
+	}
+	if activityRecord.OperatorIpAddress != nil {
+		operatorIpAddress := *activityRecord.OperatorIpAddress
+		activityRecord.OperatorIpAddress = &operatorIpAddress
+	}
+	if activityRecord.CorrelationId != nil {
+		correlationId := *activityRecord.CorrelationId
+		activityRecord.CorrelationId = &correlationId
+	}
+	return activityRecord
+}
+
+// activityRecordInMemoryDetailsValueReader returns the value found at the path of the
+// JSON record details; missing paths, JSON nulls and invalid details are all absent,
+// as the SQL json functions return NULL for them.
+func activityRecordInMemoryDetailsValueReader(
+	recordDetails any,
+	detailsPath tkValueObject.ActivityRecordDetailsPath,
+) (detailsValue any, isFound bool) {
+	recordDetailsStr, assertOk := recordDetails.(string)
+	if !assertOk {
+		return nil, false
+	}
+
+	jsonDecoder := json.NewDecoder(strings.NewReader(recordDetailsStr))
+	jsonDecoder.UseNumber()
+	err := jsonDecoder.Decode(&detailsValue)
+	if err != nil {
+		return nil, false
+	}
+
+	for pathSegment := range strings.SplitSeq(detailsPath.String(), ".") {
+		segmentKey, segmentIndexes, _ := strings.Cut(pathSegment, "[")
+		detailsMap, assertOk := detailsValue.(map[string]any)
+		if !assertOk {
+			return nil, false
+		}
+		detailsValue, isFound = detailsMap[segmentKey]
+		if !isFound {
+			return nil, false
+		}
+
+		if segmentIndexes == "" {
+			continue
+		}
+		for rawIndex := range strings.SplitSeq(strings.TrimSuffix(segmentIndexes, "]"), "][") {
+			arrayIndex, err := strconv.Atoi(rawIndex)
+			if err != nil {
+				return nil, false
+			}
+			detailsSlice, assertOk := detailsValue.([]any)
+			if !assertOk || arrayIndex >= len(detailsSlice) {
+				return nil, false
+			}
+			detailsValue = detailsSlice[arrayIndex]
+		}
+	}
+
+	return detailsValue, detailsValue != nil
+}
+
+// activityRecordInMemoryAsciiLowerCaser mimics the SQLite LIKE case folding, which is
+// limited to the ASCII letters.
+func activityRecordInMemoryAsciiLowerCaser(rawString string) string {
+	return strings.Map(func(stringRune rune) rune {
+		if stringRune >= 'A' && stringRune <= 'Z' {
+			return stringRune + ('a' - 'A')
+		}
+		return stringRune
+	}, rawString)
+}
+
+// activityRecordInMemoryDetailsFilterMatcherFactory validates the filter the same way
+// the query repo recordDetailsFilterCondition does and returns its predicate. Values
+// are compared with the stored values of the same JSON type only.
+func activityRecordInMemoryDetailsFilterMatcherFactory(
+	detailsFilter tkDto.ActivityRecordDetailsFilter,
+) (detailsMatcher func(recordDetails any) bool, err error) {
+	detailsPath, err := tkValueObject.NewActivityRecordDetailsPath(detailsFilter.Path.String())
+	if err != nil {
+		return detailsMatcher, err
+	}
+
+	if detailsFilter.Value == nil {
+		switch detailsFilter.Operator {
+		case tkValueObject.ComparisonOperatorEqual:
+			return func(recordDetails any) bool {
+				_, isFound := activityRecordInMemoryDetailsValueReader(recordDetails, detailsPath)
+				return !isFound
+			}, nil
+		case tkValueObject.ComparisonOperatorNotEqual:
+			return func(recordDetails any) bool {
+				_, isFound := activityRecordInMemoryDetailsValueReader(recordDetails, detailsPath)
+				return isFound
+			}, nil
+		default:
+			return detailsMatcher, errors.New(
+				"RecordDetailsFilterNullValueRequiresEqualityOperator",
+			)
+		}
+	}
+
+	// valueComparer returns the comparison of the stored value with the filter value,
+	// or false when the stored value is of another JSON type.
+	var valueComparer func(detailsValue any) (comparisonResult int, isComparable bool)
+	switch typedFilterValue := detailsFilter.Value.(type) {
+	case string:
+		valueComparer = func(detailsValue any) (int, bool) {
+			detailsStr, assertOk := detailsValue.(string)
+			return strings.Compare(detailsStr, typedFilterValue), assertOk
+		}
+	case float32, float64, json.Number,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+		filterNumber, err := strconv.ParseFloat(fmt.Sprint(typedFilterValue), 64)
+		if err != nil {
+			return detailsMatcher, errors.New("UnsupportedRecordDetailsFilterValue")
+		}
+		valueComparer = func(detailsValue any) (int, bool) {
+			detailsNumber, assertOk := detailsValue.(json.Number)
+			if !assertOk {
+				return 0, false
+			}
+			detailsFloat, err := detailsNumber.Float64()
+			return cmp.Compare(detailsFloat, filterNumber), err == nil
+		}
+	case bool:
+		valueComparer = func(detailsValue any) (int, bool) {
+			detailsBool, assertOk := detailsValue.(bool)
+			switch {
+			case !assertOk:
+				return 0, false
+			case detailsBool == typedFilterValue:
+				return 0, true
+			case typedFilterValue:
+				return -1, true
+			default:
+				return 1, true
+			}
+		}
+	default:
+		return detailsMatcher, errors.New("UnsupportedRecordDetailsFilterValue")
+	}
+
+	var comparisonMatcher func(comparisonResult int) bool
+	switch detailsFilter.Operator {
+	case tkValueObject.ComparisonOperatorEqual:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult == 0 }
+	case tkValueObject.ComparisonOperatorNotEqual:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult != 0 }
+	case tkValueObject.ComparisonOperatorGreaterThan:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult > 0 }
+	case tkValueObject.ComparisonOperatorGreaterThanOrEqual:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult >= 0 }
+	case tkValueObject.ComparisonOperatorLessThan:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult < 0 }
+	case tkValueObject.ComparisonOperatorLessThanOrEqual:
+		comparisonMatcher = func(comparisonResult int) bool { return comparisonResult <= 0 }
+	case tkValueObject.ComparisonOperatorContains:
+		filterValueStr, assertOk := detailsFilter.Value.(string)
+		if !assertOk {
+			return detailsMatcher, errors.New("RecordDetailsFilterContainsRequiresStringValue")
+		}
+		filterValueStr = activityRecordInMemoryAsciiLowerCaser(filterValueStr)
+		return func(recordDetails any) bool {
+			detailsValue, _ := activityRecordInMemoryDetailsValueReader(recordDetails, detailsPath)
+			detailsStr, assertOk := detailsValue.(string)
+			return assertOk && strings.Contains(
+				activityRecordInMemoryAsciiLowerCaser(detailsStr), filterValueStr,
+			)
+		}, nil
+	default:
+		return detailsMatcher, errors.New("InvalidRecordDetailsFilterOperator")
+	}
+
+	return func(recordDetails any) bool {
+		detailsValue, isFound := activityRecordInMemoryDetailsValueReader(recordDetails, detailsPath)
+		if !isFound {
+			return false
+		}
+		comparisonResult, isComparable := valueComparer(detailsValue)
+		return isComparable && comparisonMatcher(comparisonResult)
+	}, nil
+}
+
+// filteredEntriesReader returns copies of the entries matching every filter of the
+// read request, by ascending ID, ignoring its pagination.
+func (repo *ActivityRecordInMemoryQueryRepo) filteredEntriesReader(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (filteredEntries []activityRecordInMemoryEntry, err error) {
+	detailsMatchers := []func(recordDetails any) bool{}
+	for _, detailsFilter := range requestDto.RecordDetailsFilters {
+		detailsMatcher, err := activityRecordInMemoryDetailsFilterMatcherFactory(detailsFilter)
+		if err != nil {
+			return filteredEntries, errors.New("InvalidRecordDetailsFilter: " + err.Error())
+		}
+		detailsMatchers = append(detailsMatchers, detailsMatcher)
+	}
+
+	repo.mutex.RLock()
+	defer repo.mutex.RUnlock()
+
+	filteredEntries = []activityRecordInMemoryEntry{}
+	for _, entry := range repo.entries {
+		if !activityRecordReadFiltersMatcher(requestDto, entry.activityRecord, entry.createdAt) {
+			continue
+		}
+
+		isDetailsMatched := true
+		for _, detailsMatcher := range detailsMatchers {
+			if !detailsMatcher(entry.activityRecord.RecordDetails) {
+				isDetailsMatched = false
+				break
+			}
+		}
+		if !isDetailsMatched {
+			continue
+		}
+
+		entry.activityRecord = activityRecordInMemoryClone(entry.activityRecord)
+		filteredEntries = append(filteredEntries, entry)
+	}
+
+	return filteredEntries, nil
+}
+
+func (repo *ActivityRecordInMemoryQueryRepo) Read(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (responseDto tkDto.ReadActivityRecordsResponse, err error) {
+	filteredEntries, err := repo.filteredEntriesReader(requestDto)
+	if err != nil {
+		return responseDto, err
+	}
+
+	pageEntries, responsePagination, err := tkInfraDb.PaginationSliceBuilder(
+		filteredEntries, requestDto.Pagination, activityRecordInMemoryPaginationSettings,
+	)
+	if err != nil {
+		return responseDto, fmt.Errorf("PaginationQueryBuilderError: %w", err)
+	}
+
+	for _, pageEntry := range pageEntries {
+		responseDto.ActivityRecords = append(responseDto.ActivityRecords, pageEntry.activityRecord)
+	}
+	responseDto.Pagination = responsePagination
+
+	return responseDto, nil
+}
+
+func (repo *ActivityRecordInMemoryQueryRepo) ReadFirst(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (activityRecord tkEntity.ActivityRecord, err error) {
+	requestDto.Pagination = tkDto.PaginationSingleItem
+	responseDto, err := repo.Read(requestDto)
+	if err != nil {
+		return activityRecord, err
+	}
+
+	if len(responseDto.ActivityRecords) == 0 {
+		return activityRecord, errors.New(tkUseCase.ErrActivityRecordNotFound)
+	}
+
+	return responseDto.ActivityRecords[0], nil
+}
+
+// VerifyHashChain always fails, since the in-memory records aren't chained.
+func (repo *ActivityRecordInMemoryQueryRepo) VerifyHashChain() (
+	responseDto tkDto.VerifyActivityRecordsHashChainResponse, err error,
+) {
+	return responseDto, errors.New("ActivityRecordHashChainNotEnabled")
+}
+
+func activityRecordInMemoryTimeBucketStartAt(
+	granularity tkValueObject.TimeBucketGranularity,
+	createdAt time.Time,
+) (timeBucketStartAt int64, err error) {
+	createdAtUnix := createdAt.Unix()
+	switch granularity {
+	case tkValueObject.TimeBucketGranularityHour:
+		return createdAtUnix / 3600 * 3600, nil
+	case tkValueObject.TimeBucketGranularityDay:
+		return createdAtUnix / 86400 * 86400, nil
+	case tkValueObject.TimeBucketGranularityWeek:
+		return (createdAtUnix-345600)/604800*604800 + 345600, nil
+	case tkValueObject.TimeBucketGranularityMonth:
+		createdAtUtc := createdAt.UTC()
+		return time.Date(
+			createdAtUtc.Year(), createdAtUtc.Month(), 1, 0, 0, 0, 0, time.UTC,
+		).Unix(), nil
+	default:
+		return timeBucketStartAt, errors.New("InvalidTimeBucketGranularity")
+	}
+}
+
+// activityRecordInMemoryNullableCompare places the nils first, as SQLite does on
+// ascending sorts.
+func activityRecordInMemoryNullableCompare[ValueType cmp.Ordered](
+	valueA, valueB *ValueType,
+) int {
+	switch {
+	case valueA == nil && valueB == nil:
+		return 0
+	case valueA == nil:
+		return -1
+	case valueB == nil:
+		return 1
+	default:
+		return cmp.Compare(*valueA, *valueB)
+	}
+}
+
+// Aggregate counts the records matching the read filters, grouping and sorting them
+// as the query repo Aggregate does.
+func (repo *ActivityRecordInMemoryQueryRepo) Aggregate(
+	aggregateDto tkDto.AggregateActivityRecordsRequest,
+) (responseDto tkDto.AggregateActivityRecordsResponse, err error) {
+	filteredEntries, err := repo.filteredEntriesReader(aggregateDto.ReadRequest)
+	if err != nil {
+		return responseDto, err
+	}
+
+	for _, aggregationDimension := range aggregateDto.GroupBy {
+		switch aggregationDimension {
+		case tkValueObject.ActivityRecordAggregationDimensionRecordCode,
+			tkValueObject.ActivityRecordAggregationDimensionRecordLevel,
+			tkValueObject.ActivityRecordAggregationDimensionOperatorSri,
+			tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType:
+		default:
+			return responseDto, errors.New("InvalidActivityRecordAggregationDimension")
+		}
+	}
+	// Validated upfront, so requests fail the same way with or without matching records.
+	if aggregateDto.TimeBucketGranularity != nil {
+		_, err = activityRecordInMemoryTimeBucketStartAt(
+			*aggregateDto.TimeBucketGranularity, time.Now(),
+		)
+		if err != nil {
+			return responseDto, err
+		}
+	}
+	if len(aggregateDto.GroupBy) == 0 && aggregateDto.TimeBucketGranularity == nil {
+		return responseDto, errors.New("AggregationRequiresGroupByOrTimeBucket")
+	}
+
+	// The rows are keyed by their JSON, since their pointers aren't comparable.
+	aggregationRows := map[string]*activityRecordAggregationRow{}
+	aggregationRowsRecordIds := map[string]map[uint64]struct{}{}
+	for _, entry := range filteredEntries {
+		entryRows := []activityRecordAggregationRow{{}}
+		for _, aggregationDimension := range aggregateDto.GroupBy {
+			switch aggregationDimension {
+			case tkValueObject.ActivityRecordAggregationDimensionRecordCode:
+				recordCodeStr := entry.activityRecord.RecordCode.String()
+				for rowIndex := range entryRows {
+					entryRows[rowIndex].RecordCode = &recordCodeStr
+				}
+			case tkValueObject.ActivityRecordAggregationDimensionRecordLevel:
+				recordLevelStr := entry.activityRecord.RecordLevel.String()
+				for rowIndex := range entryRows {
+					entryRows[rowIndex].RecordLevel = &recordLevelStr
+				}
+			case tkValueObject.ActivityRecordAggregationDimensionOperatorSri:
+				var operatorSriPtr *string
+				if entry.activityRecord.OperatorSri != nil {
+					operatorSriStr := entry.activityRecord.OperatorSri.String()
+					operatorSriPtr = &operatorSriStr
+				}
+				for rowIndex := range entryRows {
+					entryRows[rowIndex].OperatorSri = operatorSriPtr
+				}
+			case tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType:
+				// One row per affected resource, as the LEFT JOIN produces.
+				if len(entry.activityRecord.AffectedResources) == 0 {
+					for rowIndex := range entryRows {
+						entryRows[rowIndex].AffectedResourceType = nil
+					}
+					continue
+				}
+				joinedRows := []activityRecordAggregationRow{}
+				for _, affectedResource := range entry.activityRecord.AffectedResources {
+					resourceType, err := affectedResource.ReadResourceType()
+					if err != nil {
+						continue
+					}
+					resourceTypeStr := resourceType.String()
+					for _, entryRow := range entryRows {
+						entryRow.AffectedResourceType = &resourceTypeStr
+						joinedRows = append(joinedRows, entryRow)
+					}
+				}
+				entryRows = joinedRows
+			}
+		}
+
+		if aggregateDto.TimeBucketGranularity != nil {
+			timeBucketStartAt, err := activityRecordInMemoryTimeBucketStartAt(
+				*aggregateDto.TimeBucketGranularity, entry.createdAt,
+			)
+			if err != nil {
+				return responseDto, err
+			}
+			for rowIndex := range entryRows {
+				entryRows[rowIndex].TimeBucketStartAt = &timeBucketStartAt
+			}
+		}
+
+		for _, entryRow := range entryRows {
+			rowKeyBytes, err := json.Marshal(entryRow)
+			if err != nil {
+				return responseDto, err
+			}
+			rowKey := string(rowKeyBytes)
+			if aggregationRows[rowKey] == nil {
+				aggregationRows[rowKey] = &entryRow
+				aggregationRowsRecordIds[rowKey] = map[uint64]struct{}{}
+			}
+			aggregationRowsRecordIds[rowKey][entry.activityRecord.RecordId.Uint64()] = struct{}{}
+		}
+	}
+
+	sortedRows := []activityRecordAggregationRow{}
+	for rowKey, aggregationRow := range aggregationRows {
+		aggregationRow.RecordsCount = uint64(len(aggregationRowsRecordIds[rowKey]))
+		sortedRows = append(sortedRows, *aggregationRow)
+	}
+	slices.SortFunc(sortedRows, func(rowA, rowB activityRecordAggregationRow) int {
+		comparisonResult := activityRecordInMemoryNullableCompare(
+			rowA.TimeBucketStartAt, rowB.TimeBucketStartAt,
+		)
+		if comparisonResult != 0 {
+			return comparisonResult
+		}
+		comparisonResult = cmp.Compare(rowB.RecordsCount, rowA.RecordsCount)
+		if comparisonResult != 0 {
+			return comparisonResult
+		}
+		for _, aggregationDimension := range aggregateDto.GroupBy {
+			switch aggregationDimension {
+			case tkValueObject.ActivityRecordAggregationDimensionRecordCode:
+				comparisonResult = activityRecordInMemoryNullableCompare(rowA.RecordCode, rowB.RecordCode)
+			case tkValueObject.ActivityRecordAggregationDimensionRecordLevel:
+				comparisonResult = activityRecordInMemoryNullableCompare(rowA.RecordLevel, rowB.RecordLevel)
+			case tkValueObject.ActivityRecordAggregationDimensionOperatorSri:
+				comparisonResult = activityRecordInMemoryNullableCompare(rowA.OperatorSri, rowB.OperatorSri)
+			case tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType:
+				comparisonResult = activityRecordInMemoryNullableCompare(
+					rowA.AffectedResourceType, rowB.AffectedResourceType,
+				)
+			}
+			if comparisonResult != 0 {
+				return comparisonResult
+			}
+		}
+		return 0
+	})
+
+	maxGroupsCount := activityRecordAggregationMaxGroupsCountDefault
+	if aggregateDto.MaxGroupsCount != 0 {
+		maxGroupsCount = aggregateDto.MaxGroupsCount
+	}
+	if len(sortedRows) > int(maxGroupsCount) {
+		responseDto.IsTruncated = true
+		sortedRows = sortedRows[:maxGroupsCount]
+	}
+
+	responseDto.Groups = []tkDto.ActivityRecordsAggregationGroup{}
+	for _, aggregationRow := range sortedRows {
+		aggregationGroup, err := activityRecordAggregationRowToGroup(aggregationRow)
+		if err != nil {
+			slog.Error("ActivityRecordAggregationRowError", slog.String("err", err.Error()))
+			continue
+		}
+		responseDto.Groups = append(responseDto.Groups, aggregationGroup)
+	}
+
+	return responseDto, nil
+}
@@ -1,29 +1,212 @@
 package tkInfraActivityRecord
 
 import (
+	"encoding/json"
 	"errors"
+	"fmt"
 	"log/slog"
+	"maps"
+	"strings"
 
 	tkDto "github.com/goinfinite/tk/src/domain/dto"
 	tkEntity "github.com/goinfinite/tk/src/domain/entity"
 	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
 	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
 )
 
+const activityRecordHashChainVerifyBatchSize int = 500
+
+// activityRecordPaginationSettings restricts the Read sorting to the entity fields
+// backed by indexable columns; RecordDetails and AffectedResources aren't sortable.
+var activityRecordPaginationSettings = tkInfraDb.PaginationQueryBuilderSettings{
+	PrimaryKeyColumn: "activity_records.id",
+	SortableFields: tkInfraDb.PaginationSortableFields{
+		"id":                {ColumnExpression: "activity_records.id"},
+		"recordId":          {ColumnExpression: "activity_records.id"},
+		"recordLevel":       {ColumnExpression: "activity_records.record_level"},
+		"recordCode":        {ColumnExpression: "activity_records.record_code"},
+		"operatorSri":       {ColumnExpression: "activity_records.operator_sri"},
+		"operatorIpAddress": {ColumnExpression: "activity_records.operator_ip_address"},
+		"createdAt":         {ColumnExpression: "activity_records.created_at"},
+	},
+}
+
 type ActivityRecordQueryRepo struct {
-	trailDbSvc *tkInfraDb.TrailDatabaseService
+	trailDbSvc         *tkInfraDb.TrailDatabaseService
+	hashChainer        *activityRecordHashChainer
+	fieldsEncryptor    *activityRecordFieldsEncryptor
+	paginationSettings tkInfraDb.PaginationQueryBuilderSettings
 }
 
 func NewActivityRecordQueryRepo(
 	trailDbSvc *tkInfraDb.TrailDatabaseService,
 ) *ActivityRecordQueryRepo {
-	return &ActivityRecordQueryRepo{trailDbSvc: trailDbSvc}
+	queryRepo, _ := NewActivityRecordQueryRepoWithSettings(
+		trailDbSvc, ActivityRecordRepoSettings{},
+	)
+	return queryRepo
 }
 
-func (repo *ActivityRecordQueryRepo) Read(
+func NewActivityRecordQueryRepoWithSettings(
+	trailDbSvc *tkInfraDb.TrailDatabaseService,
+	settings ActivityRecordRepoSettings,
+) (*ActivityRecordQueryRepo, error) {
+	hashChainer, err := newActivityRecordHashChainer(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	fieldsEncryptor, err := newActivityRecordFieldsEncryptor(settings)
+	if err != nil {
+		return nil, err
+	}
+
+	// The encrypted values order is meaningless, so they aren't sortable.
+	paginationSettings := activityRecordPaginationSettings
+	if settings.ShouldEncryptOperatorIpAddress {
+		paginationSettings.SortableFields = maps.Clone(paginationSettings.SortableFields)
+		delete(paginationSettings.SortableFields, "operatorIpAddress")
+	}
+
+	return &ActivityRecordQueryRepo{
+		trailDbSvc:         trailDbSvc,
+		hashChainer:        hashChainer,
+		fieldsEncryptor:    fieldsEncryptor,
+		paginationSettings: paginationSettings,
+	}, nil
+}
+
+const activityRecordAggregationMaxGroupsCountDefault uint16 = 1000
+
+// affectedResourcesSubQueryBuilder selects the IDs of the records affecting any of the
+// resources matched by the SRIs, as SystemResourceIdentifier.Matches defines it: the
+// wildcard and account SRIs rely on the indexed account_id and resource_type columns.
+func (repo *ActivityRecordQueryRepo) affectedResourcesSubQueryBuilder(
+	sris []tkValueObject.SystemResourceIdentifier,
+) *gorm.DB {
+	sriStrs := make([]string, 0, len(sris))
+	for _, sri := range sris {
+		sriStrs = append(sriStrs, sri.String())
+	}
+
+	conditionParts := []string{"system_resource_identifier IN ?"}
+	conditionArgs := []any{sriStrs}
+	for _, sri := range sris {
+		ownedAccountId, err := sri.ReadOwnedAccountId()
+		if err == nil {
+			conditionParts = append(conditionParts, "account_id = ?")
+			conditionArgs = append(conditionArgs, ownedAccountId.Uint64())
+		}
+
+		if !sri.IsWildcard() {
+			continue
+		}
+		accountId, err := sri.ReadAccountId()
+		if err != nil {
+			continue
+		}
+		resourceType, err := sri.ReadResourceType()
+		if err != nil {
+			continue
+		}
+		conditionParts = append(conditionParts, "(account_id = ? AND resource_type = ?)")
+		conditionArgs = append(conditionArgs, accountId.Uint64(), resourceType.String())
+	}
+
+	return repo.trailDbSvc.Handler.
+		Model(&tkInfraDbModel.ActivityRecordAffectedResource{}).
+		Select("DISTINCT activity_record_id").
+		Where(strings.Join(conditionParts, " OR "), conditionArgs...)
+}
+
+// recordDetailsFilterCondition translates the filter into a SQL condition. Both the
+// JSON path and the value are bound as parameters, never concatenated. Records whose
+// details aren't valid JSON simply don't match instead of failing the whole query.
+func (repo *ActivityRecordQueryRepo) recordDetailsFilterCondition(
+	detailsFilter tkDto.ActivityRecordDetailsFilter,
+) (conditionStr string, conditionArgs []any, err error) {
+	detailsPath, err := tkValueObject.NewActivityRecordDetailsPath(detailsFilter.Path.String())
+	if err != nil {
+		return conditionStr, conditionArgs, err
+	}
+	sqlDialect := tkInfraDb.NewSqlDialect(repo.trailDbSvc.Handler)
+
+	if detailsFilter.Value == nil {
+		extractExpression, extractArgs := sqlDialect.JsonPathExtractExpression(
+			"record_details", detailsPath.ReadAsJsonPath(), tkInfraDb.SqlJsonValueKindAny,
+		)
+		switch detailsFilter.Operator {
+		case tkValueObject.ComparisonOperatorEqual:
+			return extractExpression + " IS NULL", extractArgs, nil
+		case tkValueObject.ComparisonOperatorNotEqual:
+			return extractExpression + " IS NOT NULL", extractArgs, nil
+		default:
+			return conditionStr, conditionArgs, errors.New(
+				"RecordDetailsFilterNullValueRequiresEqualityOperator",
+			)
+		}
+	}
+
+	filterValue := detailsFilter.Value
+	valueKind := tkInfraDb.SqlJsonValueKindNumber
+	switch typedValue := filterValue.(type) {
+	case string:
+		valueKind = tkInfraDb.SqlJsonValueKindText
+	case float32, float64, json.Number,
+		int, int8, int16, int32, int64, uint, uint8, uint16, uint32, uint64:
+	case bool:
+		valueKind = tkInfraDb.SqlJsonValueKindBoolean
+		filterValue = sqlDialect.JsonBooleanBindValue(typedValue)
+	default:
+		return conditionStr, conditionArgs, errors.New("UnsupportedRecordDetailsFilterValue")
+	}
+
+	extractExpression, extractArgs := sqlDialect.JsonPathExtractExpression(
+		"record_details", detailsPath.ReadAsJsonPath(), valueKind,
+	)
+	conditionArgs = append(conditionArgs, extractArgs...)
+
+	sqlOperator := ""
+	switch detailsFilter.Operator {
+	case tkValueObject.ComparisonOperatorEqual:
+		sqlOperator = "="
+	case tkValueObject.ComparisonOperatorNotEqual:
+		sqlOperator = "<>"
+	case tkValueObject.ComparisonOperatorGreaterThan:
+		sqlOperator = ">"
+	case tkValueObject.ComparisonOperatorGreaterThanOrEqual:
+		sqlOperator = ">="
+	case tkValueObject.ComparisonOperatorLessThan:
+		sqlOperator = "<"
+	case tkValueObject.ComparisonOperatorLessThanOrEqual:
+		sqlOperator = "<="
+	case tkValueObject.ComparisonOperatorContains:
+		valueStr, assertOk := filterValue.(string)
+		if !assertOk {
+			return conditionStr, conditionArgs, errors.New(
+				"RecordDetailsFilterContainsRequiresStringValue",
+			)
+		}
+		// "!" is the escape character since backslashes are special in MySQL literals.
+		likeEscaper := strings.NewReplacer("!", "!!", "%", "!%", "_", "!_")
+		conditionArgs = append(conditionArgs, "%"+likeEscaper.Replace(valueStr)+"%")
+		return extractExpression + " LIKE ? ESCAPE '!'", conditionArgs, nil
+	default:
+		return conditionStr, conditionArgs, errors.New("InvalidRecordDetailsFilterOperator")
+	}
+
+	conditionArgs = append(conditionArgs, filterValue)
+	return extractExpression + " " + sqlOperator + " ?", conditionArgs, nil
+}
+
+// filteredQueryBuilder returns the activity records query narrowed by every filter of
+// the read request, ignoring its pagination.
+func (repo *ActivityRecordQueryRepo) filteredQueryBuilder(
 	requestDto tkDto.ReadActivityRecordsRequest,
-) (responseDto tkDto.ReadActivityRecordsResponse, err error) {
+) (dbQuery *gorm.DB, err error) {
 	recordModel := tkInfraDbModel.ActivityRecord{}
 	if requestDto.RecordId != nil {
 		recordId := requestDto.RecordId.Uint64()
@@ -46,38 +229,82 @@ func (repo *ActivityRecordQueryRepo) Read(
 	}
 
 	if requestDto.OperatorIpAddress != nil {
+		if repo.fieldsEncryptor != nil && repo.fieldsEncryptor.shouldEncryptOperatorIpAddress {
+			return dbQuery, errors.New(errOperatorIpAddressFilterEncrypted)
+		}
 		operatorIpAddressStr := requestDto.OperatorIpAddress.String()
 		recordModel.OperatorIpAddress = &operatorIpAddressStr
 	}
 
-	dbQuery := repo.trailDbSvc.Handler.Model(&recordModel).Where(&recordModel)
+	if requestDto.CorrelationId != nil {
+		correlationIdStr := requestDto.CorrelationId.String()
+		recordModel.CorrelationId = &correlationIdStr
+	}
+
+	dbQuery = repo.trailDbSvc.Handler.Model(&recordModel).Where(&recordModel)
 
 	if len(requestDto.AffectedResources) > 0 {
-		systemResourceIdentifiersStrSlice := []string{}
-		for _, sri := range requestDto.AffectedResources {
-			systemResourceIdentifiersStrSlice = append(systemResourceIdentifiersStrSlice, sri.String())
-		}
+		dbQuery = dbQuery.Where(
+			"activity_records.id IN (?)",
+			repo.affectedResourcesSubQueryBuilder(requestDto.AffectedResources),
+		)
+	}
 
-		affectedResourcesSubQuery := repo.trailDbSvc.Handler.
-			Model(&tkInfraDbModel.ActivityRecordAffectedResource{}).
-			Select("DISTINCT activity_record_id").
-			Where("system_resource_identifier IN ?", systemResourceIdentifiersStrSlice)
+	if requestDto.AccountId != nil {
+		accountSriPattern := "sri://" + requestDto.AccountId.String() + ":%"
+		accountSri := tkValueObject.NewSriAccount(*requestDto.AccountId)
 
-		dbQuery = dbQuery.Where("activity_records.id IN (?)", affectedResourcesSubQuery)
+		dbQuery = dbQuery.Where(
+			"(activity_records.operator_sri LIKE ? OR activity_records.operator_sri = ? OR activity_records.id IN (?))",
+			accountSriPattern, accountSri.String(),
+			repo.affectedResourcesSubQueryBuilder(
+				[]tkValueObject.SystemResourceIdentifier{accountSri},
+			),
+		)
 	}
 
 	if requestDto.CreatedBeforeAt != nil {
-		dbQuery = dbQuery.Where("created_at < ?", requestDto.CreatedBeforeAt.ReadAsGoTime())
+		dbQuery = dbQuery.Where(
+			"activity_records.created_at < ?", requestDto.CreatedBeforeAt.ReadAsGoTime(),
+		)
 	}
 	if requestDto.CreatedAfterAt != nil {
-		dbQuery = dbQuery.Where("created_at > ?", requestDto.CreatedAfterAt.ReadAsGoTime())
+		dbQuery = dbQuery.Where(
+			"activity_records.created_at > ?", requestDto.CreatedAfterAt.ReadAsGoTime(),
+		)
 	}
 
-	paginatedDbQuery, responsePagination, err := tkInfraDb.PaginationQueryBuilder(
-		dbQuery, requestDto.Pagination, "",
+	isRecordDetailsEncrypted := repo.fieldsEncryptor != nil &&
+		repo.fieldsEncryptor.shouldEncryptRecordDetails
+	if len(requestDto.RecordDetailsFilters) > 0 && isRecordDetailsEncrypted {
+		return dbQuery, errors.New(errRecordDetailsFiltersEncrypted)
+	}
+
+	for _, detailsFilter := range requestDto.RecordDetailsFilters {
+		conditionStr, conditionArgs, err := repo.recordDetailsFilterCondition(detailsFilter)
+		if err != nil {
+			return dbQuery, errors.New("InvalidRecordDetailsFilter: " + err.Error())
+		}
+		dbQuery = dbQuery.Where(conditionStr, conditionArgs...)
+	}
+
+	return dbQuery, nil
+}
+
+func (repo *ActivityRecordQueryRepo) Read(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (responseDto tkDto.ReadActivityRecordsResponse, err error) {
+	dbQuery, err := repo.filteredQueryBuilder(requestDto)
+	if err != nil {
+		return responseDto, err
+	}
+
+	paginatedDbQuery, responsePagination, err := tkInfraDb.PaginationQueryBuilderWithSettings(
+		dbQuery, requestDto.Pagination, repo.paginationSettings,
 	)
 	if err != nil {
-		return responseDto, errors.New("PaginationQueryBuilderError: " + err.Error())
+		// Wrapped so callers can still tell a *PaginationSortByNotAllowedError apart.
+		return responseDto, fmt.Errorf("PaginationQueryBuilderError: %w", err)
 	}
 
 	recordModels := []tkInfraDbModel.ActivityRecord{}
@@ -86,7 +313,22 @@ func (repo *ActivityRecordQueryRepo) Read(
 		return responseDto, err
 	}
 
+	responsePagination.NextCursor, err = tkInfraDb.PaginationNextCursorBuilder(
+		paginatedDbQuery, responsePagination, repo.paginationSettings, recordModels,
+	)
+	if err != nil {
+		return responseDto, errors.New("PaginationNextCursorBuilderError: " + err.Error())
+	}
+
 	for _, recordModel := range recordModels {
+		// Unlike invalid records, undecryptable ones mean a missing key, so they fail.
+		if repo.fieldsEncryptor != nil {
+			recordModel, err = repo.fieldsEncryptor.decryptModel(recordModel)
+			if err != nil {
+				return responseDto, err
+			}
+		}
+
 		activityRecordEntity, err := recordModel.ToEntity()
 		if err != nil {
 			slog.Debug(
@@ -120,3 +362,301 @@ func (repo *ActivityRecordQueryRepo) ReadFirst(
 
 	return responseDto.ActivityRecords[0], nil
 }
+
+// VerifyHashChain walks every record by ascending ID, recomputing its content hash and
+// checking that it links to the previous chained record (or to a valid checkpoint when
+// the previous record was deleted), then checks that the signed chain head points to
+// the last chained record, so a deleted tail is noticed. Records created before the
+// hash chain was enabled are skipped.
+//
+// @attention Without the secret key nobody can forge the hashes, but whoever can write
+// to the database may still restore an older copy of the chain head along with the
+// records it vouches for; anchor the chain head externally to also notice that.
+func (repo *ActivityRecordQueryRepo) VerifyHashChain() (
+	responseDto tkDto.VerifyActivityRecordsHashChainResponse, err error,
+) {
+	if repo.hashChainer == nil {
+		return responseDto, errors.New("ActivityRecordHashChainNotEnabled")
+	}
+
+	brokenLinkFactory := func(
+		recordId uint64, brokenLinkReason string,
+	) tkDto.VerifyActivityRecordsHashChainResponse {
+		recordIdVo, _ := tkValueObject.NewActivityRecordId(recordId)
+		responseDto.FirstBrokenRecordId = &recordIdVo
+		responseDto.BrokenLinkReason = &brokenLinkReason
+		return responseDto
+	}
+
+	isChainStarted := false
+	expectedPreviousHash := ""
+	lastChainedRecordId := uint64(0)
+	lastSeenRecordId := uint64(0)
+	for {
+		recordModels := []tkInfraDbModel.ActivityRecord{}
+		err = repo.trailDbSvc.Handler.
+			Where("id > ?", lastSeenRecordId).
+			Order("id ASC").
+			Limit(activityRecordHashChainVerifyBatchSize).
+			Preload("AffectedResources").
+			Find(&recordModels).Error
+		if err != nil {
+			return responseDto, err
+		}
+		if len(recordModels) == 0 {
+			break
+		}
+
+		for _, recordModel := range recordModels {
+			lastSeenRecordId = recordModel.ID
+			if recordModel.ContentHash == nil || recordModel.PreviousHash == nil {
+				if !isChainStarted {
+					continue
+				}
+				return brokenLinkFactory(recordModel.ID, "RecordNotChained"), nil
+			}
+			isChainStarted = true
+
+			recomputedHash := recordModel.ComputeContentHash(
+				repo.hashChainer.secretKeyBytes, *recordModel.PreviousHash,
+			)
+			if recomputedHash != *recordModel.ContentHash {
+				return brokenLinkFactory(recordModel.ID, "ContentHashMismatch"), nil
+			}
+
+			if *recordModel.PreviousHash != expectedPreviousHash {
+				checkpointModel := tkInfraDbModel.ActivityRecordCheckpoint{}
+				err = repo.trailDbSvc.Handler.
+					Where("activity_record_id = ?", recordModel.ID).
+					Limit(1).Find(&checkpointModel).Error
+				if err != nil {
+					return responseDto, err
+				}
+
+				isCheckpointValid := checkpointModel.ID != 0 &&
+					checkpointModel.PreviousHash == *recordModel.PreviousHash &&
+					checkpointModel.AnchorHash == expectedPreviousHash &&
+					repo.hashChainer.isCheckpointValid(checkpointModel)
+				if !isCheckpointValid {
+					return brokenLinkFactory(recordModel.ID, "PreviousHashMismatch"), nil
+				}
+				responseDto.CheckpointsCount++
+			}
+
+			expectedPreviousHash = *recordModel.ContentHash
+			lastChainedRecordId = recordModel.ID
+			responseDto.VerifiedRecordsCount++
+		}
+	}
+
+	chainHeadModel := tkInfraDbModel.ActivityRecordChainHead{}
+	err = repo.trailDbSvc.Handler.
+		Where("id = ?", activityRecordChainHeadId).
+		Limit(1).Find(&chainHeadModel).Error
+	if err != nil {
+		return responseDto, err
+	}
+	if chainHeadModel.ID == 0 {
+		if isChainStarted {
+			return brokenLinkFactory(lastChainedRecordId, "ChainHeadNotFound"), nil
+		}
+		responseDto.IsIntact = true
+		return responseDto, nil
+	}
+
+	isChainHeadMatching := repo.hashChainer.isChainHeadValid(chainHeadModel) &&
+		chainHeadModel.LastRecordId == lastChainedRecordId &&
+		chainHeadModel.LastHash == expectedPreviousHash
+	if !isChainHeadMatching && chainHeadModel.LastRecordId > lastChainedRecordId {
+		// Records chained while walking move the chain head past the last one seen.
+		appendedRecordsCount := int64(0)
+		err = repo.trailDbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).
+			Where(
+				"id = ? AND content_hash = ?",
+				chainHeadModel.LastRecordId, chainHeadModel.LastHash,
+			).
+			Count(&appendedRecordsCount).Error
+		if err != nil {
+			return responseDto, err
+		}
+		isChainHeadMatching = appendedRecordsCount > 0 &&
+			repo.hashChainer.isChainHeadValid(chainHeadModel)
+	}
+	if !isChainHeadMatching {
+		return brokenLinkFactory(
+			max(chainHeadModel.LastRecordId, lastChainedRecordId), "ChainHeadMismatch",
+		), nil
+	}
+
+	responseDto.IsIntact = true
+	return responseDto, nil
+}
+
+// activityRecordAggregationRow is the scan target of the aggregation query; columns
+// of the dimensions not requested stay nil.
+type activityRecordAggregationRow struct {
+	RecordCode           *string
+	RecordLevel          *string
+	OperatorSri          *string
+	AffectedResourceType *string
+	TimeBucketStartAt    *int64
+	RecordsCount         uint64
+}
+
+func (repo *ActivityRecordQueryRepo) timeBucketExpression(
+	granularity tkValueObject.TimeBucketGranularity,
+) (bucketExpression string, err error) {
+	sqlDialect := tkInfraDb.NewSqlDialect(repo.trailDbSvc.Handler)
+	createdAtColumn := "activity_records.created_at"
+	switch granularity {
+	case tkValueObject.TimeBucketGranularityHour:
+		return sqlDialect.UnixTimeBucketExpression(createdAtColumn, 3600, 0), nil
+	case tkValueObject.TimeBucketGranularityDay:
+		return sqlDialect.UnixTimeBucketExpression(createdAtColumn, 86400, 0), nil
+	case tkValueObject.TimeBucketGranularityWeek:
+		// The Unix epoch was a Thursday, so weeks are shifted by four days to start on
+		// Monday.
+		return sqlDialect.UnixTimeBucketExpression(createdAtColumn, 604800, 345600), nil
+	case tkValueObject.TimeBucketGranularityMonth:
+		return sqlDialect.UnixMonthBucketExpression(createdAtColumn), nil
+	default:
+		return bucketExpression, errors.New("InvalidTimeBucketGranularity")
+	}
+}
+
+func activityRecordAggregationRowToGroup(
+	aggregationRow activityRecordAggregationRow,
+) (aggregationGroup tkDto.ActivityRecordsAggregationGroup, err error) {
+	aggregationGroup.RecordsCount = aggregationRow.RecordsCount
+
+	if aggregationRow.RecordCode != nil {
+		recordCode, err := tkValueObject.NewActivityRecordCode(*aggregationRow.RecordCode)
+		if err != nil {
+			return aggregationGroup, err
+		}
+		aggregationGroup.RecordCode = &recordCode
+	}
+
+	if aggregationRow.RecordLevel != nil {
+		recordLevel, err := tkValueObject.NewActivityRecordLevel(*aggregationRow.RecordLevel)
+		if err != nil {
+			return aggregationGroup, err
+		}
+		aggregationGroup.RecordLevel = &recordLevel
+	}
+
+	if aggregationRow.OperatorSri != nil {
+		operatorSri, err := tkValueObject.NewSystemResourceIdentifier(*aggregationRow.OperatorSri)
+		if err != nil {
+			return aggregationGroup, err
+		}
+		aggregationGroup.OperatorSri = &operatorSri
+	}
+
+	if aggregationRow.AffectedResourceType != nil {
+		resourceType, err := tkValueObject.NewSystemResourceType(
+			*aggregationRow.AffectedResourceType,
+		)
+		if err != nil {
+			return aggregationGroup, err
+		}
+		aggregationGroup.AffectedResourceType = &resourceType
+	}
+
+	if aggregationRow.TimeBucketStartAt != nil {
+		timeBucketStartAt := tkValueObject.UnixTime(*aggregationRow.TimeBucketStartAt)
+		aggregationGroup.TimeBucketStartAt = &timeBucketStartAt
+	}
+
+	return aggregationGroup, nil
+}
+
+// Aggregate counts the records matching the read filters with a single GROUP BY query.
+// Groups are sorted by time bucket (oldest first) and then by records count.
+func (repo *ActivityRecordQueryRepo) Aggregate(
+	aggregateDto tkDto.AggregateActivityRecordsRequest,
+) (responseDto tkDto.AggregateActivityRecordsResponse, err error) {
+	dbQuery, err := repo.filteredQueryBuilder(aggregateDto.ReadRequest)
+	if err != nil {
+		return responseDto, err
+	}
+
+	selectExpressions := []string{}
+	groupByColumns := []string{}
+	for _, aggregationDimension := range aggregateDto.GroupBy {
+		switch aggregationDimension {
+		case tkValueObject.ActivityRecordAggregationDimensionRecordCode:
+			selectExpressions = append(selectExpressions, "activity_records.record_code AS record_code")
+			groupByColumns = append(groupByColumns, "record_code")
+		case tkValueObject.ActivityRecordAggregationDimensionRecordLevel:
+			selectExpressions = append(selectExpressions, "activity_records.record_level AS record_level")
+			groupByColumns = append(groupByColumns, "record_level")
+		case tkValueObject.ActivityRecordAggregationDimensionOperatorSri:
+			selectExpressions = append(selectExpressions, "activity_records.operator_sri AS operator_sri")
+			groupByColumns = append(groupByColumns, "operator_sri")
+		case tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType:
+			dbQuery = dbQuery.Joins(
+				"LEFT JOIN activity_records_affected_resources affected_resources " +
+					"ON affected_resources.activity_record_id = activity_records.id",
+			)
+			selectExpressions = append(
+				selectExpressions, "affected_resources.resource_type AS affected_resource_type",
+			)
+			groupByColumns = append(groupByColumns, "affected_resource_type")
+		default:
+			return responseDto, errors.New("InvalidActivityRecordAggregationDimension")
+		}
+	}
+
+	orderByColumns := []string{"records_count DESC"}
+	if aggregateDto.TimeBucketGranularity != nil {
+		bucketExpression, err := repo.timeBucketExpression(*aggregateDto.TimeBucketGranularity)
+		if err != nil {
+			return responseDto, err
+		}
+		selectExpressions = append(selectExpressions, bucketExpression+" AS time_bucket_start_at")
+		groupByColumns = append(groupByColumns, "time_bucket_start_at")
+		orderByColumns = append([]string{"time_bucket_start_at ASC"}, orderByColumns...)
+	}
+	if len(groupByColumns) == 0 {
+		return responseDto, errors.New("AggregationRequiresGroupByOrTimeBucket")
+	}
+	orderByColumns = append(orderByColumns, groupByColumns...)
+
+	selectExpressions = append(
+		selectExpressions, "COUNT(DISTINCT activity_records.id) AS records_count",
+	)
+
+	maxGroupsCount := activityRecordAggregationMaxGroupsCountDefault
+	if aggregateDto.MaxGroupsCount != 0 {
+		maxGroupsCount = aggregateDto.MaxGroupsCount
+	}
+
+	// One extra row is read to tell whether the groups were cut at maxGroupsCount.
+	aggregationRows := []activityRecordAggregationRow{}
+	err = dbQuery.
+		Select(strings.Join(selectExpressions, ", ")).
+		Group(strings.Join(groupByColumns, ", ")).
+		Order(strings.Join(orderByColumns, ", ")).
+		Limit(int(maxGroupsCount) + 1).
+		Scan(&aggregationRows).Error
+	if err != nil {
+		return responseDto, err
+	}
+	if len(aggregationRows) > int(maxGroupsCount) {
+		responseDto.IsTruncated = true
+		aggregationRows = aggregationRows[:maxGroupsCount]
+	}
+
+	responseDto.Groups = []tkDto.ActivityRecordsAggregationGroup{}
+	for _, aggregationRow := range aggregationRows {
+		aggregationGroup, err := activityRecordAggregationRowToGroup(aggregationRow)
+		if err != nil {
+			slog.Error("ActivityRecordAggregationRowError", slog.String("err", err.Error()))
+			continue
+		}
+		responseDto.Groups = append(responseDto.Groups, aggregationGroup)
+	}
+
+	return responseDto, nil
+}
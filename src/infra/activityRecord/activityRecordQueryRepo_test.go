@@ -1,11 +1,18 @@
 package tkInfraActivityRecord
 
 import (
+	"errors"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
 	"testing"
 	"time"
 
 	tkDto "github.com/goinfinite/tk/src/domain/dto"
 	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
 )
 
 func TestActivityRecordQueryRepoRead(t *testing.T) {
@@ -377,6 +384,135 @@ func TestActivityRecordQueryRepoRead(t *testing.T) {
 			t.Errorf("ExpectedOneRecordWithPagination: got %d", len(responseDto.ActivityRecords))
 		}
 	})
+
+	t.Run("ReadWithCursorNewestFirst", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+
+		recordCodeVo, _ := tkValueObject.NewActivityRecordCode("CURSOR_TEST")
+		for range 5 {
+			_, err := createTestActivityRecord(dbSvc, tkDto.CreateActivityRecord{
+				RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+				RecordCode:        recordCodeVo,
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{},
+			})
+			if err != nil {
+				t.Fatalf("CreateTestActivityRecordFailed: %v", err)
+			}
+		}
+
+		sortByCreatedAt, _ := tkValueObject.NewPaginationSortBy("created_at")
+		requestPagination := tkDto.Pagination{
+			ItemsPerPage:  2,
+			SortBy:        &sortByCreatedAt,
+			SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+		}
+
+		readRecordIds := []uint64{}
+		for range 5 {
+			responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: requestPagination,
+			})
+			if err != nil {
+				t.Fatalf("ReadWithCursorFailed: %v", err)
+			}
+			for _, recordEntity := range responseDto.ActivityRecords {
+				readRecordIds = append(readRecordIds, recordEntity.RecordId.Uint64())
+			}
+			if responseDto.Pagination.NextCursor == nil {
+				break
+			}
+			requestPagination.Cursor = responseDto.Pagination.NextCursor
+		}
+
+		if fmt.Sprint(readRecordIds) != "[5 4 3 2 1]" {
+			t.Errorf("RecordIdsMismatch: expected [5 4 3 2 1], got %v", readRecordIds)
+		}
+	})
+
+	t.Run("ReadWithAccountIdScope", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+
+		accountOperatorSri := tkValueObject.NewSriAccount(tkValueObject.AccountId(12))
+		accountResourceOperatorSri := tkValueObject.NewSystemResourceIdentifierMustCreate(
+			"sri://12:mailbox/info",
+		)
+		otherAccountOperatorSri := tkValueObject.NewSriAccount(tkValueObject.AccountId(120))
+
+		createDtos := []tkDto.CreateActivityRecord{
+			{OperatorSri: &accountOperatorSri},
+			{OperatorSri: &accountResourceOperatorSri},
+			{
+				OperatorSri: &otherAccountOperatorSri,
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{
+					tkValueObject.NewSystemResourceIdentifierMustCreate("sri://12:website/blog"),
+				},
+			},
+			{OperatorSri: &otherAccountOperatorSri},
+			{
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{
+					tkValueObject.NewSystemResourceIdentifierMustCreate("sri://120:website/blog"),
+				},
+			},
+		}
+		for _, createDto := range createDtos {
+			createDto.RecordLevel = tkValueObject.ActivityRecordLevelInfo
+			createDto.RecordCode = tkValueObject.ActivityRecordCode("ScopedRecord")
+			_, err := createTestActivityRecord(dbSvc, createDto)
+			if err != nil {
+				t.Fatalf("CreateTestActivityRecordFailed: %v", err)
+			}
+		}
+
+		accountId := tkValueObject.AccountId(12)
+		responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+			Pagination: tkDto.PaginationUnpaginated,
+			AccountId:  &accountId,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		readRecordIds := []uint64{}
+		for _, activityRecord := range responseDto.ActivityRecords {
+			readRecordIds = append(readRecordIds, activityRecord.RecordId.Uint64())
+		}
+		if fmt.Sprint(readRecordIds) != "[1 2 3]" {
+			t.Errorf("RecordIdsMismatch: expected [1 2 3], got %v", readRecordIds)
+		}
+	})
+
+	t.Run("ReadWithSortByOutsideAllowlist", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+
+		testCaseStructs := []struct {
+			sortBy      string
+			expectError bool
+		}{
+			{"createdAt", false},
+			{"record_code", false},
+			{"recordDetails", true},
+			{"content_hash", true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sortByVo, _ := tkValueObject.NewPaginationSortBy(testCase.sortBy)
+			_, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: tkDto.Pagination{ItemsPerPage: 10, SortBy: &sortByVo},
+			})
+
+			notAllowedErr := &tkInfraDb.PaginationSortByNotAllowedError{}
+			isNotAllowedErr := errors.As(err, &notAllowedErr)
+			if testCase.expectError && !isNotAllowedErr {
+				t.Errorf("MissingExpectedError: [%s]", testCase.sortBy)
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.sortBy)
+			}
+		}
+	})
 }
 
 func TestActivityRecordQueryRepoReadFirst(t *testing.T) {
@@ -427,3 +563,388 @@ func TestActivityRecordQueryRepoReadFirst(t *testing.T) {
 		}
 	})
 }
+
+func TestActivityRecordQueryRepoReadRecordDetailsFilters(t *testing.T) {
+	dbSvc := SetupTestTrailDatabaseService(t)
+	queryRepo := NewActivityRecordQueryRepo(dbSvc)
+
+	recordCodeVo, err := tkValueObject.NewActivityRecordCode("DETAILS_FILTER_TEST")
+	if err != nil {
+		t.Fatalf("CreateRecordCodeVoFailed: %v", err)
+	}
+
+	recordsDetails := []any{
+		map[string]any{
+			"domain": "x.com", "size": 500, "isDryRun": true,
+			"owner": map[string]any{"name": "alice"}, "tags": []string{"a", "b"},
+		},
+		map[string]any{
+			"domain": "y.com", "size": 1500, "isDryRun": false,
+			"owner": map[string]any{"name": "bob"}, "tags": []string{"c"},
+		},
+		map[string]any{"domain": "100%_sure.com", "size": 2500.5, "owner": nil},
+		"NotAnObject",
+		nil,
+		map[string]any{"domain": "z.com", "size": "big", "isDryRun": 1},
+	}
+	for _, recordDetails := range recordsDetails {
+		_, err := createTestActivityRecord(dbSvc, tkDto.CreateActivityRecord{
+			RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:        recordCodeVo,
+			AffectedResources: []tkValueObject.SystemResourceIdentifier{},
+			RecordDetails:     recordDetails,
+		})
+		if err != nil {
+			t.Fatalf("CreateTestActivityRecordFailed: %v", err)
+		}
+	}
+
+	detailsFilterFactory := func(
+		path string, operator string, value any,
+	) tkDto.ActivityRecordDetailsFilter {
+		pathVo, err := tkValueObject.NewActivityRecordDetailsPath(path)
+		if err != nil {
+			t.Fatalf("CreateDetailsPathVoFailed: %v", err)
+		}
+		operatorVo, err := tkValueObject.NewComparisonOperator(operator)
+		if err != nil {
+			t.Fatalf("CreateComparisonOperatorVoFailed: %v", err)
+		}
+		return tkDto.ActivityRecordDetailsFilter{
+			Path: pathVo, Operator: operatorVo, Value: value,
+		}
+	}
+
+	t.Run("ValidFilters", func(t *testing.T) {
+		testCaseStructs := []struct {
+			testName          string
+			detailsFilters    []tkDto.ActivityRecordDetailsFilter
+			expectedRecordIds []uint64
+		}{
+			{
+				"StringEqual",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("domain", "==", "x.com")},
+				[]uint64{1},
+			},
+			{
+				"NumberGreaterThan",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("size", ">", 1000)},
+				[]uint64{2, 3},
+			},
+			{
+				"FloatLessThanOrEqual",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("size", "lte", 1500.0)},
+				[]uint64{1, 2},
+			},
+			{
+				"BoolEqual",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("isDryRun", "eq", false)},
+				[]uint64{2},
+			},
+			{
+				"BoolEqualSkipsNumbers",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("isDryRun", "eq", true)},
+				[]uint64{1},
+			},
+			{
+				"NumberComparisonSkipsStrings",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("size", "ne", 500)},
+				[]uint64{2, 3},
+			},
+			{
+				"StringComparisonSkipsNumbers",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("size", "gt", "1")},
+				[]uint64{6},
+			},
+			{
+				"NestedPath",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("owner.name", "ne", "bob")},
+				[]uint64{1},
+			},
+			{
+				"ArrayIndex",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("tags[1]", "eq", "b")},
+				[]uint64{1},
+			},
+			{
+				"NullEqual",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("owner", "eq", nil)},
+				[]uint64{3, 4, 5, 6},
+			},
+			{
+				"NullNotEqual",
+				[]tkDto.ActivityRecordDetailsFilter{detailsFilterFactory("isDryRun", "ne", nil)},
+				[]uint64{1, 2, 6},
+			},
+			{
+				"ContainsEscapesWildcards",
+				[]tkDto.ActivityRecordDetailsFilter{
+					detailsFilterFactory("domain", "contains", "0%_s"),
+				},
+				[]uint64{3},
+			},
+			{
+				"CombinedWithAnd",
+				[]tkDto.ActivityRecordDetailsFilter{
+					detailsFilterFactory("domain", "contains", ".com"),
+					detailsFilterFactory("size", ">=", 1500),
+					detailsFilterFactory("size", "<", 2000),
+				},
+				[]uint64{2},
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination:           tkDto.PaginationUnpaginated,
+				RecordDetailsFilters: testCase.detailsFilters,
+			})
+			if err != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.testName)
+				continue
+			}
+
+			actualRecordIds := []uint64{}
+			for _, activityRecord := range responseDto.ActivityRecords {
+				actualRecordIds = append(actualRecordIds, activityRecord.RecordId.Uint64())
+			}
+			slices.Sort(actualRecordIds)
+			if !slices.Equal(actualRecordIds, testCase.expectedRecordIds) {
+				t.Errorf(
+					"RecordIdsMismatch: expected %v, got %v [%s]",
+					testCase.expectedRecordIds, actualRecordIds, testCase.testName,
+				)
+			}
+		}
+	})
+
+	t.Run("InvalidFilters", func(t *testing.T) {
+		testCaseStructs := []struct {
+			testName      string
+			detailsFilter tkDto.ActivityRecordDetailsFilter
+		}{
+			{"NullWithOrderingOperator", detailsFilterFactory("size", "gt", nil)},
+			{"ContainsWithNumber", detailsFilterFactory("size", "contains", 10)},
+			{"ObjectValue", detailsFilterFactory("owner", "eq", map[string]any{"a": 1})},
+			{
+				"UnvalidatedPath",
+				tkDto.ActivityRecordDetailsFilter{
+					Path:     tkValueObject.ActivityRecordDetailsPath(`domain") OR 1=1 --`),
+					Operator: tkValueObject.ComparisonOperatorEqual,
+					Value:    "x.com",
+				},
+			},
+			{
+				"UnvalidatedOperator",
+				tkDto.ActivityRecordDetailsFilter{
+					Path:     tkValueObject.ActivityRecordDetailsPath("domain"),
+					Operator: tkValueObject.ComparisonOperator("= 1 OR 1 ="),
+					Value:    "x.com",
+				},
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			_, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: tkDto.PaginationUnpaginated,
+				RecordDetailsFilters: []tkDto.ActivityRecordDetailsFilter{
+					testCase.detailsFilter,
+				},
+			})
+			if err == nil {
+				t.Errorf("MissingExpectedError: [%s]", testCase.testName)
+			}
+		}
+	})
+}
+
+func TestActivityRecordQueryRepoAggregate(t *testing.T) {
+	dbSvc := SetupTestTrailDatabaseService(t)
+	queryRepo := NewActivityRecordQueryRepo(dbSvc)
+
+	operatorSri := "sri://0:account/1"
+	testRecords := []struct {
+		recordLevel       string
+		recordCode        string
+		operatorSri       *string
+		affectedResources []string
+		createdAt         time.Time
+	}{
+		{
+			"INFO", "CodeA", &operatorSri,
+			[]string{"sri://0:mailbox/1", "sri://0:mailbox/2"},
+			time.Date(2026, 1, 5, 10, 15, 0, 0, time.UTC),
+		},
+		{
+			"INFO", "CodeA", nil, []string{"sri://0:domain/x"},
+			time.Date(2026, 1, 5, 7, 45, 0, 0, time.FixedZone("UTC-3", -3*60*60)),
+		},
+		{
+			"ERROR", "CodeB", &operatorSri, []string{},
+			time.Date(2026, 1, 5, 11, 5, 0, 0, time.UTC),
+		},
+		{
+			"INFO", "CodeB", nil, []string{"sri://0:mailbox/3"},
+			time.Date(2026, 2, 10, 0, 0, 0, 0, time.UTC),
+		},
+	}
+	for _, testRecord := range testRecords {
+		affectedResourceModels := []tkInfraDbModel.ActivityRecordAffectedResource{}
+		for _, affectedResource := range testRecord.affectedResources {
+			affectedResourceModels = append(
+				affectedResourceModels,
+				tkInfraDbModel.NewActivityRecordAffectedResource(affectedResource),
+			)
+		}
+		recordModel := tkInfraDbModel.NewActivityRecord(
+			0, testRecord.recordLevel, testRecord.recordCode, affectedResourceModels,
+			nil, testRecord.operatorSri, nil,
+		)
+		recordModel.CreatedAt = testRecord.createdAt
+		err := dbSvc.Handler.Create(&recordModel).Error
+		if err != nil {
+			t.Fatalf("CreateTestRecordFailed: %v", err)
+		}
+	}
+
+	dimensionCode := tkValueObject.ActivityRecordAggregationDimensionRecordCode
+	dimensionLevel := tkValueObject.ActivityRecordAggregationDimensionRecordLevel
+	dimensionOperator := tkValueObject.ActivityRecordAggregationDimensionOperatorSri
+	dimensionResourceType := tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType
+	granularityPtr := func(
+		granularity tkValueObject.TimeBucketGranularity,
+	) *tkValueObject.TimeBucketGranularity {
+		return &granularity
+	}
+	infoLevel := tkValueObject.ActivityRecordLevelInfo
+
+	// Each group is rendered as "<dimension values>=<count>" to keep the cases compact.
+	groupRenderer := func(aggregationGroup tkDto.ActivityRecordsAggregationGroup) string {
+		groupParts := []string{}
+		if aggregationGroup.RecordCode != nil {
+			groupParts = append(groupParts, aggregationGroup.RecordCode.String())
+		}
+		if aggregationGroup.RecordLevel != nil {
+			groupParts = append(groupParts, aggregationGroup.RecordLevel.String())
+		}
+		if aggregationGroup.OperatorSri != nil {
+			groupParts = append(groupParts, aggregationGroup.OperatorSri.String())
+		}
+		if aggregationGroup.AffectedResourceType != nil {
+			groupParts = append(groupParts, aggregationGroup.AffectedResourceType.String())
+		}
+		if aggregationGroup.TimeBucketStartAt != nil {
+			groupParts = append(
+				groupParts,
+				aggregationGroup.TimeBucketStartAt.ReadAsGoTime().UTC().Format(time.DateTime),
+			)
+		}
+		return strings.Join(groupParts, "|") + "=" + strconv.FormatUint(aggregationGroup.RecordsCount, 10)
+	}
+
+	testCaseStructs := []struct {
+		testName       string
+		aggregateDto   tkDto.AggregateActivityRecordsRequest
+		expectedGroups []string
+	}{
+		{
+			"ByRecordCode",
+			tkDto.AggregateActivityRecordsRequest{
+				GroupBy: []tkValueObject.ActivityRecordAggregationDimension{dimensionCode},
+			},
+			[]string{"CodeA=2", "CodeB=2"},
+		},
+		{
+			"ByRecordLevel",
+			tkDto.AggregateActivityRecordsRequest{
+				GroupBy: []tkValueObject.ActivityRecordAggregationDimension{dimensionLevel},
+			},
+			[]string{"INFO=3", "ERROR=1"},
+		},
+		{
+			"ByOperatorSri",
+			tkDto.AggregateActivityRecordsRequest{
+				GroupBy: []tkValueObject.ActivityRecordAggregationDimension{dimensionOperator},
+			},
+			[]string{"=2", "sri://0:account/1=2"},
+		},
+		{
+			"ByAffectedResourceType",
+			tkDto.AggregateActivityRecordsRequest{
+				GroupBy: []tkValueObject.ActivityRecordAggregationDimension{dimensionResourceType},
+			},
+			[]string{"mailbox=2", "=1", "domain=1"},
+		},
+		{
+			"ByHour",
+			tkDto.AggregateActivityRecordsRequest{
+				TimeBucketGranularity: granularityPtr(tkValueObject.TimeBucketGranularityHour),
+			},
+			[]string{"2026-01-05 10:00:00=2", "2026-01-05 11:00:00=1", "2026-02-10 00:00:00=1"},
+		},
+		{
+			"ByDay",
+			tkDto.AggregateActivityRecordsRequest{
+				TimeBucketGranularity: granularityPtr(tkValueObject.TimeBucketGranularityDay),
+			},
+			[]string{"2026-01-05 00:00:00=3", "2026-02-10 00:00:00=1"},
+		},
+		{
+			"ByWeek",
+			tkDto.AggregateActivityRecordsRequest{
+				TimeBucketGranularity: granularityPtr(tkValueObject.TimeBucketGranularityWeek),
+			},
+			[]string{"2026-01-05 00:00:00=3", "2026-02-09 00:00:00=1"},
+		},
+		{
+			"ByMonth",
+			tkDto.AggregateActivityRecordsRequest{
+				TimeBucketGranularity: granularityPtr(tkValueObject.TimeBucketGranularityMonth),
+			},
+			[]string{"2026-01-01 00:00:00=3", "2026-02-01 00:00:00=1"},
+		},
+		{
+			"ByRecordCodeAndDayWithFilter",
+			tkDto.AggregateActivityRecordsRequest{
+				ReadRequest:           tkDto.ReadActivityRecordsRequest{RecordLevel: &infoLevel},
+				GroupBy:               []tkValueObject.ActivityRecordAggregationDimension{dimensionCode},
+				TimeBucketGranularity: granularityPtr(tkValueObject.TimeBucketGranularityDay),
+			},
+			[]string{"CodeA|2026-01-05 00:00:00=2", "CodeB|2026-02-10 00:00:00=1"},
+		},
+		{
+			"MaxGroupsCount",
+			tkDto.AggregateActivityRecordsRequest{
+				GroupBy:        []tkValueObject.ActivityRecordAggregationDimension{dimensionLevel},
+				MaxGroupsCount: 1,
+			},
+			[]string{"INFO=3"},
+		},
+	}
+
+	for _, testCase := range testCaseStructs {
+		responseDto, err := queryRepo.Aggregate(testCase.aggregateDto)
+		if err != nil {
+			t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.testName)
+			continue
+		}
+
+		actualGroups := []string{}
+		for _, aggregationGroup := range responseDto.Groups {
+			actualGroups = append(actualGroups, groupRenderer(aggregationGroup))
+		}
+		if !slices.Equal(actualGroups, testCase.expectedGroups) {
+			t.Errorf(
+				"GroupsMismatch: expected %v, got %v [%s]",
+				testCase.expectedGroups, actualGroups, testCase.testName,
+			)
+		}
+	}
+
+	t.Run("RequiresGroupByOrTimeBucket", func(t *testing.T) {
+		_, err := queryRepo.Aggregate(tkDto.AggregateActivityRecordsRequest{})
+		if err == nil {
+			t.Error("MissingExpectedError: AggregationRequiresGroupByOrTimeBucket")
+		}
+	})
+}
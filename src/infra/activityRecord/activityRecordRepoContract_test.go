@@ -0,0 +1,856 @@
+package tkInfraActivityRecord
+
+import (
+	"errors"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+)
+
+type activityRecordContractCmdRepo interface {
+	tkRepository.ActivityRecordCmdRepo
+	CreateMany(createDtos []tkDto.CreateActivityRecord) error
+}
+
+type activityRecordContractReposFactory func(t *testing.T) (
+	activityRecordContractCmdRepo, tkRepository.ActivityRecordQueryRepo,
+)
+
+// activityRecordContractSeeder creates the five records every contract case relies
+// on, which get the IDs 1 to 5:
+//
+//  1. INFO AccountCreated, operator account 1, affects sri://1:account/1, correlation req_1
+//  2. ERROR LoginFailed, operator account 2, affects sri://2:mailbox/info
+//  3. SECURITY LoginFailed, no operator, affects sri://2:mailbox/info and sri://1:domain/example.com
+//  4. INFO AccountUpdated, operator account 1, affects nothing, no details, correlation req_1
+//  5. WARNING AccountUpdated, operator account 3, affects sri://3:account/3, scalar details
+func activityRecordContractSeeder(t *testing.T, cmdRepo activityRecordContractCmdRepo) {
+	t.Helper()
+
+	sriFactory := tkValueObject.NewSystemResourceIdentifierMustCreate
+	operatorSriFactory := func(rawSri string) *tkValueObject.SystemResourceIdentifier {
+		operatorSri := sriFactory(rawSri)
+		return &operatorSri
+	}
+	ipAddressFactory := func(rawIpAddress string) *tkValueObject.IpAddress {
+		ipAddress := tkValueObject.IpAddress(rawIpAddress)
+		return &ipAddress
+	}
+	correlationId := tkValueObject.CorrelationId("req_1")
+
+	createDtos := []tkDto.CreateActivityRecord{
+		{
+			RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:        "AccountCreated",
+			AffectedResources: []tkValueObject.SystemResourceIdentifier{sriFactory("sri://1:account/1")},
+			RecordDetails: map[string]any{
+				"user": map[string]any{"role": "admin", "age": 30},
+				"tags": []string{"a", "b"}, "enabled": true,
+			},
+			OperatorSri:       operatorSriFactory("sri://1:account/1"),
+			OperatorIpAddress: ipAddressFactory("10.0.0.1"),
+			CorrelationId:     &correlationId,
+		},
+		{
+			RecordLevel:       tkValueObject.ActivityRecordLevelError,
+			RecordCode:        "LoginFailed",
+			AffectedResources: []tkValueObject.SystemResourceIdentifier{sriFactory("sri://2:mailbox/info")},
+			RecordDetails:     map[string]any{"user": map[string]any{"role": "user", "age": 25}, "attempts": 3},
+			OperatorSri:       operatorSriFactory("sri://2:account/2"),
+			OperatorIpAddress: ipAddressFactory("10.0.0.2"),
+		},
+		{
+			RecordLevel: tkValueObject.ActivityRecordLevelSecurity,
+			RecordCode:  "LoginFailed",
+			AffectedResources: []tkValueObject.SystemResourceIdentifier{
+				sriFactory("sri://2:mailbox/info"), sriFactory("sri://1:domain/example.com"),
+			},
+			RecordDetails: map[string]any{"user": map[string]any{"role": "Admin"}, "attempts": 5},
+		},
+		{
+			RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:        "AccountUpdated",
+			OperatorSri:       operatorSriFactory("sri://1:account/1"),
+			OperatorIpAddress: ipAddressFactory("10.0.0.1"),
+			CorrelationId:     &correlationId,
+		},
+		{
+			RecordLevel:       tkValueObject.ActivityRecordLevelWarning,
+			RecordCode:        "AccountUpdated",
+			AffectedResources: []tkValueObject.SystemResourceIdentifier{sriFactory("sri://3:account/3")},
+			RecordDetails:     "plain",
+			OperatorSri:       operatorSriFactory("sri://3:account/3"),
+		},
+	}
+
+	for _, createDto := range createDtos {
+		err := cmdRepo.Create(createDto)
+		if err != nil {
+			t.Fatalf("SeedActivityRecordFailed: '%s'", err.Error())
+		}
+	}
+}
+
+func activityRecordContractIdsReader(
+	t *testing.T,
+	queryRepo tkRepository.ActivityRecordQueryRepo,
+	requestDto tkDto.ReadActivityRecordsRequest,
+) string {
+	t.Helper()
+
+	if requestDto.Pagination.ItemsPerPage == 0 {
+		requestDto.Pagination = tkDto.PaginationUnpaginated
+	}
+	responseDto, err := queryRepo.Read(requestDto)
+	if err != nil {
+		t.Fatalf("UnexpectedError: '%s'", err.Error())
+	}
+
+	recordIds := []uint64{}
+	for _, activityRecord := range responseDto.ActivityRecords {
+		recordIds = append(recordIds, activityRecord.RecordId.Uint64())
+	}
+	return fmt.Sprint(recordIds)
+}
+
+// activityRecordContractRunner holds every ActivityRecordCmdRepo and
+// ActivityRecordQueryRepo implementation to the same behavior.
+func activityRecordContractRunner(
+	t *testing.T,
+	reposFactory activityRecordContractReposFactory,
+) {
+	recordIdFactory := func(rawRecordId uint64) *tkValueObject.ActivityRecordId {
+		recordId := tkValueObject.ActivityRecordId(rawRecordId)
+		return &recordId
+	}
+	recordLevelFactory := func(
+		recordLevel tkValueObject.ActivityRecordLevel,
+	) *tkValueObject.ActivityRecordLevel {
+		return &recordLevel
+	}
+	recordCodeFactory := func(rawRecordCode string) *tkValueObject.ActivityRecordCode {
+		recordCode := tkValueObject.ActivityRecordCode(rawRecordCode)
+		return &recordCode
+	}
+	sriFactory := func(rawSri string) *tkValueObject.SystemResourceIdentifier {
+		sri := tkValueObject.NewSystemResourceIdentifierMustCreate(rawSri)
+		return &sri
+	}
+	ipAddressFactory := func(rawIpAddress string) *tkValueObject.IpAddress {
+		ipAddress := tkValueObject.IpAddress(rawIpAddress)
+		return &ipAddress
+	}
+	correlationIdFactory := func(rawCorrelationId string) *tkValueObject.CorrelationId {
+		correlationId := tkValueObject.CorrelationId(rawCorrelationId)
+		return &correlationId
+	}
+	unixTimeFactory := func(timeOffset time.Duration) *tkValueObject.UnixTime {
+		unixTime := tkValueObject.NewUnixTimeAfterNow(timeOffset)
+		return &unixTime
+	}
+	accountIdFactory := func(rawAccountId uint64) *tkValueObject.AccountId {
+		accountId := tkValueObject.AccountId(rawAccountId)
+		return &accountId
+	}
+	detailsFilterFactory := func(
+		rawPath string, comparisonOperator tkValueObject.ComparisonOperator, filterValue any,
+	) []tkDto.ActivityRecordDetailsFilter {
+		return []tkDto.ActivityRecordDetailsFilter{{
+			Path:     tkValueObject.ActivityRecordDetailsPath(rawPath),
+			Operator: comparisonOperator,
+			Value:    filterValue,
+		}}
+	}
+	sortByFactory := func(rawSortBy string) *tkValueObject.PaginationSortBy {
+		sortBy, _ := tkValueObject.NewPaginationSortBy(rawSortBy)
+		return &sortBy
+	}
+	lastSeenId2, _ := tkValueObject.NewPaginationLastSeenId("2")
+
+	t.Run("ReadFilters", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		testCaseStructs := []struct {
+			name        string
+			requestDto  tkDto.ReadActivityRecordsRequest
+			expectedIds string
+		}{
+			{"NoFilters", tkDto.ReadActivityRecordsRequest{}, "[1 2 3 4 5]"},
+			{"RecordId", tkDto.ReadActivityRecordsRequest{RecordId: recordIdFactory(3)}, "[3]"},
+			{
+				"RecordLevel",
+				tkDto.ReadActivityRecordsRequest{
+					RecordLevel: recordLevelFactory(tkValueObject.ActivityRecordLevelInfo),
+				},
+				"[1 4]",
+			},
+			{
+				"RecordCode",
+				tkDto.ReadActivityRecordsRequest{RecordCode: recordCodeFactory("LoginFailed")},
+				"[2 3]",
+			},
+			{
+				"AffectedResourcesAnyMatch",
+				tkDto.ReadActivityRecordsRequest{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://1:account/1"), *sriFactory("sri://1:domain/example.com"),
+					},
+				},
+				"[1 3]",
+			},
+			{
+				"AffectedResourcesWildcard",
+				tkDto.ReadActivityRecordsRequest{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://2:mailbox/*"),
+					},
+				},
+				"[2 3]",
+			},
+			{
+				"AffectedResourcesWildcardOtherAccount",
+				tkDto.ReadActivityRecordsRequest{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://3:mailbox/*"),
+					},
+				},
+				"[]",
+			},
+			{
+				"AffectedResourcesAccountWide",
+				tkDto.ReadActivityRecordsRequest{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://0:account/1"),
+					},
+				},
+				"[1 3]",
+			},
+			{
+				"OperatorSri",
+				tkDto.ReadActivityRecordsRequest{OperatorSri: sriFactory("sri://1:account/1")},
+				"[1 4]",
+			},
+			{
+				"OperatorIpAddress",
+				tkDto.ReadActivityRecordsRequest{OperatorIpAddress: ipAddressFactory("10.0.0.2")},
+				"[2]",
+			},
+			{
+				"CorrelationId",
+				tkDto.ReadActivityRecordsRequest{CorrelationId: correlationIdFactory("req_1")},
+				"[1 4]",
+			},
+			{
+				"CorrelationIdNoMatch",
+				tkDto.ReadActivityRecordsRequest{CorrelationId: correlationIdFactory("req_2")},
+				"[]",
+			},
+			{
+				"CreatedBeforeAtFuture",
+				tkDto.ReadActivityRecordsRequest{CreatedBeforeAt: unixTimeFactory(time.Hour)},
+				"[1 2 3 4 5]",
+			},
+			{
+				"CreatedAfterAtFuture",
+				tkDto.ReadActivityRecordsRequest{CreatedAfterAt: unixTimeFactory(time.Hour)},
+				"[]",
+			},
+			{
+				"CreatedAfterAtPast",
+				tkDto.ReadActivityRecordsRequest{CreatedAfterAt: unixTimeFactory(-time.Hour)},
+				"[1 2 3 4 5]",
+			},
+			{"AccountIdOperatorOrAffected", tkDto.ReadActivityRecordsRequest{AccountId: accountIdFactory(1)}, "[1 3 4]"},
+			{"AccountIdAffectedOnly", tkDto.ReadActivityRecordsRequest{AccountId: accountIdFactory(2)}, "[2 3]"},
+			{
+				"DetailsStringEqual",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.role", tkValueObject.ComparisonOperatorEqual, "admin",
+					),
+				},
+				"[1]",
+			},
+			{
+				"DetailsStringNotEqualSkipsMissingPaths",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.role", tkValueObject.ComparisonOperatorNotEqual, "admin",
+					),
+				},
+				"[2 3]",
+			},
+			{
+				"DetailsContains",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.role", tkValueObject.ComparisonOperatorContains, "dmin",
+					),
+				},
+				"[1 3]",
+			},
+			{
+				"DetailsNumberGreaterThanOrEqual",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"attempts", tkValueObject.ComparisonOperatorGreaterThanOrEqual, 4,
+					),
+				},
+				"[3]",
+			},
+			{
+				"DetailsNestedNumberLessThan",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.age", tkValueObject.ComparisonOperatorLessThan, 30.0,
+					),
+				},
+				"[2]",
+			},
+			{
+				"DetailsBoolean",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"enabled", tkValueObject.ComparisonOperatorEqual, true,
+					),
+				},
+				"[1]",
+			},
+			{
+				"DetailsArrayIndex",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"tags[1]", tkValueObject.ComparisonOperatorEqual, "b",
+					),
+				},
+				"[1]",
+			},
+			{
+				"DetailsNullEqual",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.role", tkValueObject.ComparisonOperatorEqual, nil,
+					),
+				},
+				"[4 5]",
+			},
+			{
+				"DetailsNullNotEqual",
+				tkDto.ReadActivityRecordsRequest{
+					RecordDetailsFilters: detailsFilterFactory(
+						"user.role", tkValueObject.ComparisonOperatorNotEqual, nil,
+					),
+				},
+				"[1 2 3]",
+			},
+			{
+				"CombinedFilters",
+				tkDto.ReadActivityRecordsRequest{
+					RecordCode: recordCodeFactory("LoginFailed"),
+					AccountId:  accountIdFactory(1),
+				},
+				"[3]",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				recordIds := activityRecordContractIdsReader(t, queryRepo, testCase.requestDto)
+				if recordIds != testCase.expectedIds {
+					t.Errorf("RecordIdsMismatch: expected %s, got %s", testCase.expectedIds, recordIds)
+				}
+			})
+		}
+	})
+
+	t.Run("ReadInvalidDetailsFilters", func(t *testing.T) {
+		_, queryRepo := reposFactory(t)
+
+		testCaseStructs := []struct {
+			name          string
+			detailsFilter []tkDto.ActivityRecordDetailsFilter
+		}{
+			{
+				"ContainsWithNumber",
+				detailsFilterFactory("attempts", tkValueObject.ComparisonOperatorContains, 3),
+			},
+			{
+				"NullWithGreaterThan",
+				detailsFilterFactory("attempts", tkValueObject.ComparisonOperatorGreaterThan, nil),
+			},
+			{
+				"UnsupportedValue",
+				detailsFilterFactory("attempts", tkValueObject.ComparisonOperatorEqual, []int{1}),
+			},
+			{
+				"InvalidPath",
+				detailsFilterFactory(`user"role`, tkValueObject.ComparisonOperatorEqual, "admin"),
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				_, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+					Pagination:           tkDto.PaginationUnpaginated,
+					RecordDetailsFilters: testCase.detailsFilter,
+				})
+				if err == nil || !strings.HasPrefix(err.Error(), "InvalidRecordDetailsFilter") {
+					t.Errorf("MissingExpectedError: InvalidRecordDetailsFilter (got %v)", err)
+				}
+			})
+		}
+	})
+
+	t.Run("ReadPaginationAndSorting", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		testCaseStructs := []struct {
+			name        string
+			pagination  tkDto.Pagination
+			expectedIds string
+		}{
+			{"SecondPage", tkDto.Pagination{PageNumber: 1, ItemsPerPage: 2}, "[3 4]"},
+			{"PageOutOfRange", tkDto.Pagination{PageNumber: 3, ItemsPerPage: 2}, "[]"},
+			{"LastSeenId", tkDto.Pagination{ItemsPerPage: 10, LastSeenId: &lastSeenId2}, "[3 4 5]"},
+			{
+				"LastSeenIdDescending",
+				tkDto.Pagination{
+					ItemsPerPage:  10,
+					LastSeenId:    &lastSeenId2,
+					SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+				},
+				"[1]",
+			},
+			{
+				"SortByRecordCode",
+				tkDto.Pagination{ItemsPerPage: 10, SortBy: sortByFactory("recordCode")},
+				"[1 4 5 2 3]",
+			},
+			{
+				"SortByOperatorSriDescendingNullsLast",
+				tkDto.Pagination{
+					ItemsPerPage:  10,
+					SortBy:        sortByFactory("operatorSri"),
+					SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+				},
+				"[5 2 4 1 3]",
+			},
+			{
+				"SortBySnakeCasedCreatedAt",
+				tkDto.Pagination{
+					ItemsPerPage:  10,
+					SortBy:        sortByFactory("created_at"),
+					SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+				},
+				"[5 4 3 2 1]",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				recordIds := activityRecordContractIdsReader(
+					t, queryRepo, tkDto.ReadActivityRecordsRequest{Pagination: testCase.pagination},
+				)
+				if recordIds != testCase.expectedIds {
+					t.Errorf("RecordIdsMismatch: expected %s, got %s", testCase.expectedIds, recordIds)
+				}
+			})
+		}
+
+		t.Run("Totals", func(t *testing.T) {
+			responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: tkDto.Pagination{ItemsPerPage: 2},
+				RecordCode: recordCodeFactory("AccountUpdated"),
+			})
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			if *responseDto.Pagination.ItemsTotal != 2 || *responseDto.Pagination.PagesTotal != 1 {
+				t.Errorf(
+					"TotalsMismatch: expected 2 items and 1 page, got %d and %d",
+					*responseDto.Pagination.ItemsTotal, *responseDto.Pagination.PagesTotal,
+				)
+			}
+		})
+
+		t.Run("CursorWalk", func(t *testing.T) {
+			requestPagination := tkDto.Pagination{
+				ItemsPerPage: 2, SortBy: sortByFactory("recordLevel"),
+			}
+			walkedIds := []string{}
+			for range 5 {
+				responseDto, err := queryRepo.Read(
+					tkDto.ReadActivityRecordsRequest{Pagination: requestPagination},
+				)
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				for _, activityRecord := range responseDto.ActivityRecords {
+					walkedIds = append(walkedIds, activityRecord.RecordId.String())
+				}
+				if responseDto.Pagination.NextCursor == nil {
+					break
+				}
+				requestPagination = tkDto.Pagination{
+					ItemsPerPage: 2, Cursor: responseDto.Pagination.NextCursor,
+				}
+			}
+
+			// ERROR, INFO, INFO, SECURITY, WARNING.
+			if strings.Join(walkedIds, " ") != "2 1 4 3 5" {
+				t.Errorf("WalkedIdsMismatch: expected 2 1 4 3 5, got %v", walkedIds)
+			}
+		})
+
+		t.Run("SortByNotAllowed", func(t *testing.T) {
+			_, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: tkDto.Pagination{
+					ItemsPerPage: 2, SortBy: sortByFactory("recordDetails"),
+				},
+			})
+			notAllowedErr := &tkInfraDb.PaginationSortByNotAllowedError{}
+			if !errors.As(err, &notAllowedErr) {
+				t.Errorf("MissingExpectedError: PaginationSortByNotAllowed (got %v)", err)
+			}
+		})
+	})
+
+	t.Run("ReadFirstAndEntityContent", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		activityRecord, err := queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{
+			RecordId: recordIdFactory(1),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		expectedDetails := `{"enabled":true,"tags":["a","b"],"user":{"age":30,"role":"admin"}}`
+		if activityRecord.RecordDetails != expectedDetails {
+			t.Errorf("RecordDetailsMismatch: expected %s, got %v", expectedDetails, activityRecord.RecordDetails)
+		}
+		if fmt.Sprint(activityRecord.AffectedResources) != "[sri://1:account/1]" ||
+			activityRecord.OperatorSri.String() != "sri://1:account/1" ||
+			activityRecord.OperatorIpAddress.String() != "10.0.0.1" ||
+			activityRecord.CorrelationId.String() != "req_1" {
+			t.Errorf("RecordContentMismatch: %+v", activityRecord)
+		}
+		if time.Since(activityRecord.CreatedAt.ReadAsGoTime()) > time.Minute {
+			t.Errorf("CreatedAtMismatch: %s", activityRecord.CreatedAt.String())
+		}
+
+		activityRecord, err = queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{
+			RecordId: recordIdFactory(4),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if activityRecord.RecordDetails != nil || len(activityRecord.AffectedResources) != 0 {
+			t.Errorf("EmptyRecordContentMismatch: %+v", activityRecord)
+		}
+
+		_, err = queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{
+			RecordId: recordIdFactory(42),
+		})
+		if err == nil || err.Error() != tkUseCase.ErrActivityRecordNotFound {
+			t.Errorf("MissingExpectedError: %s (got %v)", tkUseCase.ErrActivityRecordNotFound, err)
+		}
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		err := cmdRepo.CreateMany([]tkDto.CreateActivityRecord{
+			{RecordLevel: tkValueObject.ActivityRecordLevelInfo, RecordCode: "BatchCreated"},
+			{RecordLevel: tkValueObject.ActivityRecordLevelDebug, RecordCode: "BatchCreated"},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		recordIds := activityRecordContractIdsReader(t, queryRepo, tkDto.ReadActivityRecordsRequest{
+			RecordCode: recordCodeFactory("BatchCreated"),
+		})
+		if recordIds != "[1 2]" {
+			t.Errorf("RecordIdsMismatch: expected [1 2], got %s", recordIds)
+		}
+	})
+
+	t.Run("CreateRedactsRecordDetails", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		err := cmdRepo.Create(tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelSecurity,
+			RecordCode:  "PasswordUpdated",
+			RecordDetails: map[string]any{
+				"username": "admin",
+				"password": tkValueObject.Password("Sup3rS3cret!"),
+				"apiToken": "abc123",
+			},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		activityRecord, err := queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		expectedDetails := `{"apiToken":"[REDACTED]","password":"[REDACTED]","username":"admin"}`
+		if activityRecord.RecordDetails != expectedDetails {
+			t.Errorf(
+				"RecordDetailsMismatch: expected %s, got %v",
+				expectedDetails, activityRecord.RecordDetails,
+			)
+		}
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		testCaseStructs := []struct {
+			name                 string
+			deleteDto            tkDto.DeleteActivityRecord
+			expectedDeletedCount uint64
+			expectedRemainingIds string
+		}{
+			{
+				"ScopedToAccount",
+				tkDto.DeleteActivityRecord{
+					RecordCode: recordCodeFactory("LoginFailed"), AccountId: accountIdFactory(1),
+				},
+				1, "[1 2 4 5]",
+			},
+			{"ByRecordId", tkDto.DeleteActivityRecord{RecordId: recordIdFactory(1)}, 1, "[2 4 5]"},
+			{"NoMatch", tkDto.DeleteActivityRecord{RecordId: recordIdFactory(42)}, 0, "[2 4 5]"},
+			{
+				"ByAffectedResource",
+				tkDto.DeleteActivityRecord{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://2:mailbox/info"),
+					},
+				},
+				1, "[4 5]",
+			},
+			{
+				"ByAccountWideAffectedResource",
+				tkDto.DeleteActivityRecord{
+					AffectedResources: []tkValueObject.SystemResourceIdentifier{
+						*sriFactory("sri://0:account/3"),
+					},
+				},
+				1, "[4]",
+			},
+			{
+				"ByCorrelationId",
+				tkDto.DeleteActivityRecord{CorrelationId: correlationIdFactory("req_1")},
+				1, "[]",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			responseDto, err := cmdRepo.Delete(testCase.deleteDto)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s' [%s]", err.Error(), testCase.name)
+			}
+			if responseDto.DeletedRecordsCount != testCase.expectedDeletedCount {
+				t.Errorf(
+					"DeletedRecordsCountMismatch: expected %d, got %d [%s]",
+					testCase.expectedDeletedCount, responseDto.DeletedRecordsCount,
+					testCase.name,
+				)
+			}
+
+			recordIds := activityRecordContractIdsReader(
+				t, queryRepo, tkDto.ReadActivityRecordsRequest{},
+			)
+			if recordIds != testCase.expectedRemainingIds {
+				t.Errorf(
+					"RemainingIdsMismatch: expected %s, got %s [%s]",
+					testCase.expectedRemainingIds, recordIds, testCase.name,
+				)
+			}
+		}
+	})
+
+	t.Run("Purge", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		maxAge := time.Hour
+		maxRecordsCountOne, maxRecordsCountZero := uint64(1), uint64(0)
+		responseDto, err := cmdRepo.Purge(tkDto.PurgeActivityRecordsRequest{
+			RetentionRules: []tkDto.ActivityRecordRetentionRule{
+				{RecordCode: recordCodeFactory("AccountUpdated"), MaxRecordsCount: &maxRecordsCountOne},
+				{RecordLevel: recordLevelFactory(tkValueObject.ActivityRecordLevelError), MaxAge: &maxAge},
+				{
+					RecordLevel:     recordLevelFactory(tkValueObject.ActivityRecordLevelSecurity),
+					MaxRecordsCount: &maxRecordsCountZero,
+				},
+			},
+			BatchSize: 1,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		if responseDto.PurgedRecordsCount != 2 ||
+			fmt.Sprint(responseDto.PurgedRecordsCountPerRule) != "[1 0 1]" {
+			t.Errorf("PurgedCountsMismatch: %+v", responseDto)
+		}
+		recordIds := activityRecordContractIdsReader(t, queryRepo, tkDto.ReadActivityRecordsRequest{})
+		if recordIds != "[1 2 5]" {
+			t.Errorf("RemainingIdsMismatch: expected [1 2 5], got %s", recordIds)
+		}
+	})
+
+	t.Run("Aggregate", func(t *testing.T) {
+		cmdRepo, queryRepo := reposFactory(t)
+		activityRecordContractSeeder(t, cmdRepo)
+
+		groupsStringifier := func(
+			aggregationGroups []tkDto.ActivityRecordsAggregationGroup,
+		) string {
+			groupsStrs := []string{}
+			for _, aggregationGroup := range aggregationGroups {
+				groupParts := []string{}
+				if aggregationGroup.RecordCode != nil {
+					groupParts = append(groupParts, aggregationGroup.RecordCode.String())
+				}
+				if aggregationGroup.AffectedResourceType != nil {
+					groupParts = append(groupParts, aggregationGroup.AffectedResourceType.String())
+				}
+				if aggregationGroup.TimeBucketStartAt != nil {
+					groupParts = append(groupParts, "bucket")
+				}
+				groupParts = append(groupParts, fmt.Sprint(aggregationGroup.RecordsCount))
+				groupsStrs = append(groupsStrs, strings.Join(groupParts, ":"))
+			}
+			return strings.Join(groupsStrs, " ")
+		}
+
+		dayGranularity := tkValueObject.TimeBucketGranularityDay
+		testCaseStructs := []struct {
+			name                string
+			aggregateDto        tkDto.AggregateActivityRecordsRequest
+			expectedGroups      string
+			expectedIsTruncated bool
+		}{
+			{
+				"ByRecordCode",
+				tkDto.AggregateActivityRecordsRequest{
+					GroupBy: []tkValueObject.ActivityRecordAggregationDimension{
+						tkValueObject.ActivityRecordAggregationDimensionRecordCode,
+					},
+				},
+				"AccountUpdated:2 LoginFailed:2 AccountCreated:1", false,
+			},
+			{
+				"ByRecordCodeExactlyAtLimit",
+				tkDto.AggregateActivityRecordsRequest{
+					GroupBy: []tkValueObject.ActivityRecordAggregationDimension{
+						tkValueObject.ActivityRecordAggregationDimensionRecordCode,
+					},
+					MaxGroupsCount: 3,
+				},
+				"AccountUpdated:2 LoginFailed:2 AccountCreated:1", false,
+			},
+			{
+				"ByRecordCodeTruncated",
+				tkDto.AggregateActivityRecordsRequest{
+					GroupBy: []tkValueObject.ActivityRecordAggregationDimension{
+						tkValueObject.ActivityRecordAggregationDimensionRecordCode,
+					},
+					MaxGroupsCount: 2,
+				},
+				"AccountUpdated:2 LoginFailed:2", true,
+			},
+			{
+				"ByAffectedResourceType",
+				tkDto.AggregateActivityRecordsRequest{
+					GroupBy: []tkValueObject.ActivityRecordAggregationDimension{
+						tkValueObject.ActivityRecordAggregationDimensionAffectedResourceType,
+					},
+				},
+				"account:2 mailbox:2 1 domain:1", false,
+			},
+			{
+				"ByRecordCodeWithFiltersAndLimit",
+				tkDto.AggregateActivityRecordsRequest{
+					ReadRequest: tkDto.ReadActivityRecordsRequest{AccountId: accountIdFactory(1)},
+					GroupBy: []tkValueObject.ActivityRecordAggregationDimension{
+						tkValueObject.ActivityRecordAggregationDimensionRecordCode,
+					},
+					MaxGroupsCount: 2,
+				},
+				"AccountCreated:1 AccountUpdated:1", true,
+			},
+			{
+				"ByTimeBucket",
+				tkDto.AggregateActivityRecordsRequest{TimeBucketGranularity: &dayGranularity},
+				"bucket:5", false,
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				responseDto, err := queryRepo.Aggregate(testCase.aggregateDto)
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				aggregationGroups := groupsStringifier(responseDto.Groups)
+				if aggregationGroups != testCase.expectedGroups {
+					t.Errorf(
+						"GroupsMismatch: expected '%s', got '%s'",
+						testCase.expectedGroups, aggregationGroups,
+					)
+				}
+				if responseDto.IsTruncated != testCase.expectedIsTruncated {
+					t.Errorf("UnexpectedIsTruncated: %t", responseDto.IsTruncated)
+				}
+			})
+		}
+
+		_, err := queryRepo.Aggregate(tkDto.AggregateActivityRecordsRequest{})
+		if err == nil || err.Error() != "AggregationRequiresGroupByOrTimeBucket" {
+			t.Errorf("MissingExpectedError: AggregationRequiresGroupByOrTimeBucket (got %v)", err)
+		}
+	})
+
+	t.Run("VerifyHashChainNotEnabled", func(t *testing.T) {
+		_, queryRepo := reposFactory(t)
+		_, err := queryRepo.VerifyHashChain()
+		if err == nil {
+			t.Error("MissingExpectedError: ActivityRecordHashChainNotEnabled")
+		}
+	})
+}
+
+func TestActivityRecordRepoContract(t *testing.T) {
+	t.Run("Gorm", func(t *testing.T) {
+		activityRecordContractRunner(t, func(t *testing.T) (
+			activityRecordContractCmdRepo, tkRepository.ActivityRecordQueryRepo,
+		) {
+			trailDbSvc := SetupTestTrailDatabaseService(t)
+			return NewActivityRecordCmdRepo(trailDbSvc), NewActivityRecordQueryRepo(trailDbSvc)
+		})
+	})
+
+	t.Run("InMemory", func(t *testing.T) {
+		activityRecordContractRunner(t, func(t *testing.T) (
+			activityRecordContractCmdRepo, tkRepository.ActivityRecordQueryRepo,
+		) {
+			queryRepo := NewActivityRecordInMemoryQueryRepo()
+			return NewActivityRecordInMemoryCmdRepo(queryRepo), queryRepo
+		})
+	})
+}
@@ -0,0 +1,94 @@
+package tkInfraActivityRecord
+
+import (
+	"errors"
+	"os"
+	"strings"
+)
+
+const (
+	ActivityRecordHashChainSecretKeyEnvVarName string = "ACTIVITY_RECORD_HASH_CHAIN_SECRET_KEY"
+	errActivityRecordHashChainSecretKeyNotSet  string = "ActivityRecordHashChainSecretKeyNotSet"
+)
+
+// ActivityRecordRepoSettings holds the optional behaviors shared by the activity
+// record cmd and query repositories. Both repositories MUST receive the same settings.
+//
+// ShouldChainHashes turns on the tamper-evident mode: every new record stores the
+// hash of its canonical content chained to the previous record hash, and deletions
+// leave signed checkpoints so the chain stays verifiable. The checkpoints are signed
+// with HashChainSecretKey, read from ACTIVITY_RECORD_HASH_CHAIN_SECRET_KEY when empty.
+//
+// StreamHub, when set, is notified of every record created by the cmd repository.
+//
+// DetailsRedactor masks the sensitive RecordDetails values before they are persisted;
+// when nil, a redactor with the default settings is used.
+//
+// ShouldEncryptRecordDetails and ShouldEncryptOperatorIpAddress encrypt those columns
+// at rest with tkInfra.Cypher (AES-GCM), using EncryptionSecretKey, read from
+// ACTIVITY_RECORD_ENCRYPTION_SECRET_KEY when empty (EnvsInspector can auto-fill it).
+// Each value is tagged with its key ID, so after a key rotation the rows encrypted
+// with the old keys still decrypt as long as those keys are kept in
+// EncryptionPreviousSecretKeys, read from the comma separated
+// ACTIVITY_RECORD_ENCRYPTION_PREVIOUS_SECRET_KEYS when nil. Encrypted columns can't
+// be filtered nor sorted by. Setting only EncryptionSecretKey keeps decrypting the
+// existing rows without encrypting the new ones.
+type ActivityRecordRepoSettings struct {
+	ShouldChainHashes  bool
+	HashChainSecretKey string
+	StreamHub          *ActivityRecordStreamHub
+	DetailsRedactor    *ActivityRecordDetailsRedactor
+
+	ShouldEncryptRecordDetails     bool
+	ShouldEncryptOperatorIpAddress bool
+	EncryptionSecretKey            string
+	EncryptionPreviousSecretKeys   []string
+}
+
+func (settings ActivityRecordRepoSettings) readHashChainSecretKey() (string, error) {
+	if settings.HashChainSecretKey != "" {
+		return settings.HashChainSecretKey, nil
+	}
+
+	envSecretKey := os.Getenv(ActivityRecordHashChainSecretKeyEnvVarName)
+	if envSecretKey == "" {
+		return "", errors.New(errActivityRecordHashChainSecretKeyNotSet)
+	}
+
+	return envSecretKey, nil
+}
+
+func (settings ActivityRecordRepoSettings) readDetailsRedactor() *ActivityRecordDetailsRedactor {
+	if settings.DetailsRedactor != nil {
+		return settings.DetailsRedactor
+	}
+	return activityRecordDetailsRedactorDefault
+}
+
+func (settings ActivityRecordRepoSettings) readEncryptionSecretKeys() (
+	currentSecretKey string, previousSecretKeys []string, err error,
+) {
+	currentSecretKey = settings.EncryptionSecretKey
+	if currentSecretKey == "" {
+		currentSecretKey = os.Getenv(ActivityRecordEncryptionSecretKeyEnvVarName)
+	}
+	if currentSecretKey == "" {
+		return "", nil, errors.New(errActivityRecordEncryptionSecretKeyNotSet)
+	}
+
+	previousSecretKeys = settings.EncryptionPreviousSecretKeys
+	if previousSecretKeys == nil {
+		rawPreviousSecretKeys := os.Getenv(ActivityRecordEncryptionPreviousSecretKeysEnvVarName)
+		for rawSecretKey := range strings.SplitSeq(
+			rawPreviousSecretKeys, activityRecordEncryptionPreviousKeysSeparator,
+		) {
+			secretKey := strings.TrimSpace(rawSecretKey)
+			if secretKey == "" {
+				continue
+			}
+			previousSecretKeys = append(previousSecretKeys, secretKey)
+		}
+	}
+
+	return currentSecretKey, previousSecretKeys, nil
+}
@@ -0,0 +1,101 @@
+package tkInfraActivityRecord
+
+import (
+	"sync"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+)
+
+const activityRecordRetentionPurgerIntervalSecsDefault uint = 3600
+
+type ActivityRecordRetentionPurgerSettings struct {
+	PurgeRequest tkDto.PurgeActivityRecordsRequest
+	IntervalSecs uint
+}
+
+// ActivityRecordRetentionPurger periodically runs tkUseCase.PurgeActivityRecords in the
+// background. The first run happens right after Start.
+type ActivityRecordRetentionPurger struct {
+	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo
+	purgeRequest          tkDto.PurgeActivityRecordsRequest
+	interval              time.Duration
+
+	lifecycleMutex    sync.Mutex
+	stopChannel       chan struct{}
+	workerDoneChannel chan struct{}
+}
+
+func NewActivityRecordRetentionPurger(
+	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo,
+	settings ActivityRecordRetentionPurgerSettings,
+) *ActivityRecordRetentionPurger {
+	intervalSecs := activityRecordRetentionPurgerIntervalSecsDefault
+	if settings.IntervalSecs != 0 {
+		intervalSecs = settings.IntervalSecs
+	}
+
+	return &ActivityRecordRetentionPurger{
+		activityRecordCmdRepo: activityRecordCmdRepo,
+		purgeRequest:          settings.PurgeRequest,
+		interval:              time.Duration(intervalSecs) * time.Second,
+	}
+}
+
+// RunOnce executes a single retention run synchronously.
+func (purger *ActivityRecordRetentionPurger) RunOnce() (
+	tkDto.PurgeActivityRecordsResponse, error,
+) {
+	return tkUseCase.PurgeActivityRecords(purger.activityRecordCmdRepo, purger.purgeRequest)
+}
+
+func (purger *ActivityRecordRetentionPurger) worker(
+	stopChannel, workerDoneChannel chan struct{},
+) {
+	defer close(workerDoneChannel)
+
+	purgeTicker := time.NewTicker(purger.interval)
+	defer purgeTicker.Stop()
+
+	for {
+		// Errors are already logged and recorded by the use case.
+		_, _ = purger.RunOnce()
+
+		select {
+		case <-stopChannel:
+			return
+		case <-purgeTicker.C:
+		}
+	}
+}
+
+// Start launches the background worker. Calling Start on a running purger is a no-op.
+func (purger *ActivityRecordRetentionPurger) Start() {
+	purger.lifecycleMutex.Lock()
+	defer purger.lifecycleMutex.Unlock()
+
+	if purger.stopChannel != nil {
+		return
+	}
+
+	purger.stopChannel = make(chan struct{})
+	purger.workerDoneChannel = make(chan struct{})
+	go purger.worker(purger.stopChannel, purger.workerDoneChannel)
+}
+
+// Stop signals the background worker and waits for an ongoing run to finish.
+func (purger *ActivityRecordRetentionPurger) Stop() {
+	purger.lifecycleMutex.Lock()
+	defer purger.lifecycleMutex.Unlock()
+
+	if purger.stopChannel == nil {
+		return
+	}
+
+	close(purger.stopChannel)
+	<-purger.workerDoneChannel
+	purger.stopChannel = nil
+	purger.workerDoneChannel = nil
+}
@@ -0,0 +1,79 @@
+package tkInfraActivityRecord
+
+import (
+	"testing"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestActivityRecordRetentionPurger(t *testing.T) {
+	maxAge := time.Hour
+	purgeRequest := tkDto.PurgeActivityRecordsRequest{
+		RetentionRules: []tkDto.ActivityRecordRetentionRule{{MaxAge: &maxAge}},
+	}
+
+	t.Run("RunOnceLeavesSummaryRecord", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		purger := NewActivityRecordRetentionPurger(
+			NewActivityRecordCmdRepo(dbSvc),
+			ActivityRecordRetentionPurgerSettings{PurgeRequest: purgeRequest},
+		)
+
+		_, err := purger.RunOnce()
+		if err != nil {
+			t.Fatalf("RunOnceFailed: %v", err)
+		}
+
+		summaryRecord, err := queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{
+			RecordCode: &tkUseCase.ActivityRecordCodeActivityRecordsPurged,
+		})
+		if err != nil {
+			t.Fatalf("ReadSummaryRecordFailed: %v", err)
+		}
+		if summaryRecord.RecordLevel != tkValueObject.ActivityRecordLevelInfo {
+			t.Errorf("SummaryRecordLevelMismatch: got %s", summaryRecord.RecordLevel)
+		}
+	})
+
+	t.Run("InvalidRuleIsRejected", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		purger := NewActivityRecordRetentionPurger(
+			NewActivityRecordCmdRepo(dbSvc),
+			ActivityRecordRetentionPurgerSettings{
+				PurgeRequest: tkDto.PurgeActivityRecordsRequest{
+					RetentionRules: []tkDto.ActivityRecordRetentionRule{{}},
+				},
+			},
+		)
+
+		_, err := purger.RunOnce()
+		if err == nil {
+			t.Errorf("MissingExpectedError: RetentionRuleMustHaveMaxAgeOrMaxRecordsCount")
+		}
+	})
+
+	t.Run("StartAndStop", func(t *testing.T) {
+		dbSvc := SetupTestTrailDatabaseService(t)
+		queryRepo := NewActivityRecordQueryRepo(dbSvc)
+		purger := NewActivityRecordRetentionPurger(
+			NewActivityRecordCmdRepo(dbSvc),
+			ActivityRecordRetentionPurgerSettings{PurgeRequest: purgeRequest},
+		)
+
+		purger.Start()
+		purger.Start()
+		purger.Stop()
+		purger.Stop()
+
+		_, err := queryRepo.ReadFirst(tkDto.ReadActivityRecordsRequest{
+			RecordCode: &tkUseCase.ActivityRecordCodeActivityRecordsPurged,
+		})
+		if err != nil {
+			t.Errorf("BackgroundRunDidNotHappen: %v", err)
+		}
+	})
+}
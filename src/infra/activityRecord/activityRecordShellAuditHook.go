@@ -0,0 +1,38 @@
+package tkInfraActivityRecord
+
+import (
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfra "github.com/goinfinite/tk/src/infra"
+)
+
+var (
+	ActivityRecordCodeShellCommandExecuted tkValueObject.ActivityRecordCode = "ShellCommandExecuted"
+	ActivityRecordCodeShellCommandFailed   tkValueObject.ActivityRecordCode = "ShellCommandFailed"
+)
+
+// NewActivityRecordShellAuditHook returns a ShellExecutionPolicy AuditHook recording
+// every run as an activity record, ShellCommandExecuted (INFO) or, when the command was
+// rejected, didn't start or failed, ShellCommandFailed (ERROR).
+func NewActivityRecordShellAuditHook(
+	activityRecordCmdRepo tkRepository.ActivityRecordCmdRepo,
+	operatorSri *tkValueObject.SystemResourceIdentifier,
+) func(auditEntry tkInfra.ShellAuditEntry) {
+	return func(auditEntry tkInfra.ShellAuditEntry) {
+		recordLevel := tkValueObject.ActivityRecordLevelInfo
+		recordCode := ActivityRecordCodeShellCommandExecuted
+		if auditEntry.Err != nil {
+			recordLevel = tkValueObject.ActivityRecordLevelError
+			recordCode = ActivityRecordCodeShellCommandFailed
+		}
+
+		tkUseCase.CreateActivityRecord(activityRecordCmdRepo, tkDto.CreateActivityRecord{
+			RecordLevel:   recordLevel,
+			RecordCode:    recordCode,
+			RecordDetails: auditEntry,
+			OperatorSri:   operatorSri,
+		})
+	}
+}
@@ -0,0 +1,59 @@
+package tkInfraActivityRecord
+
+import (
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfra "github.com/goinfinite/tk/src/infra"
+)
+
+func TestActivityRecordShellAuditHook(t *testing.T) {
+	testCaseStructs := []struct {
+		name          string
+		command       string
+		args          []string
+		expectedLevel tkValueObject.ActivityRecordLevel
+		expectedCode  tkValueObject.ActivityRecordCode
+	}{
+		{"Executed", "true", nil, tkValueObject.ActivityRecordLevelInfo, ActivityRecordCodeShellCommandExecuted},
+		{"Failed", "false", nil, tkValueObject.ActivityRecordLevelError, ActivityRecordCodeShellCommandFailed},
+		{"Rejected", "echo", []string{"$(id)"}, tkValueObject.ActivityRecordLevelError, ActivityRecordCodeShellCommandFailed},
+	}
+
+	for _, testCase := range testCaseStructs {
+		t.Run(testCase.name, func(t *testing.T) {
+			queryRepo := NewActivityRecordInMemoryQueryRepo()
+			cmdRepo := NewActivityRecordInMemoryCmdRepo(queryRepo)
+
+			_, _ = tkInfra.NewShell(tkInfra.ShellSettings{
+				Command: testCase.command,
+				Args:    testCase.args,
+				ExecutionPolicy: &tkInfra.ShellExecutionPolicy{
+					AuditHook: NewActivityRecordShellAuditHook(cmdRepo, nil),
+				},
+			}).Run()
+
+			responseDto, err := queryRepo.Read(tkDto.ReadActivityRecordsRequest{
+				Pagination: tkDto.PaginationUnpaginated,
+			})
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			if len(responseDto.ActivityRecords) != 1 {
+				t.Fatalf("UnexpectedRecordsCount: %d", len(responseDto.ActivityRecords))
+			}
+
+			activityRecord := responseDto.ActivityRecords[0]
+			if activityRecord.RecordLevel != testCase.expectedLevel ||
+				activityRecord.RecordCode != testCase.expectedCode {
+				t.Errorf(
+					"UnexpectedRecord: %s %s", activityRecord.RecordLevel, activityRecord.RecordCode,
+				)
+			}
+			if activityRecord.RecordDetails == nil {
+				t.Error("MissingRecordDetails")
+			}
+		})
+	}
+}
@@ -0,0 +1,236 @@
+package tkInfraActivityRecord
+
+import (
+	"errors"
+	"slices"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+const (
+	activityRecordStreamSubscriberBufferSizeDefault   uint16 = 256
+	errActivityRecordStreamDetailsFiltersNotSupported string = "ActivityRecordStreamDetailsFiltersNotSupported"
+)
+
+type ActivityRecordStreamHubSettings struct {
+	// SubscriberBufferSize is the amount of records a subscriber may fall behind
+	// before being dropped. Defaults to 256.
+	SubscriberBufferSize uint16
+}
+
+// ActivityRecordStreamHub fans the records created by ActivityRecordCmdRepo (when set
+// in ActivityRecordRepoSettings.StreamHub) out to the live subscribers whose filter
+// they match. Publishing never blocks: a subscriber whose buffer is full is dropped
+// and must resume from the database, using the ID of the last record it received.
+type ActivityRecordStreamHub struct {
+	mutex                sync.Mutex
+	subscriptions        map[*ActivityRecordStreamSubscription]struct{}
+	subscriberBufferSize uint16
+}
+
+func NewActivityRecordStreamHub(
+	settings ActivityRecordStreamHubSettings,
+) *ActivityRecordStreamHub {
+	subscriberBufferSize := settings.SubscriberBufferSize
+	if subscriberBufferSize == 0 {
+		subscriberBufferSize = activityRecordStreamSubscriberBufferSizeDefault
+	}
+
+	return &ActivityRecordStreamHub{
+		subscriptions:        map[*ActivityRecordStreamSubscription]struct{}{},
+		subscriberBufferSize: subscriberBufferSize,
+	}
+}
+
+// ActivityRecordStreamSubscription.Records is closed once the subscription ends,
+// either through Unsubscribe or because the subscriber fell too far behind
+// (IsOverflowed).
+type ActivityRecordStreamSubscription struct {
+	Records        <-chan tkEntity.ActivityRecord
+	recordsChannel chan tkEntity.ActivityRecord
+	filterDto      tkDto.ReadActivityRecordsRequest
+	isOverflowed   atomic.Bool
+}
+
+func (subscription *ActivityRecordStreamSubscription) IsOverflowed() bool {
+	return subscription.isOverflowed.Load()
+}
+
+// Subscribe accepts the ReadActivityRecordsRequest filters, except for Pagination
+// (ignored) and RecordDetailsFilters (rejected, since they're evaluated by the database).
+func (hub *ActivityRecordStreamHub) Subscribe(
+	filterDto tkDto.ReadActivityRecordsRequest,
+) (*ActivityRecordStreamSubscription, error) {
+	if len(filterDto.RecordDetailsFilters) > 0 {
+		return nil, errors.New(errActivityRecordStreamDetailsFiltersNotSupported)
+	}
+
+	recordsChannel := make(chan tkEntity.ActivityRecord, hub.subscriberBufferSize)
+	subscription := &ActivityRecordStreamSubscription{
+		Records:        recordsChannel,
+		recordsChannel: recordsChannel,
+		filterDto:      filterDto,
+	}
+
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.subscriptions[subscription] = struct{}{}
+
+	return subscription, nil
+}
+
+func (hub *ActivityRecordStreamHub) unsubscribe(
+	subscription *ActivityRecordStreamSubscription,
+) {
+	if _, isSubscribed := hub.subscriptions[subscription]; !isSubscribed {
+		return
+	}
+
+	delete(hub.subscriptions, subscription)
+	close(subscription.recordsChannel)
+}
+
+func (hub *ActivityRecordStreamHub) Unsubscribe(
+	subscription *ActivityRecordStreamSubscription,
+) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	hub.unsubscribe(subscription)
+}
+
+func (hub *ActivityRecordStreamHub) SubscribersCount() int {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+	return len(hub.subscriptions)
+}
+
+func (hub *ActivityRecordStreamHub) Publish(activityRecords []tkEntity.ActivityRecord) {
+	hub.mutex.Lock()
+	defer hub.mutex.Unlock()
+
+	for subscription := range hub.subscriptions {
+		for _, activityRecord := range activityRecords {
+			if !ActivityRecordStreamFilterMatches(subscription.filterDto, activityRecord) {
+				continue
+			}
+
+			select {
+			case subscription.recordsChannel <- activityRecord:
+			default:
+				subscription.isOverflowed.Store(true)
+				hub.unsubscribe(subscription)
+			}
+
+			if subscription.IsOverflowed() {
+				break
+			}
+		}
+	}
+}
+
+func activityRecordStreamSriBelongsToAccount(
+	sri tkValueObject.SystemResourceIdentifier,
+	accountId tkValueObject.AccountId,
+) bool {
+	return tkValueObject.NewSriAccount(accountId).Matches(sri)
+}
+
+// ActivityRecordStreamFilterMatches evaluates the filters the same way the query repo
+// Read does (RecordDetailsFilters aside), so live and replayed records are consistent.
+func ActivityRecordStreamFilterMatches(
+	filterDto tkDto.ReadActivityRecordsRequest,
+	activityRecord tkEntity.ActivityRecord,
+) bool {
+	return activityRecordReadFiltersMatcher(
+		filterDto, activityRecord, activityRecord.CreatedAt.ReadAsGoTime(),
+	)
+}
+
+// activityRecordReadFiltersMatcher evaluates every read filter but RecordDetailsFilters.
+// The time filters are compared against createdAt, since the entity CreatedAt is
+// truncated to seconds while the database keeps the full precision.
+func activityRecordReadFiltersMatcher(
+	filterDto tkDto.ReadActivityRecordsRequest,
+	activityRecord tkEntity.ActivityRecord,
+	createdAt time.Time,
+) bool {
+	if filterDto.RecordId != nil && *filterDto.RecordId != activityRecord.RecordId {
+		return false
+	}
+
+	if filterDto.RecordLevel != nil && *filterDto.RecordLevel != activityRecord.RecordLevel {
+		return false
+	}
+
+	if filterDto.RecordCode != nil && *filterDto.RecordCode != activityRecord.RecordCode {
+		return false
+	}
+
+	if len(filterDto.AffectedResources) > 0 {
+		isAnyAffectedResourceMatched := slices.ContainsFunc(
+			activityRecord.AffectedResources,
+			func(affectedResource tkValueObject.SystemResourceIdentifier) bool {
+				return slices.ContainsFunc(
+					filterDto.AffectedResources,
+					func(filterSri tkValueObject.SystemResourceIdentifier) bool {
+						return filterSri.Matches(affectedResource)
+					},
+				)
+			},
+		)
+		if !isAnyAffectedResourceMatched {
+			return false
+		}
+	}
+
+	if filterDto.OperatorSri != nil {
+		if activityRecord.OperatorSri == nil || *filterDto.OperatorSri != *activityRecord.OperatorSri {
+			return false
+		}
+	}
+
+	if filterDto.OperatorIpAddress != nil {
+		if activityRecord.OperatorIpAddress == nil ||
+			*filterDto.OperatorIpAddress != *activityRecord.OperatorIpAddress {
+			return false
+		}
+	}
+
+	if filterDto.CorrelationId != nil {
+		if activityRecord.CorrelationId == nil ||
+			*filterDto.CorrelationId != *activityRecord.CorrelationId {
+			return false
+		}
+	}
+
+	if filterDto.CreatedBeforeAt != nil &&
+		!createdAt.Before(filterDto.CreatedBeforeAt.ReadAsGoTime()) {
+		return false
+	}
+
+	if filterDto.CreatedAfterAt != nil &&
+		!createdAt.After(filterDto.CreatedAfterAt.ReadAsGoTime()) {
+		return false
+	}
+
+	if filterDto.AccountId != nil {
+		isOperatorFromAccount := activityRecord.OperatorSri != nil &&
+			activityRecordStreamSriBelongsToAccount(*activityRecord.OperatorSri, *filterDto.AccountId)
+		isAffectedResourceFromAccount := slices.ContainsFunc(
+			activityRecord.AffectedResources,
+			func(affectedResource tkValueObject.SystemResourceIdentifier) bool {
+				return activityRecordStreamSriBelongsToAccount(affectedResource, *filterDto.AccountId)
+			},
+		)
+		if !isOperatorFromAccount && !isAffectedResourceFromAccount {
+			return false
+		}
+	}
+
+	return true
+}
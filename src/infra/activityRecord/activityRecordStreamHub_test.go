@@ -0,0 +1,204 @@
+package tkInfraActivityRecord
+
+import (
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestActivityRecordStreamFilterMatches(t *testing.T) {
+	operatorSri := tkValueObject.NewSystemResourceIdentifierMustCreate("sri://0:account/5")
+	affectedResource := tkValueObject.NewSystemResourceIdentifierMustCreate("sri://7:mailbox/info")
+	operatorIpAddress := tkValueObject.IpAddress("10.0.0.1")
+	activityRecord := tkEntity.ActivityRecord{
+		RecordId:          tkValueObject.ActivityRecordId(10),
+		RecordLevel:       tkValueObject.ActivityRecordLevelInfo,
+		RecordCode:        tkValueObject.ActivityRecordCode("MailboxCreated"),
+		AffectedResources: []tkValueObject.SystemResourceIdentifier{affectedResource},
+		OperatorSri:       &operatorSri,
+		OperatorIpAddress: &operatorIpAddress,
+		CreatedAt:         tkValueObject.UnixTime(1000),
+	}
+
+	otherRecordId := tkValueObject.ActivityRecordId(11)
+	errorLevel := tkValueObject.ActivityRecordLevelError
+	recordCode := tkValueObject.ActivityRecordCode("MailboxCreated")
+	otherSri := tkValueObject.NewSystemResourceIdentifierMustCreate("sri://0:account/6")
+	otherIpAddress := tkValueObject.IpAddress("10.0.0.2")
+	createdBefore := tkValueObject.UnixTime(1001)
+	createdAfter := tkValueObject.UnixTime(1000)
+	operatorAccountId := tkValueObject.AccountId(5)
+	resourceAccountId := tkValueObject.AccountId(7)
+	otherAccountId := tkValueObject.AccountId(8)
+
+	testCaseStructs := []struct {
+		name            string
+		filterDto       tkDto.ReadActivityRecordsRequest
+		expectedMatches bool
+	}{
+		{"NoFilters", tkDto.ReadActivityRecordsRequest{}, true},
+		{"RecordIdMismatch", tkDto.ReadActivityRecordsRequest{RecordId: &otherRecordId}, false},
+		{"RecordLevelMismatch", tkDto.ReadActivityRecordsRequest{RecordLevel: &errorLevel}, false},
+		{"RecordCodeMatch", tkDto.ReadActivityRecordsRequest{RecordCode: &recordCode}, true},
+		{
+			"AffectedResourcesAnyMatch",
+			tkDto.ReadActivityRecordsRequest{
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{otherSri, affectedResource},
+			},
+			true,
+		},
+		{
+			"AffectedResourcesMismatch",
+			tkDto.ReadActivityRecordsRequest{
+				AffectedResources: []tkValueObject.SystemResourceIdentifier{otherSri},
+			},
+			false,
+		},
+		{"OperatorSriMismatch", tkDto.ReadActivityRecordsRequest{OperatorSri: &otherSri}, false},
+		{
+			"OperatorIpAddressMismatch",
+			tkDto.ReadActivityRecordsRequest{OperatorIpAddress: &otherIpAddress},
+			false,
+		},
+		{"CreatedBeforeAtMatch", tkDto.ReadActivityRecordsRequest{CreatedBeforeAt: &createdBefore}, true},
+		{"CreatedAfterAtIsExclusive", tkDto.ReadActivityRecordsRequest{CreatedAfterAt: &createdAfter}, false},
+		{"OperatorAccountMatch", tkDto.ReadActivityRecordsRequest{AccountId: &operatorAccountId}, true},
+		{
+			"AffectedResourceAccountMatch",
+			tkDto.ReadActivityRecordsRequest{AccountId: &resourceAccountId},
+			true,
+		},
+		{"AccountMismatch", tkDto.ReadActivityRecordsRequest{AccountId: &otherAccountId}, false},
+	}
+
+	for _, testCase := range testCaseStructs {
+		t.Run(testCase.name, func(t *testing.T) {
+			isMatched := ActivityRecordStreamFilterMatches(testCase.filterDto, activityRecord)
+			if isMatched != testCase.expectedMatches {
+				t.Errorf("MatchMismatch: expected %t, got %t", testCase.expectedMatches, isMatched)
+			}
+		})
+	}
+}
+
+func TestActivityRecordStreamHub(t *testing.T) {
+	t.Run("DetailsFiltersNotSupported", func(t *testing.T) {
+		streamHub := NewActivityRecordStreamHub(ActivityRecordStreamHubSettings{})
+		_, err := streamHub.Subscribe(tkDto.ReadActivityRecordsRequest{
+			RecordDetailsFilters: []tkDto.ActivityRecordDetailsFilter{{Path: "user.role"}},
+		})
+		if err == nil {
+			t.Errorf("MissingExpectedError: %s", errActivityRecordStreamDetailsFiltersNotSupported)
+		}
+	})
+
+	t.Run("PublishToMatchingSubscribers", func(t *testing.T) {
+		streamHub := NewActivityRecordStreamHub(ActivityRecordStreamHubSettings{})
+		errorLevel := tkValueObject.ActivityRecordLevelError
+		errorSubscription, err := streamHub.Subscribe(
+			tkDto.ReadActivityRecordsRequest{RecordLevel: &errorLevel},
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		allSubscription, err := streamHub.Subscribe(tkDto.ReadActivityRecordsRequest{})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		streamHub.Publish([]tkEntity.ActivityRecord{
+			{RecordId: 1, RecordLevel: tkValueObject.ActivityRecordLevelInfo},
+			{RecordId: 2, RecordLevel: tkValueObject.ActivityRecordLevelError},
+		})
+
+		if len(errorSubscription.Records) != 1 || len(allSubscription.Records) != 2 {
+			t.Fatalf(
+				"RecordsCountMismatch: expected 1 and 2, got %d and %d",
+				len(errorSubscription.Records), len(allSubscription.Records),
+			)
+		}
+		if activityRecord := <-errorSubscription.Records; activityRecord.RecordId != 2 {
+			t.Errorf("RecordIdMismatch: expected 2, got %d", activityRecord.RecordId)
+		}
+
+		streamHub.Unsubscribe(errorSubscription)
+		streamHub.Unsubscribe(errorSubscription)
+		if streamHub.SubscribersCount() != 1 {
+			t.Errorf("SubscribersCountMismatch: expected 1, got %d", streamHub.SubscribersCount())
+		}
+		if _, isSubscribed := <-errorSubscription.Records; isSubscribed {
+			t.Error("RecordsChannelNotClosed")
+		}
+	})
+
+	t.Run("OverflowedSubscriberIsDropped", func(t *testing.T) {
+		streamHub := NewActivityRecordStreamHub(
+			ActivityRecordStreamHubSettings{SubscriberBufferSize: 2},
+		)
+		subscription, err := streamHub.Subscribe(tkDto.ReadActivityRecordsRequest{})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		streamHub.Publish([]tkEntity.ActivityRecord{{RecordId: 1}, {RecordId: 2}, {RecordId: 3}})
+		if !subscription.IsOverflowed() {
+			t.Fatal("SubscriptionNotOverflowed")
+		}
+		if streamHub.SubscribersCount() != 0 {
+			t.Errorf("SubscribersCountMismatch: expected 0, got %d", streamHub.SubscribersCount())
+		}
+
+		receivedCount := 0
+		for range subscription.Records {
+			receivedCount++
+		}
+		if receivedCount != 2 {
+			t.Errorf("ReceivedCountMismatch: expected 2, got %d", receivedCount)
+		}
+	})
+
+	t.Run("CmdRepoPublishesCreatedRecords", func(t *testing.T) {
+		streamHub := NewActivityRecordStreamHub(ActivityRecordStreamHubSettings{})
+		cmdRepo, err := NewActivityRecordCmdRepoWithSettings(
+			SetupTestTrailDatabaseService(t),
+			ActivityRecordRepoSettings{StreamHub: streamHub},
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		subscription, err := streamHub.Subscribe(tkDto.ReadActivityRecordsRequest{})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		err = cmdRepo.Create(tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:  tkValueObject.ActivityRecordCode("StreamCreate"),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		err = cmdRepo.CreateMany([]tkDto.CreateActivityRecord{
+			{RecordLevel: tkValueObject.ActivityRecordLevelInfo, RecordCode: "StreamCreateMany"},
+			{RecordLevel: tkValueObject.ActivityRecordLevelInfo, RecordCode: "StreamCreateMany"},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		if len(subscription.Records) != 3 {
+			t.Fatalf("RecordsCountMismatch: expected 3, got %d", len(subscription.Records))
+		}
+		for expectedRecordId := uint64(1); expectedRecordId <= 3; expectedRecordId++ {
+			activityRecord := <-subscription.Records
+			if activityRecord.RecordId.Uint64() != expectedRecordId {
+				t.Errorf(
+					"RecordIdMismatch: expected %d, got %d",
+					expectedRecordId, activityRecord.RecordId.Uint64(),
+				)
+			}
+		}
+	})
+}
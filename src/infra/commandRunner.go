@@ -0,0 +1,77 @@
+package tkInfra
+
+import (
+	"context"
+	"os"
+	"strings"
+)
+
+// CommandRunner runs the command described by the settings. Shell implements it, while
+// FakeCommandRunner and RecordReplayCommandRunner let the tests run without the real
+// binaries.
+type CommandRunner interface {
+	RunCommand(ctx context.Context, settings ShellSettings) (ShellResult, error)
+}
+
+// RunCommand runs the settings given through NewShell(settings).RunDetailed, ignoring
+// the receiver ones, so a zero Shell{} is a ready CommandRunner.
+func (Shell) RunCommand(ctx context.Context, settings ShellSettings) (ShellResult, error) {
+	return NewShell(settings).RunDetailed(ctx)
+}
+
+func commandRunnerOrDefault(commandRunner CommandRunner) CommandRunner {
+	if commandRunner == nil {
+		return Shell{}
+	}
+	return commandRunner
+}
+
+// commandRunnerOutputReplayer hands a scripted or recorded result's output to the
+// settings destinations as Shell would: StdoutFilePath (then receiving stdout instead
+// of the result), StderrFilePath, the writer tees and the line callbacks.
+func commandRunnerOutputReplayer(
+	settings ShellSettings, shellResult ShellResult,
+) (ShellResult, error) {
+	if settings.StdoutFilePath != "" {
+		err := os.WriteFile(settings.StdoutFilePath, []byte(shellResult.Stdout), 0644)
+		if err != nil {
+			return shellResult, err
+		}
+	}
+	if settings.StderrFilePath != "" {
+		err := os.WriteFile(settings.StderrFilePath, []byte(shellResult.Stderr), 0644)
+		if err != nil {
+			return shellResult, err
+		}
+	}
+
+	if settings.StdoutWriter != nil {
+		_, _ = settings.StdoutWriter.Write([]byte(shellResult.Stdout))
+	}
+	if settings.StderrWriter != nil {
+		_, _ = settings.StderrWriter.Write([]byte(shellResult.Stderr))
+	}
+	if settings.StdoutLineCallback != nil {
+		stdoutLineWriter := newShellLineWriter(settings.StdoutLineCallback)
+		_, _ = stdoutLineWriter.Write([]byte(shellResult.Stdout))
+		stdoutLineWriter.flush()
+	}
+	if settings.StderrLineCallback != nil {
+		stderrLineWriter := newShellLineWriter(settings.StderrLineCallback)
+		_, _ = stderrLineWriter.Write([]byte(shellResult.Stderr))
+		stderrLineWriter.flush()
+	}
+
+	if settings.StdoutFilePath != "" {
+		shellResult.Stdout = ""
+	}
+	if shellResult.CommandLine == "" {
+		quotedArgs := []string{ShellEscape{}.Quote(settings.Command)}
+		for _, rawArg := range settings.Args {
+			quotedArgs = append(quotedArgs, ShellEscape{}.Quote(rawArg))
+		}
+		shellResult.CommandLine = strings.Join(quotedArgs, " ")
+	}
+
+	return shellResult, nil
+}
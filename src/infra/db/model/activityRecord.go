@@ -1,6 +1,11 @@
 package tkInfraDbModel
 
 import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"slices"
 	"time"
 
 	tkEntity "github.com/goinfinite/tk/src/domain/entity"
@@ -15,7 +20,10 @@ type ActivityRecord struct {
 	RecordDetails     *string
 	OperatorSri       *string
 	OperatorIpAddress *string
+	CorrelationId     *string   `gorm:"index;size:128"`
 	CreatedAt         time.Time `gorm:"not null"`
+	ContentHash       *string   `gorm:"index;size:64"`
+	PreviousHash      *string   `gorm:"size:64"`
 }
 
 func (ActivityRecord) TableName() string {
@@ -44,6 +52,49 @@ func NewActivityRecord(
 	return model
 }
 
+type activityRecordCanonicalContent struct {
+	RecordLevel       string   `json:"recordLevel"`
+	RecordCode        string   `json:"recordCode"`
+	AffectedResources []string `json:"affectedResources"`
+	RecordDetails     *string  `json:"recordDetails"`
+	OperatorSri       *string  `json:"operatorSri"`
+	OperatorIpAddress *string  `json:"operatorIpAddress"`
+	CorrelationId     *string  `json:"correlationId,omitempty"`
+	CreatedAtMicro    int64    `json:"createdAtMicro"`
+}
+
+// ComputeContentHash returns the hex encoded HMAC-SHA256, keyed with the hash chain
+// secret key, of the previous hash followed by the canonical JSON of the record
+// content. Being keyed, the chain can't be recomputed by whoever can only write to the
+// database. The ID is not part of the content since
+// the chain order is already enforced by the previous hash. CreatedAt is considered
+// with microsecond precision to survive the database round trip.
+func (model ActivityRecord) ComputeContentHash(
+	secretKeyBytes []byte, previousHash string,
+) string {
+	affectedResources := []string{}
+	for _, affectedResource := range model.AffectedResources {
+		affectedResources = append(affectedResources, affectedResource.SystemResourceIdentifier)
+	}
+	slices.Sort(affectedResources)
+
+	canonicalContentBytes, _ := json.Marshal(activityRecordCanonicalContent{
+		RecordLevel:       model.RecordLevel,
+		RecordCode:        model.RecordCode,
+		AffectedResources: affectedResources,
+		RecordDetails:     model.RecordDetails,
+		OperatorSri:       model.OperatorSri,
+		OperatorIpAddress: model.OperatorIpAddress,
+		CorrelationId:     model.CorrelationId,
+		CreatedAtMicro:    model.CreatedAt.UTC().UnixMicro(),
+	})
+
+	contentMac := hmac.New(sha256.New, secretKeyBytes)
+	contentMac.Write([]byte(previousHash + "\n"))
+	contentMac.Write(canonicalContentBytes)
+	return hex.EncodeToString(contentMac.Sum(nil))
+}
+
 func (model ActivityRecord) ToEntity() (recordEntity tkEntity.ActivityRecord, err error) {
 	recordId, err := tkValueObject.NewActivityRecordId(model.ID)
 	if err != nil {
@@ -92,9 +143,21 @@ func (model ActivityRecord) ToEntity() (recordEntity tkEntity.ActivityRecord, er
 		operatorIpAddressPtr = &operatorIpAddress
 	}
 
-	return tkEntity.NewActivityRecord(
+	var correlationIdPtr *tkValueObject.CorrelationId
+	if model.CorrelationId != nil {
+		correlationId, err := tkValueObject.NewCorrelationId(*model.CorrelationId)
+		if err != nil {
+			return recordEntity, err
+		}
+		correlationIdPtr = &correlationId
+	}
+
+	recordEntity = tkEntity.NewActivityRecord(
 		recordId, recordLevel, recordCode, affectedResources, recordDetails,
 		operatorSriPtr, operatorIpAddressPtr,
 		tkValueObject.NewUnixTimeWithGoTime(model.CreatedAt),
-	), nil
+	)
+	recordEntity.CorrelationId = correlationIdPtr
+
+	return recordEntity, nil
 }
@@ -1,11 +1,52 @@
 package tkInfraDbModel
 
+import tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+
+// ActivityRecordAffectedResource stores, besides the SRI itself, its parsed AccountId
+// and ResourceType, so wildcard and account-wide filters are served by an index
+// instead of pattern matching the SRIs. They're nil for SRIs that couldn't be parsed.
 type ActivityRecordAffectedResource struct {
-	ID                       uint64 `gorm:"primaryKey"`
-	SystemResourceIdentifier string `gorm:"not null"`
-	ActivityRecordID         uint64 `gorm:"not null"`
+	ID                       uint64  `gorm:"primaryKey"`
+	SystemResourceIdentifier string  `gorm:"not null"`
+	AccountId                *uint64 `gorm:"index:idx_activity_records_affected_resources_account_type,priority:1"`
+	ResourceType             *string `gorm:"index:idx_activity_records_affected_resources_account_type,priority:2"`
+	ActivityRecordID         uint64  `gorm:"not null"`
 }
 
 func (ActivityRecordAffectedResource) TableName() string {
 	return "activity_records_affected_resources"
 }
+
+func NewActivityRecordAffectedResource(
+	systemResourceIdentifier string,
+) ActivityRecordAffectedResource {
+	model := ActivityRecordAffectedResource{
+		SystemResourceIdentifier: systemResourceIdentifier,
+	}
+	model.AccountId, model.ResourceType = ActivityRecordAffectedResourceComponentsReader(
+		systemResourceIdentifier,
+	)
+	return model
+}
+
+// ActivityRecordAffectedResourceComponentsReader parses the AccountId and ResourceType
+// columns out of the SRI, returning nil for the ones that can't be parsed.
+func ActivityRecordAffectedResourceComponentsReader(
+	systemResourceIdentifier string,
+) (accountId *uint64, resourceType *string) {
+	sri := tkValueObject.SystemResourceIdentifier(systemResourceIdentifier)
+
+	sriAccountId, err := sri.ReadAccountId()
+	if err == nil {
+		accountIdUint := sriAccountId.Uint64()
+		accountId = &accountIdUint
+	}
+
+	sriResourceType, err := sri.ReadResourceType()
+	if err == nil {
+		resourceTypeStr := sriResourceType.String()
+		resourceType = &resourceTypeStr
+	}
+
+	return accountId, resourceType
+}
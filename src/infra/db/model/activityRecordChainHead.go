@@ -0,0 +1,19 @@
+package tkInfraDbModel
+
+import "time"
+
+// ActivityRecordChainHead is the single row (ID 1) vouching for the newest chained
+// record, so the deletion of the chain tail is noticed. LastRecordId is 0 and LastHash
+// empty when no chained record is left. Its row is also locked by the chained writes to
+// serialize them across processes.
+type ActivityRecordChainHead struct {
+	ID           uint64    `gorm:"primaryKey"`
+	LastRecordId uint64    `gorm:"not null"`
+	LastHash     string    `gorm:"not null;size:64"`
+	Signature    string    `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (ActivityRecordChainHead) TableName() string {
+	return "activity_record_chain_heads"
+}
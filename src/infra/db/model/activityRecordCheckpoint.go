@@ -0,0 +1,20 @@
+package tkInfraDbModel
+
+import "time"
+
+// ActivityRecordCheckpoint vouches for a hash chain link broken by a deletion. It
+// states that the record ActivityRecordID, whose PreviousHash points to a deleted
+// record, legitimately follows the record whose content hash is AnchorHash (empty
+// when no chained record precedes it anymore).
+type ActivityRecordCheckpoint struct {
+	ID               uint64    `gorm:"primaryKey"`
+	ActivityRecordID uint64    `gorm:"not null;uniqueIndex"`
+	PreviousHash     string    `gorm:"not null;size:64"`
+	AnchorHash       string    `gorm:"not null;index;size:64"`
+	Signature        string    `gorm:"not null"`
+	CreatedAt        time.Time `gorm:"not null"`
+}
+
+func (ActivityRecordCheckpoint) TableName() string {
+	return "activity_record_checkpoints"
+}
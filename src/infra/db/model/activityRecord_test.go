@@ -289,3 +289,49 @@ func assertStringPtrEqual(t *testing.T, actual, expected *string) {
 		t.Errorf("StringPtrValueMismatch: actual=%s, expected=%s", *actual, *expected)
 	}
 }
+
+func TestComputeContentHash(t *testing.T) {
+	baseModel := ActivityRecord{
+		ID:          1,
+		RecordLevel: tkValueObject.ActivityRecordLevelInfo.String(),
+		RecordCode:  "LoginSuccessful",
+		AffectedResources: []ActivityRecordAffectedResource{
+			{SystemResourceIdentifier: "sri://1:account/120"},
+			{SystemResourceIdentifier: "sri://10:virtualHost/local.os"},
+		},
+		RecordDetails: stringPtr(`{"username":"admin"}`),
+		CreatedAt:     time.Date(2023, 1, 15, 10, 30, 0, 123456789, time.UTC),
+	}
+	secretKeyBytes := []byte("contentHashTestSecretKey")
+	baseHash := baseModel.ComputeContentHash(secretKeyBytes, "")
+
+	t.Run("Deterministic", func(t *testing.T) {
+		reorderedModel := baseModel
+		reorderedModel.ID = 2
+		reorderedModel.AffectedResources = []ActivityRecordAffectedResource{
+			baseModel.AffectedResources[1], baseModel.AffectedResources[0],
+		}
+		reorderedModel.CreatedAt = baseModel.CreatedAt.Truncate(time.Microsecond).
+			In(time.FixedZone("UTC-3", -3*60*60))
+
+		if reorderedModel.ComputeContentHash(secretKeyBytes, "") != baseHash {
+			t.Error("ContentHashShouldIgnoreIdResourceOrderAndTimezone")
+		}
+	})
+
+	t.Run("SensitiveToContentAndPreviousHash", func(t *testing.T) {
+		changedModel := baseModel
+		changedModel.RecordDetails = stringPtr(`{"username":"root"}`)
+		if changedModel.ComputeContentHash(secretKeyBytes, "") == baseHash {
+			t.Error("ContentHashShouldChangeWithRecordDetails")
+		}
+
+		if baseModel.ComputeContentHash(secretKeyBytes, baseHash) == baseHash {
+			t.Error("ContentHashShouldChangeWithPreviousHash")
+		}
+
+		if baseModel.ComputeContentHash([]byte("otherSecretKey"), "") == baseHash {
+			t.Error("ContentHashShouldChangeWithSecretKey")
+		}
+	})
+}
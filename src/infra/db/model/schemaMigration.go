@@ -0,0 +1,16 @@
+package tkInfraDbModel
+
+import "time"
+
+// SchemaMigration records a versioned migration applied by the SchemaMigrator. The
+// Namespace keeps the toolkit and the consumer project version sequences apart.
+type SchemaMigration struct {
+	Namespace   string    `gorm:"primaryKey;size:64"`
+	Version     uint64    `gorm:"primaryKey;autoIncrement:false"`
+	Description string    `gorm:"not null"`
+	AppliedAt   time.Time `gorm:"not null"`
+}
+
+func (SchemaMigration) TableName() string {
+	return "schema_migrations"
+}
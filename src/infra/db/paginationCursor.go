@@ -0,0 +1,152 @@
+package tkInfraDb
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"reflect"
+	"strconv"
+	"time"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+const (
+	paginationCursorValueKindNull   string = "null"
+	paginationCursorValueKindString string = "string"
+	paginationCursorValueKindInt    string = "int"
+	paginationCursorValueKindUint   string = "uint"
+	paginationCursorValueKindFloat  string = "float"
+	paginationCursorValueKindBool   string = "bool"
+	paginationCursorValueKindTime   string = "time"
+)
+
+// paginationCursorValue keeps the Go kind of the column value alongside its string
+// form, so it's bound with the very same type (and format, for times) it's stored with.
+type paginationCursorValue struct {
+	Kind  string `json:"k"`
+	Value string `json:"v"`
+}
+
+func newPaginationCursorValue(rawValue any) (cursorValue paginationCursorValue, err error) {
+	reflectValue := reflect.ValueOf(rawValue)
+	for reflectValue.Kind() == reflect.Pointer {
+		if reflectValue.IsNil() {
+			return paginationCursorValue{Kind: paginationCursorValueKindNull}, nil
+		}
+		reflectValue = reflectValue.Elem()
+	}
+	if !reflectValue.IsValid() {
+		return paginationCursorValue{Kind: paginationCursorValueKindNull}, nil
+	}
+
+	if timeValue, assertOk := reflectValue.Interface().(time.Time); assertOk {
+		return paginationCursorValue{
+			Kind: paginationCursorValueKindTime, Value: timeValue.Format(time.RFC3339Nano),
+		}, nil
+	}
+
+	switch reflectValue.Kind() {
+	case reflect.String:
+		return paginationCursorValue{
+			Kind: paginationCursorValueKindString, Value: reflectValue.String(),
+		}, nil
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		return paginationCursorValue{
+			Kind:  paginationCursorValueKindInt,
+			Value: strconv.FormatInt(reflectValue.Int(), 10),
+		}, nil
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return paginationCursorValue{
+			Kind:  paginationCursorValueKindUint,
+			Value: strconv.FormatUint(reflectValue.Uint(), 10),
+		}, nil
+	case reflect.Float32, reflect.Float64:
+		return paginationCursorValue{
+			Kind:  paginationCursorValueKindFloat,
+			Value: strconv.FormatFloat(reflectValue.Float(), 'g', -1, 64),
+		}, nil
+	case reflect.Bool:
+		return paginationCursorValue{
+			Kind:  paginationCursorValueKindBool,
+			Value: strconv.FormatBool(reflectValue.Bool()),
+		}, nil
+	default:
+		return cursorValue, errors.New("UnsupportedPaginationCursorValueType")
+	}
+}
+
+// ReadBindValue returns the value to be bound to the keyset predicate; nil for nulls.
+func (cursorValue paginationCursorValue) ReadBindValue() (bindValue any, err error) {
+	switch cursorValue.Kind {
+	case paginationCursorValueKindNull:
+		return nil, nil
+	case paginationCursorValueKindString:
+		return cursorValue.Value, nil
+	case paginationCursorValueKindInt:
+		return strconv.ParseInt(cursorValue.Value, 10, 64)
+	case paginationCursorValueKindUint:
+		return strconv.ParseUint(cursorValue.Value, 10, 64)
+	case paginationCursorValueKindFloat:
+		return strconv.ParseFloat(cursorValue.Value, 64)
+	case paginationCursorValueKindBool:
+		return strconv.ParseBool(cursorValue.Value)
+	case paginationCursorValueKindTime:
+		return time.Parse(time.RFC3339Nano, cursorValue.Value)
+	default:
+		return nil, errors.New("UnsupportedPaginationCursorValueKind")
+	}
+}
+
+// paginationCursorPayload is the content of a tkValueObject.PaginationCursor: the sort
+// used to produce the page and the sort field and primary key values of its last item.
+// The sort field is resolved through the sortable fields again, never used as column.
+type paginationCursorPayload struct {
+	SortField       string                `json:"c"`
+	SortDirection   string                `json:"d"`
+	SortValue       paginationCursorValue `json:"s"`
+	PrimaryKeyValue paginationCursorValue `json:"p"`
+}
+
+func (payload paginationCursorPayload) encode() (
+	cursor tkValueObject.PaginationCursor, err error,
+) {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return cursor, err
+	}
+
+	return tkValueObject.NewPaginationCursor(
+		base64.RawURLEncoding.EncodeToString(payloadBytes),
+	)
+}
+
+func decodePaginationCursor(
+	cursor tkValueObject.PaginationCursor,
+) (payload paginationCursorPayload, err error) {
+	payloadBytes, err := cursor.ReadPayloadBytes()
+	if err != nil {
+		return payload, errors.New("InvalidPaginationCursor")
+	}
+
+	err = json.Unmarshal(payloadBytes, &payload)
+	if err != nil {
+		return payload, errors.New("InvalidPaginationCursor")
+	}
+
+	_, err = tkValueObject.NewPaginationSortBy(payload.SortField)
+	if err != nil {
+		return payload, errors.New("InvalidPaginationCursor")
+	}
+
+	_, err = tkValueObject.NewPaginationSortDirection(payload.SortDirection)
+	if err != nil {
+		return payload, errors.New("InvalidPaginationCursor")
+	}
+
+	if payload.PrimaryKeyValue.Kind == paginationCursorValueKindNull {
+		return payload, errors.New("InvalidPaginationCursor")
+	}
+
+	return payload, nil
+}
@@ -1,74 +1,368 @@
 package tkInfraDb
 
 import (
+	"context"
 	"errors"
 	"math"
+	"reflect"
 	"strings"
 
 	tkDto "github.com/goinfinite/tk/src/domain/dto"
-	"github.com/iancoleman/strcase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 	"gorm.io/gorm"
 )
 
 const (
-	errItemsPerPageCannotBeZero string = "ItemsPerPageCannotBeZero"
-	errCountItemsTotalError     string = "CountItemsTotalError"
+	errItemsPerPageCannotBeZero     string = "ItemsPerPageCannotBeZero"
+	errCountItemsTotalError         string = "CountItemsTotalError"
+	errPaginationCursorSortMismatch string = "PaginationCursorSortMismatch"
 )
 
+// paginationUnqualifiedColumn strips the table prefix ("table.column") so the column
+// can be compared or looked up in the model schema.
+func paginationUnqualifiedColumn(columnName string) string {
+	lastDotIndex := strings.LastIndex(columnName, ".")
+	if lastDotIndex == -1 {
+		return columnName
+	}
+	return columnName[lastDotIndex+1:]
+}
+
+// PaginationQueryBuilderSettings.SortableFields is the allowlist of the SortBy values;
+// when empty, the snake cased SortBy is used as column (plain identifiers only).
+type PaginationQueryBuilderSettings struct {
+	PrimaryKeyColumn string
+	SortableFields   PaginationSortableFields
+}
+
+func (settings PaginationQueryBuilderSettings) readPrimaryKeyColumn() string {
+	if settings.PrimaryKeyColumn == "" {
+		return "id"
+	}
+	return settings.PrimaryKeyColumn
+}
+
+// paginationCursorSortResolver returns the sort field and direction of the request,
+// adopting the ones of the cursor when absent. A cursor produced by another sort is
+// rejected, since its values would be compared against the wrong column.
+func paginationCursorSortResolver(
+	requestPagination tkDto.Pagination,
+	sortableFields PaginationSortableFields,
+	cursorPayload paginationCursorPayload,
+) (
+	sortField paginationResolvedSortField,
+	sortDirection tkValueObject.PaginationSortDirection,
+	err error,
+) {
+	cursorSortBy, err := tkValueObject.NewPaginationSortBy(cursorPayload.SortField)
+	if err != nil {
+		return sortField, sortDirection, errors.New("InvalidPaginationCursor")
+	}
+	sortField, err = sortableFields.resolve(cursorSortBy)
+	if err != nil {
+		return sortField, sortDirection, errors.New("InvalidPaginationCursor")
+	}
+
+	if requestPagination.SortBy != nil {
+		requestSortField, err := sortableFields.resolve(*requestPagination.SortBy)
+		if err != nil {
+			return sortField, sortDirection, err
+		}
+		if requestSortField.FieldName != sortField.FieldName {
+			return sortField, sortDirection, errors.New(errPaginationCursorSortMismatch)
+		}
+	}
+
+	sortDirection, err = tkValueObject.NewPaginationSortDirection(cursorPayload.SortDirection)
+	if err != nil {
+		return sortField, sortDirection, errors.New("InvalidPaginationCursor")
+	}
+	if requestPagination.SortDirection != nil {
+		if *requestPagination.SortDirection != sortDirection {
+			return sortField, sortDirection, errors.New(errPaginationCursorSortMismatch)
+		}
+	}
+
+	return sortField, sortDirection, nil
+}
+
+// paginationKeysetConditionBuilder returns the predicate of the rows after the cursor,
+// i.e. (sortColumn, primaryKeyColumn) > (?, ?) for ascending sorts or < for descending
+// ones. NULL sort values are placed as the ORDER BY clause places them.
+func paginationKeysetConditionBuilder(
+	sqlDialect SqlDialect,
+	sortField paginationResolvedSortField,
+	primaryKeyColumn string,
+	sortDirection tkValueObject.PaginationSortDirection,
+	cursorPayload paginationCursorPayload,
+) (conditionStr string, conditionArgs []any, err error) {
+	primaryKeyValue, err := cursorPayload.PrimaryKeyValue.ReadBindValue()
+	if err != nil {
+		return conditionStr, conditionArgs, errors.New("InvalidPaginationCursor")
+	}
+
+	comparisonOperator := ">"
+	if sortDirection == tkValueObject.PaginationSortDirectionDesc {
+		comparisonOperator = "<"
+	}
+
+	sortColumn := sortField.ColumnExpression
+	if paginationUnqualifiedColumn(sortColumn) == paginationUnqualifiedColumn(primaryKeyColumn) {
+		return primaryKeyColumn + " " + comparisonOperator + " ?", []any{primaryKeyValue}, nil
+	}
+
+	sortValue, err := cursorPayload.SortValue.ReadBindValue()
+	if err != nil {
+		return conditionStr, conditionArgs, errors.New("InvalidPaginationCursor")
+	}
+
+	isSortValueNull := sortValue == nil
+	isNullsFirst := sortField.IsNullsFirst(sqlDialect, sortDirection)
+	switch {
+	case isSortValueNull && !isNullsFirst:
+		conditionStr = "(" + sortColumn + " IS NULL AND " +
+			primaryKeyColumn + " " + comparisonOperator + " ?)"
+		conditionArgs = []any{primaryKeyValue}
+	case isSortValueNull:
+		conditionStr = "((" + sortColumn + " IS NULL AND " +
+			primaryKeyColumn + " " + comparisonOperator + " ?) OR " +
+			sortColumn + " IS NOT NULL)"
+		conditionArgs = []any{primaryKeyValue}
+	default:
+		conditionStr = "(" + sortColumn + ", " + primaryKeyColumn + ") " +
+			comparisonOperator + " (?, ?)"
+		if !isNullsFirst {
+			conditionStr = "(" + conditionStr + " OR " + sortColumn + " IS NULL)"
+		}
+		conditionArgs = []any{sortValue, primaryKeyValue}
+	}
+
+	return conditionStr, conditionArgs, nil
+}
+
+func paginationOrderStatementBuilder(
+	sqlDialect SqlDialect,
+	sortField paginationResolvedSortField,
+	primaryKeyColumn string,
+	sortDirection tkValueObject.PaginationSortDirection,
+) string {
+	orderStatement := sortField.ColumnExpression + " " + sortDirection.String()
+	if sortField.NullsOrder != PaginationNullsOrderDefault {
+		orderStatement = sqlDialect.OrderByNullsPlacement(
+			sortField.ColumnExpression, sortDirection.String(),
+			sortField.IsNullsFirst(sqlDialect, sortDirection),
+		)
+	}
+
+	sortColumn := paginationUnqualifiedColumn(sortField.ColumnExpression)
+	if sortColumn != paginationUnqualifiedColumn(primaryKeyColumn) {
+		orderStatement += ", " + primaryKeyColumn + " " + sortDirection.String()
+	}
+
+	return orderStatement
+}
+
+// PaginationQueryBuilder applies the pagination to the query, using, by precedence:
+// the opaque Cursor (keyset over the sort column plus the primary key as tiebreaker),
+// the LastSeenId (keyset over the primary key) or the PageNumber (offset).
 func PaginationQueryBuilder(
 	dbQuery *gorm.DB,
 	requestPagination tkDto.Pagination,
 	primaryKeyColumn string,
+) (paginatedQuery *gorm.DB, responsePagination tkDto.Pagination, err error) {
+	return PaginationQueryBuilderWithSettings(
+		dbQuery, requestPagination,
+		PaginationQueryBuilderSettings{PrimaryKeyColumn: primaryKeyColumn},
+	)
+}
+
+// PaginationQueryBuilderWithSettings is the PaginationQueryBuilder restricted to the
+// sortable fields of the settings. A SortBy outside of them is rejected with a
+// *PaginationSortByNotAllowedError.
+func PaginationQueryBuilderWithSettings(
+	dbQuery *gorm.DB,
+	requestPagination tkDto.Pagination,
+	settings PaginationQueryBuilderSettings,
 ) (paginatedQuery *gorm.DB, responsePagination tkDto.Pagination, err error) {
 	if requestPagination.ItemsPerPage == 0 {
 		return paginatedQuery, responsePagination, errors.New(errItemsPerPageCannotBeZero)
 	}
 
+	primaryKeyColumn := settings.readPrimaryKeyColumn()
+	sqlDialect := NewSqlDialect(dbQuery)
+
+	var sortField *paginationResolvedSortField
+	if requestPagination.SortBy != nil {
+		resolvedSortField, err := settings.SortableFields.resolve(*requestPagination.SortBy)
+		if err != nil {
+			return paginatedQuery, responsePagination, err
+		}
+		sortField = &resolvedSortField
+	}
+	sortDirection := tkValueObject.PaginationSortDirectionAsc
+	if requestPagination.SortDirection != nil {
+		sortDirection = *requestPagination.SortDirection
+	}
+
 	var itemsTotal int64
-	err = dbQuery.Count(&itemsTotal).Error
-	if err != nil {
-		return paginatedQuery, responsePagination, errors.New(errCountItemsTotalError + ": " + err.Error())
+	if !requestPagination.ShouldSkipTotals {
+		err = dbQuery.Count(&itemsTotal).Error
+		if err != nil {
+			return paginatedQuery, responsePagination, errors.New(errCountItemsTotalError + ": " + err.Error())
+		}
 	}
 
 	paginatedQuery = dbQuery.Limit(int(requestPagination.ItemsPerPage))
-	switch requestPagination.LastSeenId {
-	case nil:
-		if requestPagination.PageNumber > 0 {
-			offset := int(requestPagination.PageNumber) * int(requestPagination.ItemsPerPage)
-			paginatedQuery = paginatedQuery.Offset(offset)
+	switch {
+	case requestPagination.Cursor != nil:
+		cursorPayload, err := decodePaginationCursor(*requestPagination.Cursor)
+		if err != nil {
+			return paginatedQuery, responsePagination, err
 		}
-	default:
-		cursorColumn := "id"
-		if primaryKeyColumn != "" {
-			cursorColumn = primaryKeyColumn
+
+		cursorSortField, cursorSortDirection, err := paginationCursorSortResolver(
+			requestPagination, settings.SortableFields, cursorPayload,
+		)
+		if err != nil {
+			return paginatedQuery, responsePagination, err
+		}
+		sortField, sortDirection = &cursorSortField, cursorSortDirection
+
+		conditionStr, conditionArgs, err := paginationKeysetConditionBuilder(
+			sqlDialect, cursorSortField, primaryKeyColumn, sortDirection, cursorPayload,
+		)
+		if err != nil {
+			return paginatedQuery, responsePagination, err
+		}
+		paginatedQuery = paginatedQuery.Where(conditionStr, conditionArgs...)
+
+	case requestPagination.LastSeenId != nil:
+		comparisonOperator := " > ?"
+		if sortDirection == tkValueObject.PaginationSortDirectionDesc {
+			comparisonOperator = " < ?"
 		}
 		paginatedQuery = paginatedQuery.Where(
-			cursorColumn+" > ?",
+			primaryKeyColumn+comparisonOperator,
 			requestPagination.LastSeenId.String(),
 		)
+		if sortField == nil {
+			sortField = &paginationResolvedSortField{
+				FieldName: primaryKeyColumn, ColumnExpression: primaryKeyColumn,
+			}
+		}
+
+	case requestPagination.PageNumber > 0:
+		offset := int(requestPagination.PageNumber) * int(requestPagination.ItemsPerPage)
+		paginatedQuery = paginatedQuery.Offset(offset)
+	}
+
+	if sortField != nil {
+		paginatedQuery = paginatedQuery.Order(
+			paginationOrderStatementBuilder(
+				sqlDialect, *sortField, primaryKeyColumn, sortDirection,
+			),
+		)
 	}
 
-	if requestPagination.SortBy != nil {
-		orderStatement := requestPagination.SortBy.String()
-		orderStatement = strings.ToLower(orderStatement)
-		orderStatement = strcase.ToSnake(orderStatement)
-		if requestPagination.SortDirection != nil {
-			orderStatement += " " + requestPagination.SortDirection.String()
+	responsePagination = tkDto.Pagination{
+		PageNumber:    requestPagination.PageNumber,
+		ItemsPerPage:  requestPagination.ItemsPerPage,
+		SortBy:        requestPagination.SortBy,
+		SortDirection: requestPagination.SortDirection,
+		Cursor:        requestPagination.Cursor,
+	}
+	if requestPagination.Cursor != nil {
+		sortByVo, err := tkValueObject.NewPaginationSortBy(sortField.FieldName)
+		if err != nil {
+			return paginatedQuery, responsePagination, errors.New("InvalidPaginationCursor")
 		}
-		paginatedQuery = paginatedQuery.Order(orderStatement)
+		responsePagination.SortBy = &sortByVo
+		responsePagination.SortDirection = &sortDirection
+	}
+
+	if requestPagination.ShouldSkipTotals {
+		return paginatedQuery, responsePagination, nil
 	}
 
 	itemsTotalUint := uint64(itemsTotal)
 	pagesTotal := uint32(
 		math.Ceil(float64(itemsTotal) / float64(requestPagination.ItemsPerPage)),
 	)
+	responsePagination.PagesTotal = &pagesTotal
+	responsePagination.ItemsTotal = &itemsTotalUint
 
-	return paginatedQuery, tkDto.Pagination{
-		PageNumber:    requestPagination.PageNumber,
-		ItemsPerPage:  requestPagination.ItemsPerPage,
-		SortBy:        requestPagination.SortBy,
-		SortDirection: requestPagination.SortDirection,
-		PagesTotal:    &pagesTotal,
-		ItemsTotal:    &itemsTotalUint,
-	}, nil
+	return paginatedQuery, responsePagination, nil
+}
+
+// PaginationNextCursorBuilder returns the cursor of the page following pageModels (a
+// slice of the queried models), encoding the sort field and primary key values of its
+// last item. It returns nil when the page isn't full or the query wasn't sorted, as no
+// stable keyset exists in those cases.
+func PaginationNextCursorBuilder(
+	dbQuery *gorm.DB,
+	responsePagination tkDto.Pagination,
+	settings PaginationQueryBuilderSettings,
+	pageModels any,
+) (nextCursor *tkValueObject.PaginationCursor, err error) {
+	if responsePagination.SortBy == nil {
+		return nil, nil
+	}
+
+	pageModelsValue := reflect.Indirect(reflect.ValueOf(pageModels))
+	if pageModelsValue.Kind() != reflect.Slice {
+		return nil, errors.New("PageModelsMustBeSlice")
+	}
+	pageItemsCount := pageModelsValue.Len()
+	if pageItemsCount == 0 || pageItemsCount < int(responsePagination.ItemsPerPage) {
+		return nil, nil
+	}
+	lastItemValue := reflect.Indirect(pageModelsValue.Index(pageItemsCount - 1))
+
+	sortField, err := settings.SortableFields.resolve(*responsePagination.SortBy)
+	if err != nil {
+		return nil, err
+	}
+	sortDirection := tkValueObject.PaginationSortDirectionAsc
+	if responsePagination.SortDirection != nil {
+		sortDirection = *responsePagination.SortDirection
+	}
+
+	modelStatement := &gorm.Statement{DB: dbQuery}
+	err = modelStatement.Parse(lastItemValue.Addr().Interface())
+	if err != nil {
+		return nil, errors.New("ParseModelSchemaError: " + err.Error())
+	}
+
+	readColumnValue := func(columnName string) (paginationCursorValue, error) {
+		schemaField := modelStatement.Schema.LookUpField(
+			paginationUnqualifiedColumn(columnName),
+		)
+		if schemaField == nil {
+			return paginationCursorValue{}, errors.New("UnknownColumn: " + columnName)
+		}
+		fieldValue, _ := schemaField.ValueOf(context.Background(), lastItemValue)
+		return newPaginationCursorValue(fieldValue)
+	}
+
+	sortValue, err := readColumnValue(sortField.ColumnExpression)
+	if err != nil {
+		return nil, err
+	}
+	primaryKeyValue, err := readColumnValue(settings.readPrimaryKeyColumn())
+	if err != nil {
+		return nil, err
+	}
+
+	cursorPayload := paginationCursorPayload{
+		SortField:       sortField.FieldName,
+		SortDirection:   sortDirection.String(),
+		SortValue:       sortValue,
+		PrimaryKeyValue: primaryKeyValue,
+	}
+	cursor, err := cursorPayload.encode()
+	if err != nil {
+		return nil, err
+	}
+
+	return &cursor, nil
 }
@@ -1,6 +1,7 @@
 package tkInfraDb
 
 import (
+	"errors"
 	"fmt"
 	"testing"
 	"time"
@@ -21,6 +22,11 @@ type testPaginationCustomPkModel struct {
 	Name     string
 }
 
+type testPaginationCursorModel struct {
+	ID    uint64 `gorm:"primaryKey"`
+	Score *int
+}
+
 func TestPaginationQueryBuilder(t *testing.T) {
 	sortByName, _ := tkValueObject.NewPaginationSortBy("name")
 	lastSeenId5, _ := tkValueObject.NewPaginationLastSeenId("5")
@@ -84,9 +90,9 @@ func TestPaginationQueryBuilder(t *testing.T) {
 			name:             "SortByNameAsc",
 			primaryKeyColumn: "id",
 			requestPagination: tkDto.Pagination{
-				PageNumber:   0,
-				ItemsPerPage: 3,
-				SortBy:       &sortByName,
+				PageNumber:    0,
+				ItemsPerPage:  3,
+				SortBy:        &sortByName,
 				SortDirection: &tkValueObject.PaginationSortDirectionAsc,
 			},
 			expectedItemsTotal:    10,
@@ -98,9 +104,9 @@ func TestPaginationQueryBuilder(t *testing.T) {
 			name:             "SortByNameDesc",
 			primaryKeyColumn: "id",
 			requestPagination: tkDto.Pagination{
-				PageNumber:   0,
-				ItemsPerPage: 3,
-				SortBy:       &sortByName,
+				PageNumber:    0,
+				ItemsPerPage:  3,
+				SortBy:        &sortByName,
 				SortDirection: &tkValueObject.PaginationSortDirectionDesc,
 			},
 			expectedItemsTotal:    10,
@@ -211,6 +217,47 @@ func TestPaginationQueryBuilder(t *testing.T) {
 		})
 	}
 
+	t.Run("ShouldSkipTotals", func(t *testing.T) {
+		dbSvc := setupTestDb(t)
+		countQueriesCount := 0
+		err := dbSvc.Callback().Query().Before("gorm:query").Register(
+			"test:count_queries", func(dbTx *gorm.DB) {
+				if _, isCountDest := dbTx.Statement.Dest.(*int64); isCountDest {
+					countQueriesCount++
+				}
+			},
+		)
+		if err != nil {
+			t.Fatalf("RegisterCallbackFailed: %v", err)
+		}
+
+		paginatedQuery, responsePagination, err := PaginationQueryBuilder(
+			dbSvc.Model(&testPaginationModel{}),
+			tkDto.Pagination{
+				ItemsPerPage: 3, LastSeenId: &lastSeenId5, ShouldSkipTotals: true,
+			},
+			"id",
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+		if countQueriesCount != 0 {
+			t.Errorf("UnexpectedCountQueries: %d", countQueriesCount)
+		}
+		if responsePagination.ItemsTotal != nil || responsePagination.PagesTotal != nil {
+			t.Errorf("UnexpectedTotals: %v %v", responsePagination.ItemsTotal, responsePagination.PagesTotal)
+		}
+
+		var queryResults []testPaginationModel
+		err = paginatedQuery.Find(&queryResults).Error
+		if err != nil {
+			t.Fatalf("ExecuteQueryFailed: %v", err)
+		}
+		if len(queryResults) != 3 || queryResults[0].Name != "item6" {
+			t.Errorf("UnexpectedResults: %v", queryResults)
+		}
+	})
+
 	t.Run("LastSeenIdWithCustomPrimaryKey", func(t *testing.T) {
 		dbSvc := setupTestDb(t)
 		dbQuery := dbSvc.Model(&testPaginationCustomPkModel{})
@@ -257,6 +304,232 @@ func TestPaginationQueryBuilder(t *testing.T) {
 	})
 }
 
+func TestPaginationQueryBuilderCursor(t *testing.T) {
+	setupCursorTestDb := func(t *testing.T) *gorm.DB {
+		t.Helper()
+		dbSvc := setupTestDb(t)
+		err := dbSvc.AutoMigrate(&testPaginationCursorModel{})
+		if err != nil {
+			t.Fatalf("MigrateTestDbFailed: %v", err)
+		}
+
+		// Ties and NULLs are intentional, so the primary key tiebreaker is exercised.
+		scoreById := map[uint64]*int{
+			1: new(10), 2: nil, 3: new(20), 4: new(10), 5: nil, 6: new(30), 7: new(20),
+		}
+		for itemId := uint64(1); itemId <= 7; itemId++ {
+			err = dbSvc.Create(
+				&testPaginationCursorModel{ID: itemId, Score: scoreById[itemId]},
+			).Error
+			if err != nil {
+				t.Fatalf("InsertTestDataFailed: %v", err)
+			}
+		}
+		return dbSvc
+	}
+
+	readPageWithSettings := func(
+		t *testing.T, dbSvc *gorm.DB, requestPagination tkDto.Pagination,
+		builderSettings PaginationQueryBuilderSettings,
+	) ([]testPaginationCursorModel, tkDto.Pagination) {
+		t.Helper()
+		paginatedQuery, responsePagination, err := PaginationQueryBuilderWithSettings(
+			dbSvc.Model(&testPaginationCursorModel{}), requestPagination, builderSettings,
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+
+		pageModels := []testPaginationCursorModel{}
+		err = paginatedQuery.Find(&pageModels).Error
+		if err != nil {
+			t.Fatalf("ExecuteQueryFailed: %v", err)
+		}
+
+		responsePagination.NextCursor, err = PaginationNextCursorBuilder(
+			paginatedQuery, responsePagination, builderSettings, pageModels,
+		)
+		if err != nil {
+			t.Fatalf("NextCursorBuilderFailed: %v", err)
+		}
+		return pageModels, responsePagination
+	}
+
+	readPage := func(
+		t *testing.T, dbSvc *gorm.DB, requestPagination tkDto.Pagination,
+	) ([]testPaginationCursorModel, tkDto.Pagination) {
+		t.Helper()
+		return readPageWithSettings(
+			t, dbSvc, requestPagination, PaginationQueryBuilderSettings{PrimaryKeyColumn: "id"},
+		)
+	}
+
+	walkPages := func(
+		t *testing.T, dbSvc *gorm.DB, requestPagination tkDto.Pagination,
+		builderSettings PaginationQueryBuilderSettings,
+	) []uint64 {
+		t.Helper()
+		// Only the first request carries the sort, the following ones rely on the
+		// cursor alone, as a client echoing back the nextCursor would do.
+		walkedIds := []uint64{}
+		for range 10 {
+			pageModels, responsePagination := readPageWithSettings(
+				t, dbSvc, requestPagination, builderSettings,
+			)
+			for _, pageModel := range pageModels {
+				walkedIds = append(walkedIds, pageModel.ID)
+			}
+			if responsePagination.NextCursor == nil {
+				break
+			}
+			requestPagination = tkDto.Pagination{
+				ItemsPerPage: requestPagination.ItemsPerPage,
+				Cursor:       responsePagination.NextCursor,
+			}
+		}
+		return walkedIds
+	}
+
+	sortByScore, _ := tkValueObject.NewPaginationSortBy("score")
+	sortById, _ := tkValueObject.NewPaginationSortBy("id")
+
+	t.Run("WalksEveryPage", func(t *testing.T) {
+		testCaseStructs := []struct {
+			sortBy        tkValueObject.PaginationSortBy
+			sortDirection tkValueObject.PaginationSortDirection
+			expectedIds   []uint64
+		}{
+			{sortByScore, tkValueObject.PaginationSortDirectionAsc, []uint64{2, 5, 1, 4, 3, 7, 6}},
+			{sortByScore, tkValueObject.PaginationSortDirectionDesc, []uint64{6, 7, 3, 4, 1, 5, 2}},
+			{sortById, tkValueObject.PaginationSortDirectionDesc, []uint64{7, 6, 5, 4, 3, 2, 1}},
+		}
+
+		dbSvc := setupCursorTestDb(t)
+		for _, testCase := range testCaseStructs {
+			walkedIds := walkPages(t, dbSvc, tkDto.Pagination{
+				ItemsPerPage:  2,
+				SortBy:        &testCase.sortBy,
+				SortDirection: &testCase.sortDirection,
+			}, PaginationQueryBuilderSettings{PrimaryKeyColumn: "id"})
+
+			if fmt.Sprint(walkedIds) != fmt.Sprint(testCase.expectedIds) {
+				t.Errorf(
+					"WalkedIdsMismatch: expected %v, got %v [%s %s]",
+					testCase.expectedIds, walkedIds, testCase.sortBy, testCase.sortDirection,
+				)
+			}
+		}
+	})
+
+	t.Run("WalksEveryPageWithNullsOrder", func(t *testing.T) {
+		testCaseStructs := []struct {
+			nullsOrder    PaginationNullsOrder
+			sortDirection tkValueObject.PaginationSortDirection
+			expectedIds   []uint64
+		}{
+			{PaginationNullsOrderLast, tkValueObject.PaginationSortDirectionAsc, []uint64{1, 4, 3, 7, 6, 2, 5}},
+			{PaginationNullsOrderFirst, tkValueObject.PaginationSortDirectionDesc, []uint64{5, 2, 6, 7, 3, 4, 1}},
+		}
+
+		dbSvc := setupCursorTestDb(t)
+		for _, testCase := range testCaseStructs {
+			walkedIds := walkPages(t, dbSvc, tkDto.Pagination{
+				ItemsPerPage:  2,
+				SortBy:        &sortByScore,
+				SortDirection: &testCase.sortDirection,
+			}, PaginationQueryBuilderSettings{
+				PrimaryKeyColumn: "test_pagination_cursor_models.id",
+				SortableFields: PaginationSortableFields{
+					"score": {
+						ColumnExpression: "test_pagination_cursor_models.score",
+						NullsOrder:       testCase.nullsOrder,
+					},
+				},
+			})
+
+			if fmt.Sprint(walkedIds) != fmt.Sprint(testCase.expectedIds) {
+				t.Errorf(
+					"WalkedIdsMismatch: expected %v, got %v [%s %s]",
+					testCase.expectedIds, walkedIds, testCase.nullsOrder, testCase.sortDirection,
+				)
+			}
+		}
+	})
+
+	t.Run("RejectsSortByOutsideAllowlist", func(t *testing.T) {
+		dbSvc := setupCursorTestDb(t)
+		_, _, err := PaginationQueryBuilderWithSettings(
+			dbSvc.Model(&testPaginationCursorModel{}),
+			tkDto.Pagination{ItemsPerPage: 2, SortBy: &sortById},
+			PaginationQueryBuilderSettings{SortableFields: PaginationSortableFields{
+				"score": {ColumnExpression: "score"},
+			}},
+		)
+		notAllowedErr := &PaginationSortByNotAllowedError{}
+		if !errors.As(err, &notAllowedErr) || notAllowedErr.SortBy != "id" {
+			t.Errorf("MissingExpectedError: PaginationSortByNotAllowed (got %v)", err)
+		}
+	})
+
+	t.Run("NoNextCursorWhenUnsorted", func(t *testing.T) {
+		dbSvc := setupCursorTestDb(t)
+		_, responsePagination := readPage(t, dbSvc, tkDto.Pagination{ItemsPerPage: 2})
+		if responsePagination.NextCursor != nil {
+			t.Errorf("UnexpectedNextCursor: %s", responsePagination.NextCursor.String())
+		}
+	})
+
+	t.Run("RejectsInvalidCursors", func(t *testing.T) {
+		dbSvc := setupCursorTestDb(t)
+		_, responsePagination := readPage(t, dbSvc, tkDto.Pagination{
+			ItemsPerPage:  2,
+			SortBy:        &sortByScore,
+			SortDirection: &tkValueObject.PaginationSortDirectionAsc,
+		})
+
+		injectedCursor, _ := paginationCursorPayload{
+			SortField:       "score; DROP TABLE test_pagination_cursor_models",
+			SortDirection:   "asc",
+			PrimaryKeyValue: paginationCursorValue{Kind: paginationCursorValueKindUint, Value: "1"},
+		}.encode()
+
+		testCaseStructs := []struct {
+			requestPagination tkDto.Pagination
+			expectedError     string
+		}{
+			{
+				tkDto.Pagination{
+					ItemsPerPage: 2,
+					SortBy:       &sortById,
+					Cursor:       responsePagination.NextCursor,
+				},
+				errPaginationCursorSortMismatch,
+			},
+			{
+				tkDto.Pagination{
+					ItemsPerPage:  2,
+					SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+					Cursor:        responsePagination.NextCursor,
+				},
+				errPaginationCursorSortMismatch,
+			},
+			{
+				tkDto.Pagination{ItemsPerPage: 2, Cursor: &injectedCursor},
+				"InvalidPaginationCursor",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			_, _, err := PaginationQueryBuilder(
+				dbSvc.Model(&testPaginationCursorModel{}), testCase.requestPagination, "id",
+			)
+			if err == nil || err.Error() != testCase.expectedError {
+				t.Errorf("MissingExpectedError: %s (got %v)", testCase.expectedError, err)
+			}
+		}
+	})
+}
+
 func setupTestDb(t *testing.T) *gorm.DB {
 	t.Helper()
 	dbSvc, err := gorm.Open(
@@ -0,0 +1,301 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"math"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+// PaginationSliceBuilderSettings is the in-memory counterpart of the
+// PaginationQueryBuilderSettings. SortableFields keeps its role of SortBy allowlist
+// (and NullsOrder source), while SortValueReaders returns, for each of its field names,
+// the item value sorted by. A nil pointer value is a NULL.
+type PaginationSliceBuilderSettings[ItemType any] struct {
+	SortableFields   PaginationSortableFields
+	SortValueReaders map[string]func(item ItemType) any
+	PrimaryKeyReader func(item ItemType) uint64
+}
+
+type paginationSliceEntry[ItemType any] struct {
+	item            ItemType
+	sortValue       paginationCursorValue
+	primaryKeyValue uint64
+}
+
+// paginationCursorValuesCompare compares two non-null values of the same kind; values
+// of different kinds are ordered by kind name, which never happens for a single field.
+func paginationCursorValuesCompare(valueA, valueB paginationCursorValue) int {
+	if valueA.Kind != valueB.Kind {
+		return strings.Compare(valueA.Kind, valueB.Kind)
+	}
+
+	bindValueA, errA := valueA.ReadBindValue()
+	bindValueB, errB := valueB.ReadBindValue()
+	if errA != nil || errB != nil {
+		return strings.Compare(valueA.Value, valueB.Value)
+	}
+
+	switch typedValueA := bindValueA.(type) {
+	case int64:
+		return paginationOrderedCompare(typedValueA, bindValueB.(int64))
+	case uint64:
+		return paginationOrderedCompare(typedValueA, bindValueB.(uint64))
+	case float64:
+		return paginationOrderedCompare(typedValueA, bindValueB.(float64))
+	case bool:
+		typedValueB := bindValueB.(bool)
+		switch {
+		case typedValueA == typedValueB:
+			return 0
+		case typedValueB:
+			return -1
+		default:
+			return 1
+		}
+	case time.Time:
+		return typedValueA.Compare(bindValueB.(time.Time))
+	default:
+		return strings.Compare(valueA.Value, valueB.Value)
+	}
+}
+
+func paginationOrderedCompare[OrderedType int64 | uint64 | float64](valueA, valueB OrderedType) int {
+	switch {
+	case valueA < valueB:
+		return -1
+	case valueA > valueB:
+		return 1
+	default:
+		return 0
+	}
+}
+
+// paginationSliceEntriesCompare orders the entries as the ORDER BY clause of the
+// PaginationQueryBuilder would: sort value, then primary key, both in the sort
+// direction. NULLs are placed per the field NullsOrder or, by default, as the smallest
+// values (like SQLite does).
+func paginationSliceEntriesCompare[ItemType any](
+	sortField paginationResolvedSortField,
+	sortDirection tkValueObject.PaginationSortDirection,
+	entryA, entryB paginationSliceEntry[ItemType],
+) int {
+	isDescending := sortDirection == tkValueObject.PaginationSortDirectionDesc
+	isNullsFirst := !isDescending
+	switch sortField.NullsOrder {
+	case PaginationNullsOrderFirst:
+		isNullsFirst = true
+	case PaginationNullsOrderLast:
+		isNullsFirst = false
+	}
+
+	isNullA := entryA.sortValue.Kind == paginationCursorValueKindNull
+	isNullB := entryB.sortValue.Kind == paginationCursorValueKindNull
+	comparisonResult := 0
+	switch {
+	case isNullA && isNullB:
+	case isNullA || isNullB:
+		comparisonResult = 1
+		if isNullA == isNullsFirst {
+			comparisonResult = -1
+		}
+		return comparisonResult
+	default:
+		comparisonResult = paginationCursorValuesCompare(entryA.sortValue, entryB.sortValue)
+		if isDescending {
+			comparisonResult = -comparisonResult
+		}
+	}
+	if comparisonResult != 0 {
+		return comparisonResult
+	}
+
+	comparisonResult = paginationOrderedCompare(entryA.primaryKeyValue, entryB.primaryKeyValue)
+	if isDescending {
+		comparisonResult = -comparisonResult
+	}
+	return comparisonResult
+}
+
+// PaginationSliceBuilder applies the pagination to the (already filtered) items the
+// same way PaginationQueryBuilderWithSettings applies it to a query, including the
+// cursors, so in-memory repositories behave like the database ones.
+func PaginationSliceBuilder[ItemType any](
+	items []ItemType,
+	requestPagination tkDto.Pagination,
+	settings PaginationSliceBuilderSettings[ItemType],
+) (pageItems []ItemType, responsePagination tkDto.Pagination, err error) {
+	if requestPagination.ItemsPerPage == 0 {
+		return pageItems, responsePagination, errors.New(errItemsPerPageCannotBeZero)
+	}
+	if settings.PrimaryKeyReader == nil {
+		return pageItems, responsePagination, errors.New("PrimaryKeyReaderRequired")
+	}
+
+	var sortField *paginationResolvedSortField
+	if requestPagination.SortBy != nil {
+		resolvedSortField, err := settings.SortableFields.resolve(*requestPagination.SortBy)
+		if err != nil {
+			return pageItems, responsePagination, err
+		}
+		sortField = &resolvedSortField
+	}
+	sortDirection := tkValueObject.PaginationSortDirectionAsc
+	if requestPagination.SortDirection != nil {
+		sortDirection = *requestPagination.SortDirection
+	}
+
+	var cursorPayload *paginationCursorPayload
+	if requestPagination.Cursor != nil {
+		decodedCursorPayload, err := decodePaginationCursor(*requestPagination.Cursor)
+		if err != nil {
+			return pageItems, responsePagination, err
+		}
+
+		cursorSortField, cursorSortDirection, err := paginationCursorSortResolver(
+			requestPagination, settings.SortableFields, decodedCursorPayload,
+		)
+		if err != nil {
+			return pageItems, responsePagination, err
+		}
+		sortField, sortDirection = &cursorSortField, cursorSortDirection
+		cursorPayload = &decodedCursorPayload
+	}
+
+	var sortValueReader func(item ItemType) any
+	if sortField != nil {
+		sortValueReader = settings.SortValueReaders[sortField.FieldName]
+		if sortValueReader == nil {
+			return pageItems, responsePagination, &PaginationSortByNotAllowedError{
+				SortBy:            sortField.FieldName,
+				AllowedFieldNames: settings.SortableFields.readFieldNames(),
+			}
+		}
+	}
+
+	entries := make([]paginationSliceEntry[ItemType], 0, len(items))
+	for _, item := range items {
+		entry := paginationSliceEntry[ItemType]{
+			item:            item,
+			sortValue:       paginationCursorValue{Kind: paginationCursorValueKindNull},
+			primaryKeyValue: settings.PrimaryKeyReader(item),
+		}
+		if sortValueReader != nil {
+			entry.sortValue, err = newPaginationCursorValue(sortValueReader(item))
+			if err != nil {
+				return pageItems, responsePagination, err
+			}
+		}
+		entries = append(entries, entry)
+	}
+	itemsTotal := len(entries)
+
+	switch {
+	case cursorPayload != nil:
+		cursorPrimaryKeyValue, err := cursorPayload.PrimaryKeyValue.ReadBindValue()
+		if err != nil {
+			return pageItems, responsePagination, errors.New("InvalidPaginationCursor")
+		}
+		cursorEntry := paginationSliceEntry[ItemType]{sortValue: cursorPayload.SortValue}
+		switch typedPrimaryKeyValue := cursorPrimaryKeyValue.(type) {
+		case uint64:
+			cursorEntry.primaryKeyValue = typedPrimaryKeyValue
+		case int64:
+			cursorEntry.primaryKeyValue = uint64(max(typedPrimaryKeyValue, 0))
+		default:
+			return pageItems, responsePagination, errors.New("InvalidPaginationCursor")
+		}
+
+		entries = slices.DeleteFunc(entries, func(entry paginationSliceEntry[ItemType]) bool {
+			return paginationSliceEntriesCompare(*sortField, sortDirection, cursorEntry, entry) >= 0
+		})
+
+	case requestPagination.LastSeenId != nil:
+		lastSeenId, err := strconv.ParseUint(requestPagination.LastSeenId.String(), 10, 64)
+		if err != nil {
+			return pageItems, responsePagination, errors.New("InvalidPaginationLastSeenId")
+		}
+		entries = slices.DeleteFunc(entries, func(entry paginationSliceEntry[ItemType]) bool {
+			if sortDirection == tkValueObject.PaginationSortDirectionDesc {
+				return entry.primaryKeyValue >= lastSeenId
+			}
+			return entry.primaryKeyValue <= lastSeenId
+		})
+		if sortField == nil {
+			// Every sort value is NULL, so the entries end up sorted by primary key.
+			sortField = &paginationResolvedSortField{}
+		}
+	}
+
+	if sortField != nil {
+		slices.SortStableFunc(entries, func(entryA, entryB paginationSliceEntry[ItemType]) int {
+			return paginationSliceEntriesCompare(*sortField, sortDirection, entryA, entryB)
+		})
+	}
+
+	if cursorPayload == nil && requestPagination.LastSeenId == nil &&
+		requestPagination.PageNumber > 0 {
+		offset := int(requestPagination.PageNumber) * int(requestPagination.ItemsPerPage)
+		entries = entries[min(offset, len(entries)):]
+	}
+	entries = entries[:min(int(requestPagination.ItemsPerPage), len(entries))]
+
+	pageItems = make([]ItemType, 0, len(entries))
+	for _, entry := range entries {
+		pageItems = append(pageItems, entry.item)
+	}
+
+	responsePagination = tkDto.Pagination{
+		PageNumber:    requestPagination.PageNumber,
+		ItemsPerPage:  requestPagination.ItemsPerPage,
+		SortBy:        requestPagination.SortBy,
+		SortDirection: requestPagination.SortDirection,
+		Cursor:        requestPagination.Cursor,
+	}
+	if cursorPayload != nil {
+		sortByVo, err := tkValueObject.NewPaginationSortBy(sortField.FieldName)
+		if err != nil {
+			return pageItems, responsePagination, errors.New("InvalidPaginationCursor")
+		}
+		responsePagination.SortBy = &sortByVo
+		responsePagination.SortDirection = &sortDirection
+	}
+
+	if !requestPagination.ShouldSkipTotals {
+		itemsTotalUint := uint64(itemsTotal)
+		pagesTotal := uint32(
+			math.Ceil(float64(itemsTotal) / float64(requestPagination.ItemsPerPage)),
+		)
+		responsePagination.PagesTotal = &pagesTotal
+		responsePagination.ItemsTotal = &itemsTotalUint
+	}
+
+	isNextCursorAvailable := responsePagination.SortBy != nil && len(entries) > 0 &&
+		len(entries) == int(requestPagination.ItemsPerPage)
+	if !isNextCursorAvailable {
+		return pageItems, responsePagination, nil
+	}
+
+	lastEntry := entries[len(entries)-1]
+	primaryKeyCursorValue, err := newPaginationCursorValue(lastEntry.primaryKeyValue)
+	if err != nil {
+		return pageItems, responsePagination, err
+	}
+	nextCursor, err := paginationCursorPayload{
+		SortField:       sortField.FieldName,
+		SortDirection:   sortDirection.String(),
+		SortValue:       lastEntry.sortValue,
+		PrimaryKeyValue: primaryKeyCursorValue,
+	}.encode()
+	if err != nil {
+		return pageItems, responsePagination, err
+	}
+	responsePagination.NextCursor = &nextCursor
+
+	return pageItems, responsePagination, nil
+}
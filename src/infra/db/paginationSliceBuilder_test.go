@@ -0,0 +1,184 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestPaginationSliceBuilder(t *testing.T) {
+	// Same items as the TestPaginationQueryBuilderCursor ones, so both builders are held
+	// to the same orders.
+	scoreById := map[uint64]*int{
+		1: new(10), 2: nil, 3: new(20), 4: new(10), 5: nil, 6: new(30), 7: new(20),
+	}
+	testItems := []testPaginationCursorModel{}
+	for itemId := uint64(1); itemId <= 7; itemId++ {
+		testItems = append(
+			testItems, testPaginationCursorModel{ID: itemId, Score: scoreById[itemId]},
+		)
+	}
+
+	builderSettingsFactory := func(
+		nullsOrder PaginationNullsOrder,
+	) PaginationSliceBuilderSettings[testPaginationCursorModel] {
+		return PaginationSliceBuilderSettings[testPaginationCursorModel]{
+			SortableFields: PaginationSortableFields{
+				"id":    {ColumnExpression: "id"},
+				"score": {ColumnExpression: "score", NullsOrder: nullsOrder},
+			},
+			SortValueReaders: map[string]func(testPaginationCursorModel) any{
+				"id":    func(item testPaginationCursorModel) any { return item.ID },
+				"score": func(item testPaginationCursorModel) any { return item.Score },
+			},
+			PrimaryKeyReader: func(item testPaginationCursorModel) uint64 { return item.ID },
+		}
+	}
+
+	readPageIds := func(
+		t *testing.T, requestPagination tkDto.Pagination,
+		builderSettings PaginationSliceBuilderSettings[testPaginationCursorModel],
+	) ([]uint64, tkDto.Pagination) {
+		t.Helper()
+		pageItems, responsePagination, err := PaginationSliceBuilder(
+			testItems, requestPagination, builderSettings,
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+
+		pageIds := []uint64{}
+		for _, pageItem := range pageItems {
+			pageIds = append(pageIds, pageItem.ID)
+		}
+		return pageIds, responsePagination
+	}
+
+	walkPages := func(
+		t *testing.T, requestPagination tkDto.Pagination,
+		builderSettings PaginationSliceBuilderSettings[testPaginationCursorModel],
+	) []uint64 {
+		t.Helper()
+		walkedIds := []uint64{}
+		for range 10 {
+			pageIds, responsePagination := readPageIds(t, requestPagination, builderSettings)
+			walkedIds = append(walkedIds, pageIds...)
+			if responsePagination.NextCursor == nil {
+				break
+			}
+			requestPagination = tkDto.Pagination{
+				ItemsPerPage: requestPagination.ItemsPerPage,
+				Cursor:       responsePagination.NextCursor,
+			}
+		}
+		return walkedIds
+	}
+
+	sortByScore, _ := tkValueObject.NewPaginationSortBy("score")
+	sortById, _ := tkValueObject.NewPaginationSortBy("id")
+
+	t.Run("WalksEveryPage", func(t *testing.T) {
+		testCaseStructs := []struct {
+			sortBy        tkValueObject.PaginationSortBy
+			nullsOrder    PaginationNullsOrder
+			sortDirection tkValueObject.PaginationSortDirection
+			expectedIds   []uint64
+		}{
+			{sortByScore, PaginationNullsOrderDefault, tkValueObject.PaginationSortDirectionAsc, []uint64{2, 5, 1, 4, 3, 7, 6}},
+			{sortByScore, PaginationNullsOrderDefault, tkValueObject.PaginationSortDirectionDesc, []uint64{6, 7, 3, 4, 1, 5, 2}},
+			{sortById, PaginationNullsOrderDefault, tkValueObject.PaginationSortDirectionDesc, []uint64{7, 6, 5, 4, 3, 2, 1}},
+			{sortByScore, PaginationNullsOrderLast, tkValueObject.PaginationSortDirectionAsc, []uint64{1, 4, 3, 7, 6, 2, 5}},
+			{sortByScore, PaginationNullsOrderFirst, tkValueObject.PaginationSortDirectionDesc, []uint64{5, 2, 6, 7, 3, 4, 1}},
+		}
+
+		for _, testCase := range testCaseStructs {
+			walkedIds := walkPages(t, tkDto.Pagination{
+				ItemsPerPage:  2,
+				SortBy:        &testCase.sortBy,
+				SortDirection: &testCase.sortDirection,
+			}, builderSettingsFactory(testCase.nullsOrder))
+
+			if fmt.Sprint(walkedIds) != fmt.Sprint(testCase.expectedIds) {
+				t.Errorf(
+					"WalkedIdsMismatch: expected %v, got %v [%s %s %s]",
+					testCase.expectedIds, walkedIds, testCase.sortBy,
+					testCase.nullsOrder, testCase.sortDirection,
+				)
+			}
+		}
+	})
+
+	t.Run("PageNumberAndLastSeenId", func(t *testing.T) {
+		lastSeenId5, _ := tkValueObject.NewPaginationLastSeenId("5")
+
+		testCaseStructs := []struct {
+			requestPagination  tkDto.Pagination
+			expectedIds        string
+			expectedPagesTotal uint32
+		}{
+			{tkDto.Pagination{ItemsPerPage: 3}, "[1 2 3]", 3},
+			{tkDto.Pagination{PageNumber: 2, ItemsPerPage: 3}, "[7]", 3},
+			{tkDto.Pagination{PageNumber: 5, ItemsPerPage: 3}, "[]", 3},
+			{tkDto.Pagination{ItemsPerPage: 5, LastSeenId: &lastSeenId5}, "[6 7]", 2},
+			{
+				tkDto.Pagination{
+					ItemsPerPage:  5,
+					LastSeenId:    &lastSeenId5,
+					SortDirection: &tkValueObject.PaginationSortDirectionDesc,
+				},
+				"[4 3 2 1]", 2,
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			pageIds, responsePagination := readPageIds(
+				t, testCase.requestPagination, builderSettingsFactory(PaginationNullsOrderDefault),
+			)
+			if fmt.Sprint(pageIds) != testCase.expectedIds {
+				t.Errorf(
+					"PageIdsMismatch: expected %s, got %v [%+v]",
+					testCase.expectedIds, pageIds, testCase.requestPagination,
+				)
+			}
+			if *responsePagination.ItemsTotal != 7 ||
+				*responsePagination.PagesTotal != testCase.expectedPagesTotal {
+				t.Errorf(
+					"TotalsMismatch: expected 7 items and %d pages, got %d and %d",
+					testCase.expectedPagesTotal, *responsePagination.ItemsTotal,
+					*responsePagination.PagesTotal,
+				)
+			}
+		}
+	})
+
+	t.Run("RejectsInvalidRequests", func(t *testing.T) {
+		builderSettings := builderSettingsFactory(PaginationNullsOrderDefault)
+
+		_, _, err := PaginationSliceBuilder(testItems, tkDto.Pagination{}, builderSettings)
+		if err == nil || err.Error() != errItemsPerPageCannotBeZero {
+			t.Errorf("MissingExpectedError: %s (got %v)", errItemsPerPageCannotBeZero, err)
+		}
+
+		sortByName, _ := tkValueObject.NewPaginationSortBy("name")
+		_, _, err = PaginationSliceBuilder(
+			testItems, tkDto.Pagination{ItemsPerPage: 2, SortBy: &sortByName}, builderSettings,
+		)
+		notAllowedErr := &PaginationSortByNotAllowedError{}
+		if !errors.As(err, &notAllowedErr) || notAllowedErr.SortBy != "name" {
+			t.Errorf("MissingExpectedError: PaginationSortByNotAllowed (got %v)", err)
+		}
+
+		_, responsePagination := readPageIds(t, tkDto.Pagination{
+			ItemsPerPage: 2, SortBy: &sortByScore,
+		}, builderSettings)
+		_, _, err = PaginationSliceBuilder(testItems, tkDto.Pagination{
+			ItemsPerPage: 2, SortBy: &sortById, Cursor: responsePagination.NextCursor,
+		}, builderSettings)
+		if err == nil || err.Error() != errPaginationCursorSortMismatch {
+			t.Errorf("MissingExpectedError: %s (got %v)", errPaginationCursorSortMismatch, err)
+		}
+	})
+}
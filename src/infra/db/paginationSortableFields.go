@@ -0,0 +1,113 @@
+package tkInfraDb
+
+import (
+	"regexp"
+	"sort"
+	"strings"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/iancoleman/strcase"
+)
+
+type PaginationNullsOrder string
+
+const (
+	// PaginationNullsOrderDefault keeps the database ordering (see
+	// SqlDialect.IsNullsFirstByDefault).
+	PaginationNullsOrderDefault PaginationNullsOrder = ""
+	PaginationNullsOrderFirst   PaginationNullsOrder = "first"
+	PaginationNullsOrderLast    PaginationNullsOrder = "last"
+)
+
+// PaginationSortableField.ColumnExpression is the (preferably table qualified) column
+// used in the ORDER BY clause. Keyset cursors require it to be a plain column, since
+// the value of the last item is read from the model field of the same name.
+type PaginationSortableField struct {
+	ColumnExpression string
+	NullsOrder       PaginationNullsOrder
+}
+
+// PaginationSortableFields maps the API field names (e.g. "createdAt") a repository
+// allows sorting by to their columns. SortBy values are matched regardless of casing
+// style, so "created_at" and "CreatedAt" also match "createdAt".
+type PaginationSortableFields map[string]PaginationSortableField
+
+// PaginationSortByNotAllowedError is returned when the SortBy isn't one of the sortable
+// fields registered by the repository.
+type PaginationSortByNotAllowedError struct {
+	SortBy            string   `json:"sortBy"`
+	AllowedFieldNames []string `json:"allowedFieldNames"`
+}
+
+func (e *PaginationSortByNotAllowedError) Error() string {
+	return "PaginationSortByNotAllowed: " + e.SortBy
+}
+
+// Without an allowlist, the snake cased SortBy is used as the column, so it must be a
+// plain identifier.
+var paginationLegacySortColumnRegex = regexp.MustCompile(`^[a-z\d\_]{1,64}$`)
+
+type paginationResolvedSortField struct {
+	FieldName        string
+	ColumnExpression string
+	NullsOrder       PaginationNullsOrder
+}
+
+// IsNullsFirst reports where NULLs end up for the sort direction, so keyset predicates
+// agree with the ORDER BY clause.
+func (sortField paginationResolvedSortField) IsNullsFirst(
+	sqlDialect SqlDialect,
+	sortDirection tkValueObject.PaginationSortDirection,
+) bool {
+	switch sortField.NullsOrder {
+	case PaginationNullsOrderFirst:
+		return true
+	case PaginationNullsOrderLast:
+		return false
+	default:
+		return sqlDialect.IsNullsFirstByDefault(
+			sortDirection == tkValueObject.PaginationSortDirectionDesc,
+		)
+	}
+}
+
+func (sortableFields PaginationSortableFields) readFieldNames() []string {
+	fieldNames := make([]string, 0, len(sortableFields))
+	for fieldName := range sortableFields {
+		fieldNames = append(fieldNames, fieldName)
+	}
+	sort.Strings(fieldNames)
+	return fieldNames
+}
+
+// resolve maps the SortBy to its sortable field. An empty allowlist falls back to the
+// snake cased SortBy as column, as long as it's a plain identifier.
+func (sortableFields PaginationSortableFields) resolve(
+	sortBy tkValueObject.PaginationSortBy,
+) (resolvedField paginationResolvedSortField, err error) {
+	if len(sortableFields) == 0 {
+		sortColumn := strcase.ToSnake(strings.ToLower(sortBy.String()))
+		if !paginationLegacySortColumnRegex.MatchString(sortColumn) {
+			return resolvedField, &PaginationSortByNotAllowedError{SortBy: sortBy.String()}
+		}
+		return paginationResolvedSortField{
+			FieldName: sortColumn, ColumnExpression: sortColumn,
+		}, nil
+	}
+
+	normalizedSortBy := strcase.ToLowerCamel(sortBy.String())
+	for fieldName, sortableField := range sortableFields {
+		if strcase.ToLowerCamel(fieldName) != normalizedSortBy {
+			continue
+		}
+		return paginationResolvedSortField{
+			FieldName:        fieldName,
+			ColumnExpression: sortableField.ColumnExpression,
+			NullsOrder:       sortableField.NullsOrder,
+		}, nil
+	}
+
+	return resolvedField, &PaginationSortByNotAllowedError{
+		SortBy: sortBy.String(), AllowedFieldNames: sortableFields.readFieldNames(),
+	}
+}
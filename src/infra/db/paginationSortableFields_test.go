@@ -0,0 +1,90 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"testing"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestPaginationSortableFieldsResolve(t *testing.T) {
+	t.Run("Allowlist", func(t *testing.T) {
+		sortableFields := PaginationSortableFields{
+			"createdAt": {ColumnExpression: "items.created_at"},
+			"name":      {ColumnExpression: "items.name", NullsOrder: PaginationNullsOrderLast},
+		}
+
+		testCaseStructs := []struct {
+			sortBy                   string
+			expectedColumnExpression string
+			expectError              bool
+		}{
+			{"createdAt", "items.created_at", false},
+			{"created_at", "items.created_at", false},
+			{"CreatedAt", "items.created_at", false},
+			{"name", "items.name", false},
+			{"id", "", true},
+			{"items.name", "", true},
+			{"password", "", true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sortByVo, _ := tkValueObject.NewPaginationSortBy(testCase.sortBy)
+			resolvedField, err := sortableFields.resolve(sortByVo)
+			if testCase.expectError {
+				notAllowedErr := &PaginationSortByNotAllowedError{}
+				if !errors.As(err, &notAllowedErr) {
+					t.Errorf("MissingExpectedError: [%s]", testCase.sortBy)
+					continue
+				}
+				if len(notAllowedErr.AllowedFieldNames) != 2 {
+					t.Errorf("AllowedFieldNamesMismatch: %v", notAllowedErr.AllowedFieldNames)
+				}
+				continue
+			}
+			if err != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.sortBy)
+				continue
+			}
+			if resolvedField.ColumnExpression != testCase.expectedColumnExpression {
+				t.Errorf(
+					"ColumnExpressionMismatch: expected %s, got %s [%s]",
+					testCase.expectedColumnExpression, resolvedField.ColumnExpression,
+					testCase.sortBy,
+				)
+			}
+		}
+	})
+
+	t.Run("WithoutAllowlist", func(t *testing.T) {
+		testCaseStructs := []struct {
+			sortBy                   string
+			expectedColumnExpression string
+			expectError              bool
+		}{
+			{"name", "name", false},
+			{"created_at", "created_at", false},
+			{"items.name", "items_name", false},
+			{"name desc", "name_desc", false},
+			{"ação", "", true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sortByVo, _ := tkValueObject.NewPaginationSortBy(testCase.sortBy)
+			resolvedField, err := PaginationSortableFields{}.resolve(sortByVo)
+			if testCase.expectError && err == nil {
+				t.Errorf("MissingExpectedError: [%s]", testCase.sortBy)
+			}
+			if !testCase.expectError && err != nil {
+				t.Errorf("UnexpectedError: '%s' [%s]", err.Error(), testCase.sortBy)
+			}
+			if resolvedField.ColumnExpression != testCase.expectedColumnExpression {
+				t.Errorf(
+					"ColumnExpressionMismatch: expected %s, got %s [%s]",
+					testCase.expectedColumnExpression, resolvedField.ColumnExpression,
+					testCase.sortBy,
+				)
+			}
+		}
+	})
+}
@@ -0,0 +1,256 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"regexp"
+	"slices"
+	"strconv"
+	"time"
+
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+)
+
+const (
+	// SchemaMigrationDefaultNamespace is used by migrations registered without namespace.
+	SchemaMigrationDefaultNamespace     string = "app"
+	errSchemaMigrationNamespaceNotValid string = "SchemaMigrationNamespaceNotValid"
+	errSchemaMigrationVersionNotValid   string = "SchemaMigrationVersionNotValid"
+	errSchemaMigrationUpNotValid        string = "SchemaMigrationUpNotValid"
+	errSchemaMigrationDuplicated        string = "SchemaMigrationDuplicated"
+	errSchemaMigrationsTableError       string = "SchemaMigrationsTableError"
+	errSchemaMigrationFailed            string = "SchemaMigrationFailed"
+)
+
+var schemaMigrationNamespaceRegex = regexp.MustCompile(`^[a-z\d][a-z\d\_\-]{0,63}$`)
+
+// SchemaMigration is a single versioned schema change. Exactly one of UpSqlStatements
+// and UpFunc must be set. Versions are ordered within their Namespace, so a consumer
+// project numbering its own migrations never collides with the toolkit ones.
+type SchemaMigration struct {
+	Namespace       string
+	Version         uint64
+	Description     string
+	UpSqlStatements []string
+	UpFunc          func(dbTx *gorm.DB) error
+}
+
+func (migration SchemaMigration) String() string {
+	return migration.Namespace + "/" + strconv.FormatUint(migration.Version, 10)
+}
+
+type SchemaMigrationStatus struct {
+	Namespace       string     `json:"namespace"`
+	Version         uint64     `json:"version"`
+	Description     string     `json:"description"`
+	IsApplied       bool       `json:"isApplied"`
+	AppliedAt       *time.Time `json:"appliedAt,omitempty"`
+	UpSqlStatements []string   `json:"upSqlStatements,omitempty"`
+}
+
+type SchemaMigratorSettings struct {
+	DbHandler  *gorm.DB
+	Migrations []SchemaMigration
+}
+
+// SchemaMigrator applies the registered migrations not yet recorded in the
+// schema_migrations table, each one in its own transaction. Namespaces run in the order
+// they first appear in the Migrations slice (so the toolkit ones, registered first, run
+// before the consumer ones) and, within a namespace, by ascending version. A version
+// lower than an already applied one is still applied once registered.
+//
+// Note that MySQL implicitly commits DDL statements, so a failed migration may be left
+// partially applied on it.
+type SchemaMigrator struct {
+	dbHandler  *gorm.DB
+	migrations []SchemaMigration
+}
+
+func NewSchemaMigrator(settings SchemaMigratorSettings) (*SchemaMigrator, error) {
+	namespacesOrder := map[string]int{}
+	migrationsByKey := map[string]struct{}{}
+	migrations := make([]SchemaMigration, 0, len(settings.Migrations))
+	for _, migration := range settings.Migrations {
+		if migration.Namespace == "" {
+			migration.Namespace = SchemaMigrationDefaultNamespace
+		}
+		if !schemaMigrationNamespaceRegex.MatchString(migration.Namespace) {
+			return nil, errors.New(errSchemaMigrationNamespaceNotValid + ": " + migration.Namespace)
+		}
+		if migration.Version == 0 {
+			return nil, errors.New(errSchemaMigrationVersionNotValid + ": " + migration.String())
+		}
+
+		isSqlSet := len(migration.UpSqlStatements) > 0
+		isFuncSet := migration.UpFunc != nil
+		if isSqlSet == isFuncSet {
+			return nil, errors.New(errSchemaMigrationUpNotValid + ": " + migration.String())
+		}
+
+		if _, alreadyExists := migrationsByKey[migration.String()]; alreadyExists {
+			return nil, errors.New(errSchemaMigrationDuplicated + ": " + migration.String())
+		}
+		migrationsByKey[migration.String()] = struct{}{}
+
+		if _, alreadyExists := namespacesOrder[migration.Namespace]; !alreadyExists {
+			namespacesOrder[migration.Namespace] = len(namespacesOrder)
+		}
+		migrations = append(migrations, migration)
+	}
+
+	slices.SortStableFunc(migrations, func(a, b SchemaMigration) int {
+		if a.Namespace != b.Namespace {
+			return namespacesOrder[a.Namespace] - namespacesOrder[b.Namespace]
+		}
+		if a.Version < b.Version {
+			return -1
+		}
+		if a.Version > b.Version {
+			return 1
+		}
+		return 0
+	})
+
+	return &SchemaMigrator{dbHandler: settings.DbHandler, migrations: migrations}, nil
+}
+
+func (migrator *SchemaMigrator) readAppliedMigrationModels() (
+	appliedModelsByKey map[string]tkInfraDbModel.SchemaMigration, err error,
+) {
+	appliedModelsByKey = map[string]tkInfraDbModel.SchemaMigration{}
+	if !migrator.dbHandler.Migrator().HasTable(&tkInfraDbModel.SchemaMigration{}) {
+		return appliedModelsByKey, nil
+	}
+
+	appliedModels := []tkInfraDbModel.SchemaMigration{}
+	err = migrator.dbHandler.Model(&tkInfraDbModel.SchemaMigration{}).
+		Find(&appliedModels).Error
+	if err != nil {
+		return appliedModelsByKey, errors.New(errSchemaMigrationsTableError + ": " + err.Error())
+	}
+
+	for _, appliedModel := range appliedModels {
+		migrationKey := SchemaMigration{
+			Namespace: appliedModel.Namespace, Version: appliedModel.Version,
+		}.String()
+		appliedModelsByKey[migrationKey] = appliedModel
+	}
+
+	return appliedModelsByKey, nil
+}
+
+// ReadStatus lists every registered migration in execution order, flagging the applied
+// ones. It never writes to the database.
+func (migrator *SchemaMigrator) ReadStatus() ([]SchemaMigrationStatus, error) {
+	appliedModelsByKey, err := migrator.readAppliedMigrationModels()
+	if err != nil {
+		return nil, err
+	}
+
+	migrationsStatus := make([]SchemaMigrationStatus, 0, len(migrator.migrations))
+	for _, migration := range migrator.migrations {
+		migrationStatus := SchemaMigrationStatus{
+			Namespace:       migration.Namespace,
+			Version:         migration.Version,
+			Description:     migration.Description,
+			UpSqlStatements: migration.UpSqlStatements,
+		}
+
+		appliedModel, isApplied := appliedModelsByKey[migration.String()]
+		if isApplied {
+			migrationStatus.IsApplied = true
+			migrationStatus.AppliedAt = &appliedModel.AppliedAt
+		}
+
+		migrationsStatus = append(migrationsStatus, migrationStatus)
+	}
+
+	return migrationsStatus, nil
+}
+
+// DryRun lists the migrations Migrate would apply, without touching the database. SQL
+// migrations carry their statements; Go ones only their description.
+func (migrator *SchemaMigrator) DryRun() ([]SchemaMigrationStatus, error) {
+	migrationsStatus, err := migrator.ReadStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	pendingMigrationsStatus := []SchemaMigrationStatus{}
+	for _, migrationStatus := range migrationsStatus {
+		if migrationStatus.IsApplied {
+			continue
+		}
+		pendingMigrationsStatus = append(pendingMigrationsStatus, migrationStatus)
+	}
+
+	return pendingMigrationsStatus, nil
+}
+
+func (migrator *SchemaMigrator) apply(migration SchemaMigration, appliedAt time.Time) error {
+	return migrator.dbHandler.Transaction(func(dbTx *gorm.DB) error {
+		if migration.UpFunc != nil {
+			err := migration.UpFunc(dbTx)
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, sqlStatement := range migration.UpSqlStatements {
+			err := dbTx.Exec(sqlStatement).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return dbTx.Create(&tkInfraDbModel.SchemaMigration{
+			Namespace:   migration.Namespace,
+			Version:     migration.Version,
+			Description: migration.Description,
+			AppliedAt:   appliedAt,
+		}).Error
+	})
+}
+
+// Migrate applies the pending migrations in order and returns the ones it applied. It
+// stops at the first failure, which is rolled back, keeping the previous ones.
+func (migrator *SchemaMigrator) Migrate() ([]SchemaMigrationStatus, error) {
+	appliedMigrationsStatus := []SchemaMigrationStatus{}
+
+	err := migrator.dbHandler.AutoMigrate(&tkInfraDbModel.SchemaMigration{})
+	if err != nil {
+		return appliedMigrationsStatus, errors.New(
+			errSchemaMigrationsTableError + ": " + err.Error(),
+		)
+	}
+
+	appliedModelsByKey, err := migrator.readAppliedMigrationModels()
+	if err != nil {
+		return appliedMigrationsStatus, err
+	}
+
+	for _, migration := range migrator.migrations {
+		if _, isApplied := appliedModelsByKey[migration.String()]; isApplied {
+			continue
+		}
+
+		appliedAt := time.Now().UTC()
+		err = migrator.apply(migration, appliedAt)
+		if err != nil {
+			return appliedMigrationsStatus, errors.New(
+				errSchemaMigrationFailed + ": " + migration.String() + ": " + err.Error(),
+			)
+		}
+
+		appliedMigrationsStatus = append(appliedMigrationsStatus, SchemaMigrationStatus{
+			Namespace:       migration.Namespace,
+			Version:         migration.Version,
+			Description:     migration.Description,
+			IsApplied:       true,
+			AppliedAt:       &appliedAt,
+			UpSqlStatements: migration.UpSqlStatements,
+		})
+	}
+
+	return appliedMigrationsStatus, nil
+}
@@ -0,0 +1,387 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"strconv"
+	"strings"
+	"testing"
+
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+)
+
+func setupTestSchemaMigratorDbHandler(t *testing.T) *gorm.DB {
+	t.Helper()
+
+	dbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+		Dsn: TrailDatabaseInMemoryDsn, ShouldSkipMigrations: true,
+	})
+	if err != nil {
+		t.Fatalf("SetupFailed: '%s'", err.Error())
+	}
+	t.Cleanup(func() {
+		rawDb, err := dbSvc.Handler.DB()
+		if err == nil {
+			_ = rawDb.Close()
+		}
+	})
+
+	return dbSvc.Handler
+}
+
+func TestNewSchemaMigrator(t *testing.T) {
+	noopUpFunc := func(dbTx *gorm.DB) error { return nil }
+
+	testCaseStructs := []struct {
+		name          string
+		migrations    []SchemaMigration
+		expectedError string
+	}{
+		{
+			"ValidMigrations",
+			[]SchemaMigration{
+				{Namespace: "tk", Version: 1, UpFunc: noopUpFunc},
+				{Version: 1, UpSqlStatements: []string{"SELECT 1"}},
+			},
+			"",
+		},
+		{
+			"NamespaceNotValid",
+			[]SchemaMigration{{Namespace: "My App", Version: 1, UpFunc: noopUpFunc}},
+			errSchemaMigrationNamespaceNotValid,
+		},
+		{
+			"VersionNotValid",
+			[]SchemaMigration{{Version: 0, UpFunc: noopUpFunc}},
+			errSchemaMigrationVersionNotValid,
+		},
+		{
+			"UpNotSet",
+			[]SchemaMigration{{Version: 1}},
+			errSchemaMigrationUpNotValid,
+		},
+		{
+			"BothUpSet",
+			[]SchemaMigration{{
+				Version: 1, UpFunc: noopUpFunc, UpSqlStatements: []string{"SELECT 1"},
+			}},
+			errSchemaMigrationUpNotValid,
+		},
+		{
+			"DuplicatedWithDefaultNamespace",
+			[]SchemaMigration{
+				{Version: 1, UpFunc: noopUpFunc},
+				{Namespace: SchemaMigrationDefaultNamespace, Version: 1, UpFunc: noopUpFunc},
+			},
+			errSchemaMigrationDuplicated,
+		},
+	}
+
+	for _, testCase := range testCaseStructs {
+		t.Run(testCase.name, func(t *testing.T) {
+			_, err := NewSchemaMigrator(SchemaMigratorSettings{Migrations: testCase.migrations})
+			if testCase.expectedError == "" {
+				if err != nil {
+					t.Errorf("UnexpectedError: '%s'", err.Error())
+				}
+				return
+			}
+			if err == nil || !strings.HasPrefix(err.Error(), testCase.expectedError) {
+				t.Errorf("MissingExpectedError: %s (got %v)", testCase.expectedError, err)
+			}
+		})
+	}
+
+	t.Run("ExecutionOrder", func(t *testing.T) {
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			Migrations: []SchemaMigration{
+				{Namespace: "tk", Version: 2, UpFunc: noopUpFunc},
+				{Version: 20261018, UpFunc: noopUpFunc},
+				{Namespace: "tk", Version: 1, UpFunc: noopUpFunc},
+				{Version: 3, UpFunc: noopUpFunc},
+			},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		migrationKeys := []string{}
+		for _, migration := range schemaMigrator.migrations {
+			migrationKeys = append(migrationKeys, migration.String())
+		}
+		expectedKeys := "tk/1,tk/2,app/3,app/20261018"
+		if strings.Join(migrationKeys, ",") != expectedKeys {
+			t.Errorf("OrderMismatch: expected %s, got %v", expectedKeys, migrationKeys)
+		}
+	})
+}
+
+func TestSchemaMigratorMigrate(t *testing.T) {
+	t.Run("StatusDryRunAndMigrate", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+
+		upFuncCallsCount := 0
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			DbHandler: dbHandler,
+			Migrations: []SchemaMigration{
+				{
+					Version: 1, Description: "CreateWidgets",
+					UpSqlStatements: []string{
+						"CREATE TABLE widgets (id INTEGER PRIMARY KEY, name TEXT)",
+						"CREATE INDEX idx_widgets_name ON widgets (name)",
+					},
+				},
+				{
+					Version: 2, Description: "RenameWidgetsName",
+					UpFunc: func(dbTx *gorm.DB) error {
+						upFuncCallsCount++
+						return dbTx.Exec("ALTER TABLE widgets RENAME COLUMN name TO label").Error
+					},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		pendingMigrations, err := schemaMigrator.DryRun()
+		if err != nil {
+			t.Fatalf("DryRunUnexpectedError: '%s'", err.Error())
+		}
+		if len(pendingMigrations) != 2 || len(pendingMigrations[0].UpSqlStatements) != 2 {
+			t.Errorf("UnexpectedPendingMigrations: %+v", pendingMigrations)
+		}
+		if dbHandler.Migrator().HasTable(&tkInfraDbModel.SchemaMigration{}) ||
+			dbHandler.Migrator().HasTable("widgets") {
+			t.Errorf("DryRunChangedTheDatabase")
+		}
+
+		appliedMigrations, err := schemaMigrator.Migrate()
+		if err != nil {
+			t.Fatalf("MigrateUnexpectedError: '%s'", err.Error())
+		}
+		if len(appliedMigrations) != 2 {
+			t.Errorf("UnexpectedAppliedMigrationsCount: %d", len(appliedMigrations))
+		}
+		if !dbHandler.Migrator().HasColumn("widgets", "label") {
+			t.Errorf("MigrationNotApplied: app/2")
+		}
+
+		appliedMigrations, err = schemaMigrator.Migrate()
+		if err != nil {
+			t.Fatalf("RerunUnexpectedError: '%s'", err.Error())
+		}
+		if len(appliedMigrations) != 0 || upFuncCallsCount != 1 {
+			t.Errorf("MigrationsReapplied: %d", upFuncCallsCount)
+		}
+
+		migrationsStatus, err := schemaMigrator.ReadStatus()
+		if err != nil {
+			t.Fatalf("ReadStatusUnexpectedError: '%s'", err.Error())
+		}
+		for _, migrationStatus := range migrationsStatus {
+			if !migrationStatus.IsApplied || migrationStatus.AppliedAt == nil {
+				t.Errorf("MigrationNotFlaggedAsApplied: %+v", migrationStatus)
+			}
+		}
+	})
+
+	t.Run("FailedMigrationIsRolledBack", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			DbHandler: dbHandler,
+			Migrations: []SchemaMigration{
+				{
+					Version:         1,
+					UpSqlStatements: []string{"CREATE TABLE widgets (id INTEGER PRIMARY KEY)"},
+				},
+				{
+					Version: 2,
+					UpFunc: func(dbTx *gorm.DB) error {
+						err := dbTx.Exec("CREATE TABLE gadgets (id INTEGER PRIMARY KEY)").Error
+						if err != nil {
+							return err
+						}
+						return errors.New("BackfillFailed")
+					},
+				},
+			},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		appliedMigrations, err := schemaMigrator.Migrate()
+		if err == nil || !strings.Contains(err.Error(), "app/2: BackfillFailed") {
+			t.Errorf("MissingExpectedError: %s (got %v)", errSchemaMigrationFailed, err)
+		}
+		if len(appliedMigrations) != 1 {
+			t.Errorf("UnexpectedAppliedMigrationsCount: %d", len(appliedMigrations))
+		}
+		if dbHandler.Migrator().HasTable("gadgets") {
+			t.Errorf("FailedMigrationNotRolledBack")
+		}
+
+		pendingMigrations, err := schemaMigrator.DryRun()
+		if err != nil {
+			t.Fatalf("DryRunUnexpectedError: '%s'", err.Error())
+		}
+		if len(pendingMigrations) != 1 || pendingMigrations[0].Version != 2 {
+			t.Errorf("UnexpectedPendingMigrations: %+v", pendingMigrations)
+		}
+	})
+
+	t.Run("AdoptsAutoMigratedDatabase", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+
+		err := dbHandler.AutoMigrate(
+			&tkInfraDbModel.ActivityRecord{},
+			&tkInfraDbModel.ActivityRecordAffectedResource{},
+			&tkInfraDbModel.ActivityRecordCheckpoint{},
+		)
+		if err != nil {
+			t.Fatalf("SetupFailed: '%s'", err.Error())
+		}
+
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			DbHandler: dbHandler, Migrations: TrailDatabaseMigrations(),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		_, err = schemaMigrator.Migrate()
+		if err != nil {
+			t.Errorf("UnexpectedError: '%s'", err.Error())
+		}
+	})
+
+	t.Run("MigrationsAreFrozen", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			DbHandler: dbHandler, Migrations: TrailDatabaseMigrations()[:1],
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		_, err = schemaMigrator.Migrate()
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		dbMigrator := dbHandler.Migrator()
+		if !dbMigrator.HasTable("activity_record_checkpoints") {
+			t.Error("MissingTable: activity_record_checkpoints")
+		}
+		if dbMigrator.HasColumn("activity_records", "correlation_id") {
+			t.Error("UnexpectedColumn: activity_records.correlation_id")
+		}
+		if dbMigrator.HasColumn("activity_records_affected_resources", "account_id") {
+			t.Error("UnexpectedColumn: activity_records_affected_resources.account_id")
+		}
+	})
+
+	t.Run("BackfillsAffectedResourceComponents", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+
+		// Affected resources table as created before the account_id and resource_type
+		// columns existed.
+		legacySqlStatements := []string{
+			"CREATE TABLE activity_records_affected_resources (id integer PRIMARY KEY AUTOINCREMENT, system_resource_identifier text NOT NULL, activity_record_id integer NOT NULL)",
+			"INSERT INTO activity_records_affected_resources (system_resource_identifier, activity_record_id) VALUES ('sri://12:mailbox/info', 1), ('sri://0:account/12', 1), ('legacy-resource', 2)",
+		}
+		for _, legacySqlStatement := range legacySqlStatements {
+			err := dbHandler.Exec(legacySqlStatement).Error
+			if err != nil {
+				t.Fatalf("SetupFailed: '%s'", err.Error())
+			}
+		}
+
+		schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+			DbHandler: dbHandler, Migrations: TrailDatabaseMigrations(),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		_, err = schemaMigrator.Migrate()
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		affectedResourceModels := []tkInfraDbModel.ActivityRecordAffectedResource{}
+		err = dbHandler.Order("id ASC").Find(&affectedResourceModels).Error
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		backfilledComponents := []string{}
+		for _, affectedResourceModel := range affectedResourceModels {
+			accountIdStr, resourceTypeStr := "nil", "nil"
+			if affectedResourceModel.AccountId != nil {
+				accountIdStr = strconv.FormatUint(*affectedResourceModel.AccountId, 10)
+			}
+			if affectedResourceModel.ResourceType != nil {
+				resourceTypeStr = *affectedResourceModel.ResourceType
+			}
+			backfilledComponents = append(
+				backfilledComponents, accountIdStr+":"+resourceTypeStr,
+			)
+		}
+		expectedComponents := "12:mailbox 0:account nil:nil"
+		if strings.Join(backfilledComponents, " ") != expectedComponents {
+			t.Errorf(
+				"BackfilledComponentsMismatch: expected %s, got %v",
+				expectedComponents, backfilledComponents,
+			)
+		}
+	})
+}
+
+func TestTrailDatabaseServiceMigrations(t *testing.T) {
+	t.Run("SkipMigrations", func(t *testing.T) {
+		dbHandler := setupTestSchemaMigratorDbHandler(t)
+		if dbHandler.Migrator().HasTable(&tkInfraDbModel.ActivityRecord{}) {
+			t.Errorf("MigrationsNotSkipped")
+		}
+	})
+
+	t.Run("ExtraMigrationsRunAfterBuiltIn", func(t *testing.T) {
+		dbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+			Dsn: TrailDatabaseInMemoryDsn,
+			ExtraMigrations: []SchemaMigration{{
+				Version: 1,
+				UpSqlStatements: []string{
+					"CREATE INDEX idx_activity_records_code_level ON activity_records (record_code, record_level)",
+				},
+			}},
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		migrationsStatus, err := dbSvc.SchemaMigrator.ReadStatus()
+		if err != nil {
+			t.Fatalf("ReadStatusUnexpectedError: '%s'", err.Error())
+		}
+		if len(migrationsStatus) != len(TrailDatabaseMigrations())+1 {
+			t.Fatalf("UnexpectedMigrationsCount: %d", len(migrationsStatus))
+		}
+		lastMigrationStatus := migrationsStatus[len(migrationsStatus)-1]
+		if lastMigrationStatus.Namespace != SchemaMigrationDefaultNamespace ||
+			!lastMigrationStatus.IsApplied {
+			t.Errorf("ExtraMigrationNotApplied: %+v", lastMigrationStatus)
+		}
+	})
+
+	t.Run("InvalidExtraMigration", func(t *testing.T) {
+		_, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+			Dsn:             TrailDatabaseInMemoryDsn,
+			ExtraMigrations: []SchemaMigration{{Namespace: "tk", Version: 1, UpSqlStatements: []string{"SELECT 1"}}},
+		})
+		if err == nil || !strings.HasPrefix(err.Error(), errSchemaMigrationDuplicated) {
+			t.Errorf("MissingExpectedError: %s (got %v)", errSchemaMigrationDuplicated, err)
+		}
+	})
+}
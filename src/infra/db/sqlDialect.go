@@ -0,0 +1,237 @@
+package tkInfraDb
+
+import (
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+type DatabaseDriver string
+
+const (
+	DatabaseDriverSqlite   DatabaseDriver = "sqlite"
+	DatabaseDriverPostgres DatabaseDriver = "postgres"
+	DatabaseDriverMysql    DatabaseDriver = "mysql"
+)
+
+// SqlJsonValueKind is the JSON type JsonPathExtractExpression keeps; "any" keeps every
+// non-null value, for the NULL checks.
+type SqlJsonValueKind string
+
+const (
+	SqlJsonValueKindAny     SqlJsonValueKind = "any"
+	SqlJsonValueKindText    SqlJsonValueKind = "text"
+	SqlJsonValueKindNumber  SqlJsonValueKind = "number"
+	SqlJsonValueKindBoolean SqlJsonValueKind = "boolean"
+)
+
+// SqlDialect builds the few SQL expressions that differ between the supported drivers,
+// so the repositories don't rely on SQLite-only functions. Unknown drivers are handled
+// as SQLite.
+type SqlDialect struct {
+	Driver DatabaseDriver
+}
+
+func NewSqlDialect(dbHandler *gorm.DB) SqlDialect {
+	if dbHandler == nil || dbHandler.Dialector == nil {
+		return SqlDialect{Driver: DatabaseDriverSqlite}
+	}
+
+	switch DatabaseDriver(dbHandler.Dialector.Name()) {
+	case DatabaseDriverPostgres:
+		return SqlDialect{Driver: DatabaseDriverPostgres}
+	case DatabaseDriverMysql:
+		return SqlDialect{Driver: DatabaseDriverMysql}
+	default:
+		return SqlDialect{Driver: DatabaseDriverSqlite}
+	}
+}
+
+// IsNullsFirstByDefault reports where the driver places NULLs when the ORDER BY has no
+// NULLS FIRST/LAST: SQLite and MySQL handle them as the smallest values, PostgreSQL as
+// the largest ones.
+func (dialect SqlDialect) IsNullsFirstByDefault(isDescending bool) bool {
+	if dialect.Driver == DatabaseDriverPostgres {
+		return isDescending
+	}
+	return !isDescending
+}
+
+// OrderByNullsPlacement returns the ORDER BY term(s) of the column with the NULLs
+// explicitly first or last. MySQL lacks NULLS FIRST/LAST, so it's emulated.
+func (dialect SqlDialect) OrderByNullsPlacement(
+	columnExpression, sortDirection string,
+	shouldPlaceNullsFirst bool,
+) string {
+	if dialect.Driver == DatabaseDriverMysql {
+		nullsDirection := "ASC"
+		if shouldPlaceNullsFirst {
+			nullsDirection = "DESC"
+		}
+		return columnExpression + " IS NULL " + nullsDirection + ", " +
+			columnExpression + " " + sortDirection
+	}
+
+	nullsPlacement := " NULLS LAST"
+	if shouldPlaceNullsFirst {
+		nullsPlacement = " NULLS FIRST"
+	}
+	return columnExpression + " " + sortDirection + nullsPlacement
+}
+
+// JsonPathExtractExpression returns the expression extracting the value at jsonPath
+// (bound, never concatenated) of a text column holding JSON. Invalid JSON and values
+// of another kind result in NULL on every driver, so a string never compares to a
+// number nor a number to a boolean. Boolean comparisons must use JsonBooleanBindValue.
+func (dialect SqlDialect) JsonPathExtractExpression(
+	jsonColumn, jsonPath string,
+	valueKind SqlJsonValueKind,
+) (expression string, expressionArgs []any) {
+	switch dialect.Driver {
+	case DatabaseDriverPostgres:
+		// "IS JSON" requires PostgreSQL 16 or newer.
+		extractedValue := "jsonb_path_query_first(CASE WHEN " + jsonColumn +
+			" IS JSON THEN " + jsonColumn + "::jsonb END, ?::jsonpath)"
+		switch valueKind {
+		case SqlJsonValueKindNumber:
+			return "(CASE WHEN jsonb_typeof(" + extractedValue + ") = 'number' THEN (" +
+				extractedValue + " #>> '{}')::numeric END)", []any{jsonPath, jsonPath}
+		case SqlJsonValueKindBoolean:
+			return "(CASE WHEN jsonb_typeof(" + extractedValue + ") = 'boolean' THEN (" +
+				extractedValue + " #>> '{}') END)", []any{jsonPath, jsonPath}
+		case SqlJsonValueKindText:
+			return "(CASE WHEN jsonb_typeof(" + extractedValue + ") = 'string' THEN (" +
+				extractedValue + " #>> '{}') END)", []any{jsonPath, jsonPath}
+		default:
+			return "(" + extractedValue + " #>> '{}')", []any{jsonPath}
+		}
+
+	case DatabaseDriverMysql:
+		extractedValue := "JSON_EXTRACT(CASE WHEN JSON_VALID(" + jsonColumn + ") THEN " +
+			jsonColumn + " END, ?)"
+		typeCondition := "JSON_TYPE(" + extractedValue + ") <> 'NULL'"
+		unquotedValue := "JSON_UNQUOTE(" + extractedValue + ")"
+		switch valueKind {
+		case SqlJsonValueKindNumber:
+			typeCondition = "JSON_TYPE(" + extractedValue +
+				") IN ('INTEGER', 'UNSIGNED INTEGER', 'DOUBLE', 'DECIMAL')"
+			unquotedValue = "CAST(" + unquotedValue + " AS DOUBLE)"
+		case SqlJsonValueKindBoolean:
+			typeCondition = "JSON_TYPE(" + extractedValue + ") = 'BOOLEAN'"
+		case SqlJsonValueKindText:
+			typeCondition = "JSON_TYPE(" + extractedValue + ") = 'STRING'"
+		}
+		return "(CASE WHEN " + typeCondition + " THEN " + unquotedValue + " END)",
+			[]any{jsonPath, jsonPath}
+
+	default:
+		// Without the json_type guard, TEXT would compare above every number and the
+		// booleans, extracted as 1 or 0, would equal the numbers.
+		validColumn := "(CASE WHEN json_valid(" + jsonColumn + ") THEN " +
+			jsonColumn + " END)"
+		extractedValue := "json_extract(" + validColumn + ", ?)"
+		typeCondition := ""
+		switch valueKind {
+		case SqlJsonValueKindNumber:
+			typeCondition = "json_type(" + validColumn + ", ?) IN ('integer', 'real')"
+		case SqlJsonValueKindBoolean:
+			typeCondition = "json_type(" + validColumn + ", ?) IN ('true', 'false')"
+		case SqlJsonValueKindText:
+			typeCondition = "json_type(" + validColumn + ", ?) = 'text'"
+		default:
+			return "(" + extractedValue + ")", []any{jsonPath}
+		}
+		return "(CASE WHEN " + typeCondition + " THEN " + extractedValue + " END)",
+			[]any{jsonPath, jsonPath}
+	}
+}
+
+// JsonBooleanBindValue is the value a JSON boolean extracted by JsonPathExtractExpression
+// compares to: SQLite returns them as integers, the other drivers as text.
+func (dialect SqlDialect) JsonBooleanBindValue(booleanValue bool) any {
+	if dialect.Driver == DatabaseDriverSqlite {
+		if booleanValue {
+			return 1
+		}
+		return 0
+	}
+	return strconv.FormatBool(booleanValue)
+}
+
+// UnixEpochExpression converts a timestamp column into Unix seconds (integer).
+func (dialect SqlDialect) UnixEpochExpression(columnExpression string) string {
+	switch dialect.Driver {
+	case DatabaseDriverPostgres:
+		return "CAST(FLOOR(EXTRACT(EPOCH FROM " + columnExpression + ")) AS BIGINT)"
+	case DatabaseDriverMysql:
+		return "TIMESTAMPDIFF(SECOND, '1970-01-01 00:00:00', " + columnExpression + ")"
+	default:
+		return "CAST(strftime('%s', " + columnExpression + ") AS INTEGER)"
+	}
+}
+
+// UnixTimeBucketExpression returns the Unix seconds at which the fixed size bucket of
+// the timestamp column starts. The offset shifts the bucket boundaries.
+func (dialect SqlDialect) UnixTimeBucketExpression(
+	columnExpression string,
+	bucketSizeSecs, bucketOffsetSecs int64,
+) string {
+	epochExpression := dialect.UnixEpochExpression(columnExpression)
+	if bucketOffsetSecs != 0 {
+		epochExpression = "(" + epochExpression + " - " +
+			strconv.FormatInt(bucketOffsetSecs, 10) + ")"
+	}
+
+	divisionOperator := " / "
+	if dialect.Driver == DatabaseDriverMysql {
+		divisionOperator = " DIV "
+	}
+	bucketSizeStr := strconv.FormatInt(bucketSizeSecs, 10)
+	bucketExpression := "(" + epochExpression + divisionOperator + bucketSizeStr + ") * " +
+		bucketSizeStr
+	if bucketOffsetSecs != 0 {
+		bucketExpression += " + " + strconv.FormatInt(bucketOffsetSecs, 10)
+	}
+
+	return "(" + bucketExpression + ")"
+}
+
+// UnixMonthBucketExpression returns the Unix seconds at which the (UTC) month of the
+// timestamp column starts.
+func (dialect SqlDialect) UnixMonthBucketExpression(columnExpression string) string {
+	switch dialect.Driver {
+	case DatabaseDriverPostgres:
+		return dialect.UnixEpochExpression(
+			"date_trunc('month', " + columnExpression + " AT TIME ZONE 'UTC') AT TIME ZONE 'UTC'",
+		)
+	case DatabaseDriverMysql:
+		return dialect.UnixEpochExpression(
+			"DATE_FORMAT(" + columnExpression + ", '%Y-%m-01')",
+		)
+	default:
+		return dialect.UnixEpochExpression(
+			"strftime('%Y-%m-01', " + columnExpression + ")",
+		)
+	}
+}
+
+// StringPositionExpression returns the 1-based position of needle in haystack, or 0.
+func (dialect SqlDialect) StringPositionExpression(haystack, needle string) string {
+	if dialect.Driver == DatabaseDriverPostgres {
+		return "strpos(" + haystack + ", " + needle + ")"
+	}
+	return "instr(" + haystack + ", " + needle + ")"
+}
+
+// CompactStatement returns the statement reclaiming the space freed by deletions.
+func (dialect SqlDialect) CompactStatement(tableNames []string) string {
+	switch dialect.Driver {
+	case DatabaseDriverPostgres:
+		return "VACUUM " + strings.Join(tableNames, ", ")
+	case DatabaseDriverMysql:
+		return "OPTIMIZE TABLE " + strings.Join(tableNames, ", ")
+	default:
+		return "VACUUM"
+	}
+}
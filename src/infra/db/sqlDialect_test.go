@@ -0,0 +1,208 @@
+package tkInfraDb
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"gorm.io/driver/mysql"
+	"gorm.io/driver/postgres"
+	"gorm.io/gorm"
+)
+
+func TestSqlDialect(t *testing.T) {
+	// Dry run handlers only render the SQL, no server is needed.
+	dryRunHandlerFactory := func(t *testing.T, dialector gorm.Dialector) *gorm.DB {
+		t.Helper()
+		dbHandler, err := gorm.Open(
+			dialector, &gorm.Config{DryRun: true, DisableAutomaticPing: true},
+		)
+		if err != nil {
+			t.Fatalf("OpenDryRunHandlerFailed: %v", err)
+		}
+		return dbHandler
+	}
+
+	t.Run("DriverDetection", func(t *testing.T) {
+		testCaseStructs := []struct {
+			dbHandler      *gorm.DB
+			expectedDriver DatabaseDriver
+		}{
+			{setupTestDb(t), DatabaseDriverSqlite},
+			{dryRunHandlerFactory(t, postgres.Open("host=localhost")), DatabaseDriverPostgres},
+			{dryRunHandlerFactory(t, mysql.New(mysql.Config{
+				DSN: "tcp(localhost)/trail", SkipInitializeWithVersion: true,
+			})), DatabaseDriverMysql},
+			{nil, DatabaseDriverSqlite},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sqlDialect := NewSqlDialect(testCase.dbHandler)
+			if sqlDialect.Driver != testCase.expectedDriver {
+				t.Errorf(
+					"DriverMismatch: expected %s, got %s",
+					testCase.expectedDriver, sqlDialect.Driver,
+				)
+			}
+		}
+	})
+
+	t.Run("PaginationQueryPerDriver", func(t *testing.T) {
+		cursor, _ := paginationCursorPayload{
+			SortField:       "score",
+			SortDirection:   "asc",
+			SortValue:       paginationCursorValue{Kind: paginationCursorValueKindInt, Value: "10"},
+			PrimaryKeyValue: paginationCursorValue{Kind: paginationCursorValueKindUint, Value: "4"},
+		}.encode()
+		builderSettings := PaginationQueryBuilderSettings{
+			SortableFields: PaginationSortableFields{
+				"score": {ColumnExpression: "score", NullsOrder: PaginationNullsOrderLast},
+			},
+		}
+
+		testCaseStructs := []struct {
+			dbHandler             *gorm.DB
+			expectedSqlFragments  []string
+			forbiddenSqlFragments []string
+		}{
+			{
+				dryRunHandlerFactory(t, postgres.Open("host=localhost")),
+				[]string{
+					`(score, id) > ($1, $2) OR score IS NULL`,
+					"ORDER BY score asc NULLS LAST, id asc",
+				},
+				[]string{"strftime", "json_"},
+			},
+			{
+				dryRunHandlerFactory(t, mysql.New(mysql.Config{
+					DSN: "tcp(localhost)/trail", SkipInitializeWithVersion: true,
+				})),
+				[]string{
+					"(score, id) > (?, ?) OR score IS NULL",
+					"ORDER BY score IS NULL ASC, score asc, id asc",
+				},
+				[]string{"NULLS LAST"},
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			paginatedQuery, _, err := PaginationQueryBuilderWithSettings(
+				testCase.dbHandler.Model(&testPaginationCursorModel{}),
+				tkDto.Pagination{ItemsPerPage: 2, Cursor: &cursor},
+				builderSettings,
+			)
+			if err != nil {
+				t.Fatalf("UnexpectedError: %v", err)
+			}
+
+			// Dry runs don't reset the statement after the items total count.
+			paginatedQuery.Statement.SQL.Reset()
+			paginatedQuery.Statement.Vars = nil
+			renderedSql := paginatedQuery.Find(&[]testPaginationCursorModel{}).Statement.SQL.String()
+			for _, expectedSqlFragment := range testCase.expectedSqlFragments {
+				if !strings.Contains(renderedSql, expectedSqlFragment) {
+					t.Errorf("MissingSqlFragment: '%s' in '%s'", expectedSqlFragment, renderedSql)
+				}
+			}
+			for _, forbiddenSqlFragment := range testCase.forbiddenSqlFragments {
+				if strings.Contains(renderedSql, forbiddenSqlFragment) {
+					t.Errorf("ForbiddenSqlFragment: '%s' in '%s'", forbiddenSqlFragment, renderedSql)
+				}
+			}
+		}
+	})
+
+	t.Run("SqliteExpressions", func(t *testing.T) {
+		dbHandler := setupTestDb(t)
+		sqlDialect := NewSqlDialect(dbHandler)
+		createdAtLiteral := "'2026-03-15 10:20:30+00:00'"
+
+		readInt64 := func(expression string, expressionArgs ...any) int64 {
+			var expressionValue int64
+			err := dbHandler.Raw("SELECT "+expression, expressionArgs...).Scan(&expressionValue).Error
+			if err != nil {
+				t.Fatalf("EvaluateExpressionFailed: %v [%s]", err, expression)
+			}
+			return expressionValue
+		}
+
+		testCaseStructs := []struct {
+			expression    string
+			expectedValue time.Time
+		}{
+			{
+				sqlDialect.UnixTimeBucketExpression(createdAtLiteral, 3600, 0),
+				time.Date(2026, 3, 15, 10, 0, 0, 0, time.UTC),
+			},
+			{
+				sqlDialect.UnixTimeBucketExpression(createdAtLiteral, 604800, 345600),
+				time.Date(2026, 3, 9, 0, 0, 0, 0, time.UTC),
+			},
+			{
+				sqlDialect.UnixMonthBucketExpression(createdAtLiteral),
+				time.Date(2026, 3, 1, 0, 0, 0, 0, time.UTC),
+			},
+		}
+		for _, testCase := range testCaseStructs {
+			expressionValue := readInt64(testCase.expression)
+			if expressionValue != testCase.expectedValue.Unix() {
+				t.Errorf(
+					"BucketMismatch: expected %d, got %d [%s]",
+					testCase.expectedValue.Unix(), expressionValue, testCase.expression,
+				)
+			}
+		}
+
+		extractExpression, extractArgs := sqlDialect.JsonPathExtractExpression(
+			`'{"user":{"ids":[5,7]}}'`, `$."user"."ids"[1]`, SqlJsonValueKindNumber,
+		)
+		if readInt64(extractExpression, extractArgs...) != 7 {
+			t.Errorf("JsonPathExtractMismatch: %s", extractExpression)
+		}
+
+		positionExpression := sqlDialect.StringPositionExpression("'0:mailbox/1'", "':'")
+		if readInt64(positionExpression) != 2 {
+			t.Errorf("StringPositionMismatch: %s", positionExpression)
+		}
+	})
+
+	t.Run("NullsPlacementByDriver", func(t *testing.T) {
+		testCaseStructs := []struct {
+			driver                    DatabaseDriver
+			isDescending              bool
+			expectedIsNullsFirst      bool
+			expectedNullsFirstOrderBy string
+		}{
+			{DatabaseDriverSqlite, false, true, "score asc NULLS FIRST"},
+			{DatabaseDriverSqlite, true, false, "score desc NULLS FIRST"},
+			{DatabaseDriverPostgres, false, false, "score asc NULLS FIRST"},
+			{DatabaseDriverPostgres, true, true, "score desc NULLS FIRST"},
+			{DatabaseDriverMysql, false, true, "score IS NULL DESC, score asc"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			sqlDialect := SqlDialect{Driver: testCase.driver}
+			isNullsFirst := sqlDialect.IsNullsFirstByDefault(testCase.isDescending)
+			if isNullsFirst != testCase.expectedIsNullsFirst {
+				t.Errorf(
+					"IsNullsFirstMismatch: expected %t [%s %t]",
+					testCase.expectedIsNullsFirst, testCase.driver, testCase.isDescending,
+				)
+			}
+
+			sortDirection := tkValueObject.PaginationSortDirectionAsc
+			if testCase.isDescending {
+				sortDirection = tkValueObject.PaginationSortDirectionDesc
+			}
+			orderBy := sqlDialect.OrderByNullsPlacement("score", sortDirection.String(), true)
+			if orderBy != testCase.expectedNullsFirstOrderBy {
+				t.Errorf(
+					"OrderByMismatch: expected '%s', got '%s'",
+					testCase.expectedNullsFirstOrderBy, orderBy,
+				)
+			}
+		}
+	})
+}
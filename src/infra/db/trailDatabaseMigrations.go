@@ -0,0 +1,176 @@
+package tkInfraDb
+
+import (
+	"time"
+
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+)
+
+// TrailDatabaseMigrationsNamespace holds the toolkit built-in migrations, consumer
+// projects must register theirs under another namespace.
+const TrailDatabaseMigrationsNamespace string = "tk"
+
+// The built-in migrations are frozen on migration-local snapshots of the models, so
+// what a version creates never changes along with the models. AutoMigrate only adds
+// what's missing, so the databases created back when the schema was auto-migrated on
+// every start adopt them without changes.
+
+type trailDatabaseMigrationV1ActivityRecord struct {
+	ID                uint64                                                   `gorm:"primaryKey"`
+	RecordLevel       string                                                   `gorm:"not null"`
+	RecordCode        string                                                   `gorm:"not null"`
+	AffectedResources []trailDatabaseMigrationV1ActivityRecordAffectedResource `gorm:"foreignKey:ActivityRecordID"`
+	RecordDetails     *string
+	OperatorSri       *string
+	OperatorIpAddress *string
+	CreatedAt         time.Time `gorm:"not null"`
+	ContentHash       *string   `gorm:"index;size:64"`
+	PreviousHash      *string   `gorm:"size:64"`
+}
+
+func (trailDatabaseMigrationV1ActivityRecord) TableName() string {
+	return "activity_records"
+}
+
+type trailDatabaseMigrationV1ActivityRecordAffectedResource struct {
+	ID                       uint64 `gorm:"primaryKey"`
+	SystemResourceIdentifier string `gorm:"not null"`
+	ActivityRecordID         uint64 `gorm:"not null"`
+}
+
+func (trailDatabaseMigrationV1ActivityRecordAffectedResource) TableName() string {
+	return "activity_records_affected_resources"
+}
+
+type trailDatabaseMigrationV1ActivityRecordCheckpoint struct {
+	ID               uint64    `gorm:"primaryKey"`
+	ActivityRecordID uint64    `gorm:"not null;uniqueIndex"`
+	PreviousHash     string    `gorm:"not null;size:64"`
+	AnchorHash       string    `gorm:"not null;index;size:64"`
+	Signature        string    `gorm:"not null"`
+	CreatedAt        time.Time `gorm:"not null"`
+}
+
+func (trailDatabaseMigrationV1ActivityRecordCheckpoint) TableName() string {
+	return "activity_record_checkpoints"
+}
+
+type trailDatabaseMigrationV2ActivityRecordAffectedResource struct {
+	ID                       uint64  `gorm:"primaryKey"`
+	SystemResourceIdentifier string  `gorm:"not null"`
+	AccountId                *uint64 `gorm:"index:idx_activity_records_affected_resources_account_type,priority:1"`
+	ResourceType             *string `gorm:"index:idx_activity_records_affected_resources_account_type,priority:2"`
+	ActivityRecordID         uint64  `gorm:"not null"`
+}
+
+func (trailDatabaseMigrationV2ActivityRecordAffectedResource) TableName() string {
+	return "activity_records_affected_resources"
+}
+
+type trailDatabaseMigrationV3ActivityRecord struct {
+	ID            uint64  `gorm:"primaryKey"`
+	CorrelationId *string `gorm:"index;size:128"`
+}
+
+func (trailDatabaseMigrationV3ActivityRecord) TableName() string {
+	return "activity_records"
+}
+
+type trailDatabaseMigrationV4ActivityRecordChainHead struct {
+	ID           uint64    `gorm:"primaryKey"`
+	LastRecordId uint64    `gorm:"not null"`
+	LastHash     string    `gorm:"not null;size:64"`
+	Signature    string    `gorm:"not null"`
+	UpdatedAt    time.Time `gorm:"not null"`
+}
+
+func (trailDatabaseMigrationV4ActivityRecordChainHead) TableName() string {
+	return "activity_record_chain_heads"
+}
+
+// TrailDatabaseMigrations returns the toolkit built-in migrations.
+func TrailDatabaseMigrations() []SchemaMigration {
+	return []SchemaMigration{
+		{
+			Namespace:   TrailDatabaseMigrationsNamespace,
+			Version:     1,
+			Description: "CreateActivityRecordTables",
+			UpFunc: func(dbTx *gorm.DB) error {
+				return dbTx.AutoMigrate(
+					&trailDatabaseMigrationV1ActivityRecord{},
+					&trailDatabaseMigrationV1ActivityRecordAffectedResource{},
+					&trailDatabaseMigrationV1ActivityRecordCheckpoint{},
+				)
+			},
+		},
+		{
+			Namespace:   TrailDatabaseMigrationsNamespace,
+			Version:     2,
+			Description: "AddActivityRecordAffectedResourceComponents",
+			UpFunc: func(dbTx *gorm.DB) error {
+				err := dbTx.AutoMigrate(&trailDatabaseMigrationV2ActivityRecordAffectedResource{})
+				if err != nil {
+					return err
+				}
+				return activityRecordAffectedResourceComponentsBackfiller(dbTx)
+			},
+		},
+		{
+			Namespace:   TrailDatabaseMigrationsNamespace,
+			Version:     3,
+			Description: "AddActivityRecordCorrelationId",
+			UpFunc: func(dbTx *gorm.DB) error {
+				return dbTx.AutoMigrate(&trailDatabaseMigrationV3ActivityRecord{})
+			},
+		},
+		{
+			Namespace:   TrailDatabaseMigrationsNamespace,
+			Version:     4,
+			Description: "CreateActivityRecordChainHeadTable",
+			UpFunc: func(dbTx *gorm.DB) error {
+				return dbTx.AutoMigrate(&trailDatabaseMigrationV4ActivityRecordChainHead{})
+			},
+		},
+	}
+}
+
+// activityRecordAffectedResourceComponentsBackfiller parses the account ID and resource
+// type columns of the affected resources stored before they existed, in batches.
+func activityRecordAffectedResourceComponentsBackfiller(dbTx *gorm.DB) error {
+	const backfillBatchSize int = 1000
+
+	lastBackfilledId := uint64(0)
+	for {
+		affectedResourceModels := []trailDatabaseMigrationV2ActivityRecordAffectedResource{}
+		err := dbTx.
+			Where("id > ? AND account_id IS NULL AND resource_type IS NULL", lastBackfilledId).
+			Order("id ASC").Limit(backfillBatchSize).
+			Find(&affectedResourceModels).Error
+		if err != nil {
+			return err
+		}
+
+		for _, affectedResourceModel := range affectedResourceModels {
+			lastBackfilledId = affectedResourceModel.ID
+			accountId, resourceType := tkInfraDbModel.ActivityRecordAffectedResourceComponentsReader(
+				affectedResourceModel.SystemResourceIdentifier,
+			)
+			if accountId == nil && resourceType == nil {
+				continue
+			}
+
+			err = dbTx.Model(&trailDatabaseMigrationV2ActivityRecordAffectedResource{}).
+				Where("id = ?", affectedResourceModel.ID).
+				Updates(map[string]any{"account_id": accountId, "resource_type": resourceType}).
+				Error
+			if err != nil {
+				return err
+			}
+		}
+
+		if len(affectedResourceModels) < backfillBatchSize {
+			return nil
+		}
+	}
+}
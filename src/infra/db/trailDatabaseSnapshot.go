@@ -0,0 +1,301 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+
+	"github.com/glebarez/sqlite"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+	"gorm.io/gorm"
+)
+
+const (
+	trailDatabaseSnapshotRestoreSuffix             string = ".restore"
+	trailDatabaseSnapshotCandidateConnectionParams string = "?mode=rw&_journal_mode=DELETE&_busy_timeout=5000&_foreign_keys=on"
+
+	errTrailDatabaseSnapshotDriverNotSupported    string = "TrailDatabaseSnapshotDriverNotSupported"
+	errTrailDatabaseSnapshotCompressionNotValid   string = "TrailDatabaseSnapshotCompressionFormatNotSupported"
+	errTrailDatabaseSnapshotTargetExists          string = "TrailDatabaseSnapshotTargetAlreadyExists"
+	errTrailDatabaseSnapshotCreateError           string = "TrailDatabaseSnapshotCreateError"
+	errTrailDatabaseSnapshotNotFound              string = "TrailDatabaseSnapshotNotFound"
+	errTrailDatabaseSnapshotInMemoryNotRestorable string = "TrailDatabaseInMemoryCannotBeRestored"
+	errTrailDatabaseSnapshotNotValid              string = "TrailDatabaseSnapshotNotValid"
+	errTrailDatabaseSnapshotRestoreError          string = "TrailDatabaseSnapshotRestoreError"
+)
+
+// trailDatabaseSnapshotCompressionFormats are the single file formats, so the snapshot
+// is decompressed back into a database file rather than a directory.
+var trailDatabaseSnapshotCompressionFormats = []tkValueObject.CompressionFormat{
+	tkValueObject.CompressionFormatGzip,
+	tkValueObject.CompressionFormatXz,
+	tkValueObject.CompressionFormatBrotli,
+}
+
+func (service *TrailDatabaseService) isSqlite() bool {
+	return NewSqlDialect(service.Handler).Driver == DatabaseDriverSqlite
+}
+
+// readSqliteFilePath returns the main database file, empty when it's in memory.
+func (service *TrailDatabaseService) readSqliteFilePath() (string, error) {
+	databaseList := []struct {
+		Name string
+		File string
+	}{}
+	err := service.Handler.Raw("PRAGMA database_list").Scan(&databaseList).Error
+	if err != nil {
+		return "", err
+	}
+
+	for _, attachedDatabase := range databaseList {
+		if attachedDatabase.Name == "main" {
+			return attachedDatabase.File, nil
+		}
+	}
+
+	return "", nil
+}
+
+// CreateSnapshot writes a consistent copy of the SQLite trail database to the target
+// file path with VACUUM INTO, which reads from a single transaction and so doesn't stop
+// the writers. The snapshot is then optionally compressed (gzip, xz or br) by the
+// SnapshotCommandRunner, returning the path of the compressed file instead.
+func (service *TrailDatabaseService) CreateSnapshot(
+	targetFilePath tkValueObject.UnixAbsoluteFilePath,
+	compressionFormatPtr *tkValueObject.CompressionFormat,
+) (snapshotFilePath tkValueObject.UnixAbsoluteFilePath, err error) {
+	if !service.isSqlite() {
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotDriverNotSupported)
+	}
+
+	isCompressionFormatValid := compressionFormatPtr == nil ||
+		slices.Contains(trailDatabaseSnapshotCompressionFormats, *compressionFormatPtr)
+	if !isCompressionFormatValid {
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotCompressionNotValid)
+	}
+
+	targetFilePathStr := targetFilePath.String()
+	fileClerk := service.snapshotFileClerk
+	if fileClerk.FileExists(targetFilePathStr) {
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotTargetExists)
+	}
+
+	err = os.MkdirAll(filepath.Dir(targetFilePathStr), 0700)
+	if err != nil {
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotCreateError + ": " + err.Error())
+	}
+
+	err = service.Handler.Exec("VACUUM INTO ?", targetFilePathStr).Error
+	if err != nil {
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotCreateError + ": " + err.Error())
+	}
+
+	if compressionFormatPtr == nil {
+		return targetFilePath, nil
+	}
+
+	compressionFormatStr := compressionFormatPtr.String()
+	compressedFilePath, err := fileClerk.CompressFile(targetFilePathStr, &compressionFormatStr)
+	if err != nil {
+		_ = fileClerk.DeleteFile(targetFilePathStr)
+		return snapshotFilePath, errors.New(errTrailDatabaseSnapshotCreateError + ": " + err.Error())
+	}
+
+	return tkValueObject.NewUnixAbsoluteFilePath(compressedFilePath, true)
+}
+
+// snapshotCandidateValidator opens the restore candidate on its own connection and
+// rejects it when it's corrupted, when it was migrated by a newer version (migrations
+// this service doesn't know) or when it lacks the activity records tables. It only
+// reads the schema: older snapshots are migrated by RestoreSnapshot once swapped in.
+func (service *TrailDatabaseService) snapshotCandidateValidator(candidateFilePath string) error {
+	// Rollback journal mode, so nothing is left in a WAL file when it's swapped in.
+	candidateHandler, err := gorm.Open(
+		sqlite.Open("file:"+candidateFilePath+trailDatabaseSnapshotCandidateConnectionParams),
+		trailDatabaseOrmConfigFactory(),
+	)
+	if err != nil {
+		return err
+	}
+	candidateSqlDb, err := candidateHandler.DB()
+	if err != nil {
+		return err
+	}
+	defer candidateSqlDb.Close()
+
+	integrityCheckResult := ""
+	err = candidateHandler.Raw("PRAGMA integrity_check").Scan(&integrityCheckResult).Error
+	if err != nil {
+		return err
+	}
+	if integrityCheckResult != "ok" {
+		return errors.New("IntegrityCheckFailed: " + integrityCheckResult)
+	}
+
+	candidateMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+		DbHandler: candidateHandler, Migrations: service.SchemaMigrator.migrations,
+	})
+	if err != nil {
+		return err
+	}
+
+	appliedModelsByKey, err := candidateMigrator.readAppliedMigrationModels()
+	if err != nil {
+		return err
+	}
+	if len(appliedModelsByKey) == 0 {
+		return errors.New("SchemaMigrationsNotFound")
+	}
+	for migrationKey := range appliedModelsByKey {
+		isMigrationKnown := slices.ContainsFunc(
+			candidateMigrator.migrations, func(migration SchemaMigration) bool {
+				return migration.String() == migrationKey
+			},
+		)
+		if !isMigrationKnown {
+			return errors.New("UnknownSchemaMigration: " + migrationKey)
+		}
+	}
+
+	pendingMigrationsStatus, err := candidateMigrator.DryRun()
+	if err != nil {
+		return err
+	}
+	// The tables of the pending migrations will be created by them.
+	requiredModelsPtrs := []any{
+		&tkInfraDbModel.ActivityRecord{},
+		&tkInfraDbModel.ActivityRecordAffectedResource{},
+		&tkInfraDbModel.ActivityRecordCheckpoint{},
+	}
+	if len(pendingMigrationsStatus) == 0 {
+		requiredModelsPtrs = append(
+			requiredModelsPtrs, &tkInfraDbModel.ActivityRecordChainHead{},
+		)
+	}
+	for _, requiredModelPtr := range requiredModelsPtrs {
+		if !candidateHandler.Migrator().HasTable(requiredModelPtr) {
+			return errors.New("RequiredTableNotFound")
+		}
+	}
+
+	return nil
+}
+
+// RestoreSnapshot replaces the SQLite trail database file with the snapshot, compressed
+// or not, once it's validated. The connections are closed during the swap and reopened
+// on the restored file (or on the previous one, when the swap fails); the repositories
+// holding the service pick up the new handler. The restored database is then migrated
+// like the service one, ExtraModelsPtrs included, unless ShouldSkipMigrations was set.
+//
+// @attention The Handler is swapped without synchronization, so the caller must make
+// sure nothing uses the service until RestoreSnapshot returns, e.g. by closing the
+// ActivityRecordAsyncCmdRepo first.
+func (service *TrailDatabaseService) RestoreSnapshot(
+	snapshotFilePath tkValueObject.UnixAbsoluteFilePath,
+) error {
+	if !service.isSqlite() {
+		return errors.New(errTrailDatabaseSnapshotDriverNotSupported)
+	}
+
+	snapshotFilePathStr := snapshotFilePath.String()
+	fileClerk := service.snapshotFileClerk
+	if !fileClerk.IsFile(snapshotFilePathStr) {
+		return errors.New(errTrailDatabaseSnapshotNotFound)
+	}
+
+	databaseFilePath, err := service.readSqliteFilePath()
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotRestoreError + ": " + err.Error())
+	}
+	if databaseFilePath == "" {
+		return errors.New(errTrailDatabaseSnapshotInMemoryNotRestorable)
+	}
+
+	// Next to the database file, so the swap is an atomic rename.
+	candidateFilePath := databaseFilePath + trailDatabaseSnapshotRestoreSuffix
+	_ = fileClerk.DeleteFile(candidateFilePath)
+	defer func() { _ = fileClerk.DeleteFile(candidateFilePath) }()
+
+	compressionFormat, err := tkValueObject.NewCompressionFormat(filepath.Ext(snapshotFilePathStr))
+	isCompressed := err == nil &&
+		slices.Contains(trailDatabaseSnapshotCompressionFormats, compressionFormat)
+	switch isCompressed {
+	case true:
+		shouldKeepSourceFile := true
+		_, err = fileClerk.DecompressFile(
+			snapshotFilePathStr, &candidateFilePath, &shouldKeepSourceFile,
+		)
+	case false:
+		err = fileClerk.CopyFile(snapshotFilePathStr, candidateFilePath)
+	}
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotRestoreError + ": " + err.Error())
+	}
+
+	err = service.snapshotCandidateValidator(candidateFilePath)
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotNotValid + ": " + err.Error())
+	}
+
+	// Moves the WAL pages into the database file, so the previous file is complete on
+	// its own should the swap fail.
+	err = service.Handler.Exec("PRAGMA wal_checkpoint(TRUNCATE)").Error
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotRestoreError + ": " + err.Error())
+	}
+
+	sqlDb, err := service.Handler.DB()
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotRestoreError + ": " + err.Error())
+	}
+	err = sqlDb.Close()
+	if err != nil {
+		return errors.New(errTrailDatabaseSnapshotRestoreError + ": " + err.Error())
+	}
+
+	err = os.Rename(candidateFilePath, databaseFilePath)
+	if err != nil {
+		// The previous file and its WAL are still in place, so the Handler mustn't stay
+		// closed.
+		return errors.Join(
+			errors.New(errTrailDatabaseSnapshotRestoreError+": "+err.Error()),
+			service.reopen(databaseFilePath),
+		)
+	}
+
+	// Stale WAL pages would otherwise be replayed on top of the restored file.
+	for _, walFileSuffix := range []string{"-wal", "-shm"} {
+		_ = os.Remove(databaseFilePath + walFileSuffix)
+	}
+
+	err = service.reopen(databaseFilePath)
+	if err != nil || service.shouldSkipMigrations {
+		return err
+	}
+
+	return service.dbMigrate(service.extraModelsPtrs)
+}
+
+func (service *TrailDatabaseService) reopen(databaseFilePath string) error {
+	ormSvc, err := gorm.Open(
+		sqliteDialectorWithFilePath(databaseFilePath),
+		trailDatabaseOrmConfigFactory(),
+	)
+	if err != nil {
+		return errors.New(errTrailDatabaseConnectionError)
+	}
+
+	schemaMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+		DbHandler: ormSvc, Migrations: service.SchemaMigrator.migrations,
+	})
+	if err != nil {
+		return err
+	}
+
+	service.Handler = ormSvc
+	service.SchemaMigrator = schemaMigrator
+
+	return nil
+}
@@ -0,0 +1,168 @@
+package tkInfraDb
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+const (
+	TrailDatabaseSnapshotFileNamePrefix string = "trail-"
+	TrailDatabaseSnapshotFileExtension  string = ".db"
+	// Fixed width, so the snapshot file names sort chronologically.
+	trailDatabaseSnapshotTimeLayout string = "20060102T150405.000000Z"
+
+	errTrailDatabaseSnapshotFileNameNotValid string = "TrailDatabaseSnapshotFileNameNotValid"
+)
+
+// TrailDatabaseSnapshotRepo manages the trail database snapshots of a directory, named
+// "trail-<UTC creation time>.db" plus the compression extension, if any. Files not
+// following this pattern are ignored, so the directory may hold other backups.
+type TrailDatabaseSnapshotRepo struct {
+	trailDbSvc *TrailDatabaseService
+}
+
+func NewTrailDatabaseSnapshotRepo(trailDbSvc *TrailDatabaseService) *TrailDatabaseSnapshotRepo {
+	return &TrailDatabaseSnapshotRepo{trailDbSvc: trailDbSvc}
+}
+
+// snapshotCreatedAtParser returns the creation time encoded in the snapshot file name.
+func (TrailDatabaseSnapshotRepo) snapshotCreatedAtParser(
+	snapshotFileName string,
+) (createdAt time.Time, err error) {
+	rawCreatedAt, isPrefixed := strings.CutPrefix(
+		snapshotFileName, TrailDatabaseSnapshotFileNamePrefix,
+	)
+	if !isPrefixed {
+		return createdAt, errors.New(errTrailDatabaseSnapshotFileNameNotValid)
+	}
+
+	rawCreatedAt, compressionExtension, isExtensionFound := strings.Cut(
+		rawCreatedAt, TrailDatabaseSnapshotFileExtension,
+	)
+	if !isExtensionFound {
+		return createdAt, errors.New(errTrailDatabaseSnapshotFileNameNotValid)
+	}
+	if compressionExtension != "" {
+		compressionFormat, err := tkValueObject.NewCompressionFormat(compressionExtension)
+		if err != nil {
+			return createdAt, errors.New(errTrailDatabaseSnapshotFileNameNotValid)
+		}
+		if !slices.Contains(trailDatabaseSnapshotCompressionFormats, compressionFormat) {
+			return createdAt, errors.New(errTrailDatabaseSnapshotFileNameNotValid)
+		}
+	}
+
+	createdAt, err = time.Parse(trailDatabaseSnapshotTimeLayout, rawCreatedAt)
+	if err != nil {
+		return createdAt, errors.New(errTrailDatabaseSnapshotFileNameNotValid)
+	}
+
+	return createdAt, nil
+}
+
+func (repo *TrailDatabaseSnapshotRepo) snapshotEntityFactory(
+	snapshotFilePath string,
+) (snapshotEntity tkEntity.TrailDatabaseSnapshot, err error) {
+	createdAt, err := repo.snapshotCreatedAtParser(filepath.Base(snapshotFilePath))
+	if err != nil {
+		return snapshotEntity, err
+	}
+
+	fileInfo, err := os.Stat(snapshotFilePath)
+	if err != nil {
+		return snapshotEntity, err
+	}
+
+	filePath, err := tkValueObject.NewUnixAbsoluteFilePath(snapshotFilePath, true)
+	if err != nil {
+		return snapshotEntity, err
+	}
+
+	return tkEntity.NewTrailDatabaseSnapshot(
+		filePath, tkValueObject.Byte(fileInfo.Size()),
+		tkValueObject.NewUnixTimeWithGoTime(createdAt),
+	), nil
+}
+
+func (repo *TrailDatabaseSnapshotRepo) Create(
+	createDto tkDto.CreateTrailDatabaseSnapshot,
+) (snapshotEntity tkEntity.TrailDatabaseSnapshot, err error) {
+	snapshotFileName := TrailDatabaseSnapshotFileNamePrefix +
+		time.Now().UTC().Format(trailDatabaseSnapshotTimeLayout) +
+		TrailDatabaseSnapshotFileExtension
+	targetFilePath, err := tkValueObject.NewUnixAbsoluteFilePath(
+		filepath.Join(createDto.SnapshotsDirPath.String(), snapshotFileName), true,
+	)
+	if err != nil {
+		return snapshotEntity, err
+	}
+
+	snapshotFilePath, err := repo.trailDbSvc.CreateSnapshot(
+		targetFilePath, createDto.CompressionFormat,
+	)
+	if err != nil {
+		return snapshotEntity, err
+	}
+
+	return repo.snapshotEntityFactory(snapshotFilePath.String())
+}
+
+func (repo *TrailDatabaseSnapshotRepo) Read(
+	snapshotsDirPath tkValueObject.UnixAbsoluteFilePath,
+) (snapshotEntities []tkEntity.TrailDatabaseSnapshot, err error) {
+	dirEntries, err := os.ReadDir(snapshotsDirPath.String())
+	if err != nil {
+		if errors.Is(err, os.ErrNotExist) {
+			return snapshotEntities, nil
+		}
+		return snapshotEntities, err
+	}
+
+	for _, dirEntry := range dirEntries {
+		if !dirEntry.Type().IsRegular() {
+			continue
+		}
+
+		snapshotEntity, err := repo.snapshotEntityFactory(
+			filepath.Join(snapshotsDirPath.String(), dirEntry.Name()),
+		)
+		if err != nil {
+			continue
+		}
+		snapshotEntities = append(snapshotEntities, snapshotEntity)
+	}
+
+	// The file names hold the creation time with microseconds, unlike CreatedAt.
+	slices.SortFunc(snapshotEntities, func(a, b tkEntity.TrailDatabaseSnapshot) int {
+		return strings.Compare(
+			filepath.Base(b.FilePath.String()), filepath.Base(a.FilePath.String()),
+		)
+	})
+
+	return snapshotEntities, nil
+}
+
+// Delete only removes snapshot files, refusing any other file.
+func (repo *TrailDatabaseSnapshotRepo) Delete(
+	snapshotFilePath tkValueObject.UnixAbsoluteFilePath,
+) error {
+	_, err := repo.snapshotCreatedAtParser(filepath.Base(snapshotFilePath.String()))
+	if err != nil {
+		return err
+	}
+
+	err = os.Remove(snapshotFilePath.String())
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+
+	return nil
+}
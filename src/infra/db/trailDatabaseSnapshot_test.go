@@ -0,0 +1,389 @@
+package tkInfraDb
+
+import (
+	"bytes"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfra "github.com/goinfinite/tk/src/infra"
+	tkInfraDbModel "github.com/goinfinite/tk/src/infra/db/model"
+)
+
+type snapshotExtraTestModel struct {
+	ID   uint `gorm:"primarykey"`
+	Name string
+}
+
+func TestTrailDatabaseSnapshot(t *testing.T) {
+	setupFileTrailDatabaseService := func(t *testing.T) *TrailDatabaseService {
+		t.Helper()
+		dbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+			Dsn: "sqlite://" + filepath.Join(t.TempDir(), "trail.db"),
+		})
+		if err != nil {
+			t.Fatalf("SetupTrailDatabaseServiceFailed: %v", err)
+		}
+		t.Cleanup(func() {
+			sqlDb, err := dbSvc.Handler.DB()
+			if err == nil {
+				_ = sqlDb.Close()
+			}
+		})
+		return dbSvc
+	}
+
+	createSnapshotTestRecord := func(t *testing.T, dbSvc *TrailDatabaseService) {
+		t.Helper()
+		recordModel := tkInfraDbModel.NewActivityRecord(
+			0, "INFO", "SnapshotTest", nil, nil, nil, nil,
+		)
+		err := dbSvc.Handler.Create(&recordModel).Error
+		if err != nil {
+			t.Fatalf("CreateRecordFailed: %v", err)
+		}
+	}
+
+	readRecordsCount := func(t *testing.T, dbSvc *TrailDatabaseService) int64 {
+		t.Helper()
+		var recordsCount int64
+		err := dbSvc.Handler.Model(&tkInfraDbModel.ActivityRecord{}).
+			Count(&recordsCount).Error
+		if err != nil {
+			t.Fatalf("CountRecordsFailed: %v", err)
+		}
+		return recordsCount
+	}
+
+	snapshotFilePathFactory := func(t *testing.T, fileName string) tkValueObject.UnixAbsoluteFilePath {
+		t.Helper()
+		return tkValueObject.UnixAbsoluteFilePath(filepath.Join(t.TempDir(), fileName))
+	}
+
+	gzipFormat := tkValueObject.CompressionFormatGzip
+	zipFormat := tkValueObject.CompressionFormatZip
+
+	t.Run("CreateSnapshot", func(t *testing.T) {
+		dbSvc := setupFileTrailDatabaseService(t)
+		createSnapshotTestRecord(t, dbSvc)
+
+		testCaseStructs := []struct {
+			name                 string
+			compressionFormatPtr *tkValueObject.CompressionFormat
+			expectedSuffix       string
+		}{
+			{"Uncompressed", nil, ".db"},
+			{"Gzip", &gzipFormat, ".db.gz"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				targetFilePath := snapshotFilePathFactory(t, "snapshot.db")
+				snapshotFilePath, err := dbSvc.CreateSnapshot(
+					targetFilePath, testCase.compressionFormatPtr,
+				)
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if !strings.HasSuffix(snapshotFilePath.String(), testCase.expectedSuffix) {
+					t.Errorf("UnexpectedSnapshotFilePath: %s", snapshotFilePath)
+				}
+				if _, err := os.Stat(snapshotFilePath.String()); err != nil {
+					t.Errorf("SnapshotFileNotCreated: %s", snapshotFilePath)
+				}
+				if testCase.compressionFormatPtr != nil {
+					if _, err := os.Stat(targetFilePath.String()); err == nil {
+						t.Errorf("UncompressedSnapshotLeftBehind: %s", targetFilePath)
+					}
+				}
+			})
+		}
+
+		t.Run("TargetExists", func(t *testing.T) {
+			targetFilePath := snapshotFilePathFactory(t, "snapshot.db")
+			_ = os.WriteFile(targetFilePath.String(), []byte("taken"), 0600)
+
+			_, err := dbSvc.CreateSnapshot(targetFilePath, nil)
+			if err == nil || err.Error() != errTrailDatabaseSnapshotTargetExists {
+				t.Errorf("MissingExpectedError: %s (got %v)", errTrailDatabaseSnapshotTargetExists, err)
+			}
+		})
+
+		t.Run("InjectedCommandRunner", func(t *testing.T) {
+			fakeCommandRunner := tkInfra.NewFakeCommandRunner(tkInfra.FakeCommandExpectation{
+				Command: "xz",
+				SideEffect: func(settings tkInfra.ShellSettings) error {
+					sourceFilePath := settings.Args[len(settings.Args)-1]
+					return os.Rename(sourceFilePath, sourceFilePath+".xz")
+				},
+			})
+			fakeDbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+				Dsn: TrailDatabaseInMemoryDsn, SnapshotCommandRunner: fakeCommandRunner,
+			})
+			if err != nil {
+				t.Fatalf("SetupTrailDatabaseServiceFailed: %v", err)
+			}
+
+			xzFormat := tkValueObject.CompressionFormatXz
+			snapshotFilePath, err := fakeDbSvc.CreateSnapshot(
+				snapshotFilePathFactory(t, "snapshot.db"), &xzFormat,
+			)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			if !strings.HasSuffix(snapshotFilePath.String(), ".db.xz") {
+				t.Errorf("UnexpectedSnapshotFilePath: %s", snapshotFilePath)
+			}
+			if len(fakeCommandRunner.ReadPendingExpectations()) != 0 {
+				t.Errorf("CommandRunnerNotUsed")
+			}
+		})
+
+		t.Run("DirCompressionFormat", func(t *testing.T) {
+			_, err := dbSvc.CreateSnapshot(snapshotFilePathFactory(t, "snapshot.db"), &zipFormat)
+			if err == nil || err.Error() != errTrailDatabaseSnapshotCompressionNotValid {
+				t.Errorf("MissingExpectedError: %s (got %v)", errTrailDatabaseSnapshotCompressionNotValid, err)
+			}
+		})
+	})
+
+	t.Run("RestoreSnapshot", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name                 string
+			compressionFormatPtr *tkValueObject.CompressionFormat
+		}{
+			{"Uncompressed", nil},
+			{"Gzip", &gzipFormat},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				dbSvc := setupFileTrailDatabaseService(t)
+				createSnapshotTestRecord(t, dbSvc)
+
+				snapshotFilePath, err := dbSvc.CreateSnapshot(
+					snapshotFilePathFactory(t, "snapshot.db"), testCase.compressionFormatPtr,
+				)
+				if err != nil {
+					t.Fatalf("CreateSnapshotFailed: %v", err)
+				}
+				createSnapshotTestRecord(t, dbSvc)
+
+				err = dbSvc.RestoreSnapshot(snapshotFilePath)
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if recordsCount := readRecordsCount(t, dbSvc); recordsCount != 1 {
+					t.Errorf("UnexpectedRecordsCount: %d", recordsCount)
+				}
+
+				createSnapshotTestRecord(t, dbSvc)
+				if recordsCount := readRecordsCount(t, dbSvc); recordsCount != 2 {
+					t.Errorf("RestoredDatabaseNotWritable: %d", recordsCount)
+				}
+			})
+		}
+
+		t.Run("InvalidSnapshots", func(t *testing.T) {
+			dbSvc := setupFileTrailDatabaseService(t)
+			createSnapshotTestRecord(t, dbSvc)
+
+			notDatabaseFilePath := snapshotFilePathFactory(t, "notDatabase.db")
+			_ = os.WriteFile(
+				notDatabaseFilePath.String(), []byte(strings.Repeat("garbage", 1024)), 0600,
+			)
+
+			emptyDbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+				Dsn: TrailDatabaseInMemoryDsn, ShouldSkipMigrations: true,
+			})
+			if err != nil {
+				t.Fatalf("SetupEmptyDatabaseFailed: %v", err)
+			}
+			noSchemaFilePath, err := emptyDbSvc.CreateSnapshot(
+				snapshotFilePathFactory(t, "noSchema.db"), nil,
+			)
+			if err != nil {
+				t.Fatalf("CreateSnapshotFailed: %v", err)
+			}
+
+			newerDbSvc := setupFileTrailDatabaseService(t)
+			err = newerDbSvc.Handler.Create(&tkInfraDbModel.SchemaMigration{
+				Namespace: "tk", Version: 999, Description: "FromTheFuture",
+				AppliedAt: time.Now().UTC(),
+			}).Error
+			if err != nil {
+				t.Fatalf("CreateNewerMigrationFailed: %v", err)
+			}
+			newerSchemaFilePath, err := newerDbSvc.CreateSnapshot(
+				snapshotFilePathFactory(t, "newerSchema.db"), nil,
+			)
+			if err != nil {
+				t.Fatalf("CreateSnapshotFailed: %v", err)
+			}
+
+			invalidSnapshotCaseStructs := []struct {
+				name             string
+				snapshotFilePath tkValueObject.UnixAbsoluteFilePath
+				expectedError    string
+			}{
+				{"Missing", snapshotFilePathFactory(t, "missing.db"), errTrailDatabaseSnapshotNotFound},
+				{"NotDatabase", notDatabaseFilePath, errTrailDatabaseSnapshotNotValid},
+				{"NoSchema", noSchemaFilePath, "SchemaMigrationsNotFound"},
+				{"NewerSchema", newerSchemaFilePath, "UnknownSchemaMigration: tk/999"},
+			}
+
+			for _, invalidSnapshotCase := range invalidSnapshotCaseStructs {
+				t.Run(invalidSnapshotCase.name, func(t *testing.T) {
+					err := dbSvc.RestoreSnapshot(invalidSnapshotCase.snapshotFilePath)
+					if err == nil || !strings.Contains(err.Error(), invalidSnapshotCase.expectedError) {
+						t.Errorf(
+							"MissingExpectedError: %s (got %v)",
+							invalidSnapshotCase.expectedError, err,
+						)
+					}
+					if recordsCount := readRecordsCount(t, dbSvc); recordsCount != 1 {
+						t.Errorf("DatabaseChangedByInvalidSnapshot: %d", recordsCount)
+					}
+				})
+			}
+		})
+
+		t.Run("OlderSnapshotMigratedOnceRestored", func(t *testing.T) {
+			olderDbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+				Dsn: TrailDatabaseInMemoryDsn, ShouldSkipMigrations: true,
+			})
+			if err != nil {
+				t.Fatalf("SetupOlderDatabaseFailed: %v", err)
+			}
+			olderMigrator, err := NewSchemaMigrator(SchemaMigratorSettings{
+				DbHandler: olderDbSvc.Handler, Migrations: TrailDatabaseMigrations()[:3],
+			})
+			if err != nil {
+				t.Fatalf("SetupOlderMigratorFailed: %v", err)
+			}
+			_, err = olderMigrator.Migrate()
+			if err != nil {
+				t.Fatalf("MigrateOlderDatabaseFailed: %v", err)
+			}
+			olderSnapshotFilePath, err := olderDbSvc.CreateSnapshot(
+				snapshotFilePathFactory(t, "olderSchema.db"), nil,
+			)
+			if err != nil {
+				t.Fatalf("CreateSnapshotFailed: %v", err)
+			}
+
+			dbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+				Dsn:             "sqlite://" + filepath.Join(t.TempDir(), "trail.db"),
+				ExtraModelsPtrs: []any{&snapshotExtraTestModel{}},
+			})
+			if err != nil {
+				t.Fatalf("SetupTrailDatabaseServiceFailed: %v", err)
+			}
+
+			olderSnapshotBytes, _ := os.ReadFile(olderSnapshotFilePath.String())
+			err = dbSvc.snapshotCandidateValidator(olderSnapshotFilePath.String())
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			validatedSnapshotBytes, _ := os.ReadFile(olderSnapshotFilePath.String())
+			if !bytes.Equal(olderSnapshotBytes, validatedSnapshotBytes) {
+				t.Errorf("SnapshotChangedByValidation")
+			}
+
+			err = dbSvc.RestoreSnapshot(olderSnapshotFilePath)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			pendingMigrationsStatus, err := dbSvc.SchemaMigrator.DryRun()
+			if err != nil || len(pendingMigrationsStatus) != 0 {
+				t.Errorf("PendingMigrationsLeft: %v %v", pendingMigrationsStatus, err)
+			}
+			for _, requiredModelPtr := range []any{
+				&tkInfraDbModel.ActivityRecordChainHead{}, &snapshotExtraTestModel{},
+			} {
+				if !dbSvc.Handler.Migrator().HasTable(requiredModelPtr) {
+					t.Errorf("RequiredTableNotFound: %T", requiredModelPtr)
+				}
+			}
+		})
+
+		t.Run("InMemory", func(t *testing.T) {
+			dbSvc, err := NewTrailDatabaseServiceWithSettings(TrailDatabaseServiceSettings{
+				Dsn: TrailDatabaseInMemoryDsn,
+			})
+			if err != nil {
+				t.Fatalf("SetupTrailDatabaseServiceFailed: %v", err)
+			}
+			snapshotFilePath, err := dbSvc.CreateSnapshot(
+				snapshotFilePathFactory(t, "snapshot.db"), nil,
+			)
+			if err != nil {
+				t.Fatalf("CreateSnapshotFailed: %v", err)
+			}
+
+			err = dbSvc.RestoreSnapshot(snapshotFilePath)
+			if err == nil || err.Error() != errTrailDatabaseSnapshotInMemoryNotRestorable {
+				t.Errorf("MissingExpectedError: %s (got %v)", errTrailDatabaseSnapshotInMemoryNotRestorable, err)
+			}
+		})
+	})
+
+	t.Run("RotateSnapshots", func(t *testing.T) {
+		dbSvc := setupFileTrailDatabaseService(t)
+		snapshotRepo := NewTrailDatabaseSnapshotRepo(dbSvc)
+		snapshotsDirPath := tkValueObject.UnixAbsoluteFilePath(t.TempDir())
+
+		unrelatedFilePath := filepath.Join(snapshotsDirPath.String(), "trail-manual.db")
+		_ = os.WriteFile(unrelatedFilePath, []byte("manual"), 0600)
+
+		rotateDto := tkDto.RotateTrailDatabaseSnapshotsRequest{
+			SnapshotsDirPath: snapshotsDirPath, CompressionFormat: &gzipFormat,
+			MaxSnapshotsCount: 2,
+		}
+		createdSnapshotsFilePaths := []tkValueObject.UnixAbsoluteFilePath{}
+		for range 3 {
+			responseDto, err := tkUseCase.RotateTrailDatabaseSnapshots(snapshotRepo, rotateDto)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			createdSnapshotsFilePaths = append(
+				createdSnapshotsFilePaths, responseDto.CreatedSnapshot.FilePath,
+			)
+		}
+
+		snapshots, err := snapshotRepo.Read(snapshotsDirPath)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if len(snapshots) != 2 {
+			t.Fatalf("UnexpectedSnapshotsCount: %d", len(snapshots))
+		}
+		if snapshots[0].FilePath != createdSnapshotsFilePaths[2] ||
+			snapshots[1].FilePath != createdSnapshotsFilePaths[1] {
+			t.Errorf("UnexpectedSnapshotsKept: %v", snapshots)
+		}
+		if _, err := os.Stat(createdSnapshotsFilePaths[0].String()); err == nil {
+			t.Errorf("OldestSnapshotNotDeleted: %s", createdSnapshotsFilePaths[0])
+		}
+		if _, err := os.Stat(unrelatedFilePath); err != nil {
+			t.Errorf("UnrelatedFileDeleted: %s", unrelatedFilePath)
+		}
+
+		err = snapshotRepo.Delete(tkValueObject.UnixAbsoluteFilePath(unrelatedFilePath))
+		if err == nil {
+			t.Errorf("MissingExpectedError: %s", errTrailDatabaseSnapshotFileNameNotValid)
+		}
+
+		_, err = tkUseCase.RotateTrailDatabaseSnapshots(
+			snapshotRepo, tkDto.RotateTrailDatabaseSnapshotsRequest{SnapshotsDirPath: snapshotsDirPath},
+		)
+		if err == nil {
+			t.Error("MissingExpectedError: MaxSnapshotsCountMustBePositive")
+		}
+	})
+}
@@ -0,0 +1,129 @@
+package tkInfra
+
+import (
+	"context"
+	"errors"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const errFakeCommandRunnerUnexpectedCommand string = "FakeCommandRunnerUnexpectedCommand"
+
+// FakeCommandExpectation scripts the result of a command. Args must match exactly,
+// unless nil. SideEffect, if any, runs before the result is returned, e.g. to create the
+// file the real command would. Without Err, a non-zero Result.ExitCode returns a
+// *ShellError, as Shell does.
+type FakeCommandExpectation struct {
+	Command      string
+	Args         []string
+	Result       ShellResult
+	Err          error
+	SideEffect   func(settings ShellSettings) error
+	IsRepeatable bool
+}
+
+// FakeCommandRunner returns the scripted result of the first pending expectation
+// matching each command, consuming it unless repeatable, and fails the commands no
+// expectation matches. It records every command received.
+type FakeCommandRunner struct {
+	mutex              sync.Mutex
+	expectations       []FakeCommandExpectation
+	consumedIndexesSet map[int]struct{}
+	receivedSettings   []ShellSettings
+}
+
+func NewFakeCommandRunner(expectations ...FakeCommandExpectation) *FakeCommandRunner {
+	return &FakeCommandRunner{
+		expectations:       expectations,
+		consumedIndexesSet: map[int]struct{}{},
+	}
+}
+
+func (runner *FakeCommandRunner) expectationIndexFinder(settings ShellSettings) int {
+	for expectationIndex, expectation := range runner.expectations {
+		if _, isConsumed := runner.consumedIndexesSet[expectationIndex]; isConsumed {
+			continue
+		}
+		if expectation.Command != settings.Command {
+			continue
+		}
+		if expectation.Args != nil && !slices.Equal(expectation.Args, settings.Args) {
+			continue
+		}
+		return expectationIndex
+	}
+
+	return -1
+}
+
+func (runner *FakeCommandRunner) RunCommand(
+	ctx context.Context, settings ShellSettings,
+) (shellResult ShellResult, err error) {
+	runner.mutex.Lock()
+	runner.receivedSettings = append(runner.receivedSettings, settings)
+	expectationIndex := runner.expectationIndexFinder(settings)
+	if expectationIndex < 0 {
+		runner.mutex.Unlock()
+		return ShellResult{ExitCode: -1}, errors.New(
+			errFakeCommandRunnerUnexpectedCommand + ": " +
+				strings.Join(append([]string{settings.Command}, settings.Args...), " "),
+		)
+	}
+	expectation := runner.expectations[expectationIndex]
+	if !expectation.IsRepeatable {
+		runner.consumedIndexesSet[expectationIndex] = struct{}{}
+	}
+	runner.mutex.Unlock()
+
+	if expectation.SideEffect != nil {
+		err = expectation.SideEffect(settings)
+		if err != nil {
+			return ShellResult{ExitCode: -1}, err
+		}
+	}
+
+	shellResult, err = commandRunnerOutputReplayer(settings, expectation.Result)
+	if err != nil {
+		return shellResult, err
+	}
+
+	if expectation.Err != nil {
+		return shellResult, expectation.Err
+	}
+	if shellResult.ExitCode != 0 {
+		return shellResult, &ShellError{
+			StdErr: shellResult.Stderr, ExitCode: shellResult.ExitCode,
+		}
+	}
+
+	return shellResult, nil
+}
+
+// ReadReceivedSettings returns the settings of every command run, in order.
+func (runner *FakeCommandRunner) ReadReceivedSettings() []ShellSettings {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	return slices.Clone(runner.receivedSettings)
+}
+
+// ReadPendingExpectations returns the non-repeatable expectations not consumed yet, so
+// the tests can assert every scripted command ran.
+func (runner *FakeCommandRunner) ReadPendingExpectations() []FakeCommandExpectation {
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+
+	pendingExpectations := []FakeCommandExpectation{}
+	for expectationIndex, expectation := range runner.expectations {
+		if expectation.IsRepeatable {
+			continue
+		}
+		if _, isConsumed := runner.consumedIndexesSet[expectationIndex]; isConsumed {
+			continue
+		}
+		pendingExpectations = append(pendingExpectations, expectation)
+	}
+
+	return pendingExpectations
+}
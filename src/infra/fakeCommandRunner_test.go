@@ -0,0 +1,119 @@
+package tkInfra
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestFakeCommandRunner(t *testing.T) {
+	t.Run("MatchAndConsume", func(t *testing.T) {
+		scriptedErr := errors.New("ScriptedError")
+		testCaseStructs := []struct {
+			name             string
+			command          string
+			args             []string
+			expectedStdout   string
+			expectedErrStart string
+		}{
+			{"ExactArgs", "hostname", []string{"-I"}, "10.0.0.5\n", ""},
+			{"AnyArgs", "uname", []string{"-a"}, "Linux\n", ""},
+			{"ExitCode", "false", nil, "", `{"stdErr":"failed\n","exitCode":1}`},
+			{"ScriptedErr", "id", nil, "", "ScriptedError"},
+			{"AlreadyConsumed", "hostname", []string{"-I"}, "", errFakeCommandRunnerUnexpectedCommand},
+			{"ArgsMismatch", "hostname", []string{"-f"}, "", errFakeCommandRunnerUnexpectedCommand},
+			{"UnexpectedCommand", "reboot", nil, "", errFakeCommandRunnerUnexpectedCommand},
+		}
+
+		fakeRunner := NewFakeCommandRunner(
+			FakeCommandExpectation{
+				Command: "hostname", Args: []string{"-I"},
+				Result: ShellResult{Stdout: "10.0.0.5\n"},
+			},
+			FakeCommandExpectation{Command: "uname", Result: ShellResult{Stdout: "Linux\n"}},
+			FakeCommandExpectation{
+				Command: "false", Result: ShellResult{Stderr: "failed\n", ExitCode: 1},
+			},
+			FakeCommandExpectation{Command: "id", Err: scriptedErr},
+		)
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				shellResult, err := fakeRunner.RunCommand(
+					context.Background(),
+					ShellSettings{Command: testCase.command, Args: testCase.args},
+				)
+				if testCase.expectedErrStart != "" {
+					if err == nil || !strings.HasPrefix(err.Error(), testCase.expectedErrStart) {
+						t.Errorf("MissingExpectedError: %s (%v)", testCase.expectedErrStart, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if shellResult.Stdout != testCase.expectedStdout {
+					t.Errorf("UnexpectedStdout: '%s'", shellResult.Stdout)
+				}
+			})
+		}
+
+		if len(fakeRunner.ReadPendingExpectations()) != 0 {
+			t.Errorf("UnexpectedPendingExpectations: %v", fakeRunner.ReadPendingExpectations())
+		}
+		if len(fakeRunner.ReadReceivedSettings()) != len(testCaseStructs) {
+			t.Errorf("UnexpectedReceivedCount: %d", len(fakeRunner.ReadReceivedSettings()))
+		}
+	})
+
+	t.Run("RepeatableAndPending", func(t *testing.T) {
+		fakeRunner := NewFakeCommandRunner(
+			FakeCommandExpectation{Command: "date", IsRepeatable: true},
+			FakeCommandExpectation{Command: "sync"},
+		)
+
+		for range 3 {
+			_, err := fakeRunner.RunCommand(context.Background(), ShellSettings{Command: "date"})
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+		}
+
+		pendingExpectations := fakeRunner.ReadPendingExpectations()
+		if len(pendingExpectations) != 1 || pendingExpectations[0].Command != "sync" {
+			t.Errorf("UnexpectedPendingExpectations: %v", pendingExpectations)
+		}
+	})
+
+	t.Run("OutputDestinations", func(t *testing.T) {
+		stdoutFilePath := filepath.Join(t.TempDir(), "stdout.txt")
+		fakeRunner := NewFakeCommandRunner(FakeCommandExpectation{
+			Command: "gzip",
+			Result:  ShellResult{Stdout: "decompressed", Stderr: "warn1\nwarn2\n"},
+		})
+
+		stderrLines := []string{}
+		shellResult, err := fakeRunner.RunCommand(context.Background(), ShellSettings{
+			Command:            "gzip",
+			StdoutFilePath:     stdoutFilePath,
+			StderrLineCallback: func(line string) { stderrLines = append(stderrLines, line) },
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if shellResult.Stdout != "" || shellResult.CommandLine != "gzip" {
+			t.Errorf("UnexpectedResult: %+v", shellResult)
+		}
+
+		stdoutFileContent, err := os.ReadFile(stdoutFilePath)
+		if err != nil || string(stdoutFileContent) != "decompressed" {
+			t.Errorf("UnexpectedStdoutFile: '%s' (%v)", stdoutFileContent, err)
+		}
+		if strings.Join(stderrLines, ",") != "warn1,warn2" {
+			t.Errorf("UnexpectedStderrLines: %v", stderrLines)
+		}
+	})
+}
@@ -2,6 +2,7 @@ package tkInfra
 
 import (
 	"bufio"
+	"context"
 	"errors"
 	"io"
 	"os"
@@ -25,7 +26,10 @@ var (
 	ErrUnsupportedCompressionFormat = errors.New("UnsupportedCompressionFormat")
 )
 
-type FileClerk struct{}
+// FileClerk.CommandRunner runs the compression commands, a Shell when nil.
+type FileClerk struct {
+	CommandRunner CommandRunner
+}
 
 func (FileClerk) FileExists(filePath string) bool {
 	_, err := os.Stat(filePath)
@@ -296,9 +300,10 @@ func (clerk FileClerk) CompressFile(
 	}
 
 	compressionArgs = append(compressionArgs, sourcePath)
-	_, err = NewShell(
+	_, err = commandRunnerOrDefault(clerk.CommandRunner).RunCommand(
+		context.Background(),
 		ShellSettings{Command: compressionCmd, Args: compressionArgs},
-	).Run()
+	)
 	if err != nil {
 		return compressedFilePath, err
 	}
@@ -359,21 +364,21 @@ func (clerk FileClerk) DecompressFile(
 		return decompressedFilePath, ErrUnsupportedCompressionFormat
 	}
 
-	shell := NewShell(
-		ShellSettings{
-			Command:          decompressionCmd,
-			Args:             decompressionArgs,
-			WorkingDirectory: filepath.Dir(sourcePath),
-		},
-	)
+	decompressionSettings := ShellSettings{
+		Command:          decompressionCmd,
+		Args:             decompressionArgs,
+		WorkingDirectory: filepath.Dir(sourcePath),
+	}
 	switch sourcePathExtNoDotStr {
 	case "gz", "gzip", "xz":
 		if targetPathPtr != nil {
-			shell.runtimeSettings.StdoutFilePath = *targetPathPtr
+			decompressionSettings.StdoutFilePath = *targetPathPtr
 		}
 	}
 
-	_, err = shell.Run()
+	_, err = commandRunnerOrDefault(clerk.CommandRunner).RunCommand(
+		context.Background(), decompressionSettings,
+	)
 	if err != nil {
 		return decompressedFilePath, err
 	}
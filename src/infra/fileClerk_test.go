@@ -1,6 +1,8 @@
 package tkInfra
 
 import (
+	"errors"
+	"os"
 	"path/filepath"
 	"strings"
 	"testing"
@@ -1213,6 +1215,55 @@ func TestCompressFile(t *testing.T) {
 			t.Errorf("WrongErrorMessage: '%s' vs '%s'", "SourceFileNotFound", err.Error())
 		}
 	})
+
+	t.Run("CompressWithFakeCommandRunner", func(t *testing.T) {
+		sourceFile := filepath.Join(tempDir, "fake_runner.txt")
+		err := clerk.CreateFile(sourceFile)
+		if err != nil {
+			t.Fatalf("CreateFileFailed: %v", err)
+		}
+
+		brFormat := "br"
+		fakeRunner := NewFakeCommandRunner(FakeCommandExpectation{
+			Command: "brotli",
+			Args:    []string{"--quality=4", "--rm", sourceFile},
+			SideEffect: func(settings ShellSettings) error {
+				return os.Rename(sourceFile, sourceFile+".br")
+			},
+		})
+
+		compressedFilePath, err := FileClerk{CommandRunner: fakeRunner}.CompressFile(
+			sourceFile, &brFormat,
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if compressedFilePath != sourceFile+".br" {
+			t.Errorf("UnexpectedCompressedFilePath: '%s'", compressedFilePath)
+		}
+		if len(fakeRunner.ReadPendingExpectations()) != 0 {
+			t.Error("PendingExpectations")
+		}
+	})
+
+	t.Run("CompressWithFailingCommandRunner", func(t *testing.T) {
+		sourceFile := filepath.Join(tempDir, "failing_runner.txt")
+		err := clerk.CreateFile(sourceFile)
+		if err != nil {
+			t.Fatalf("CreateFileFailed: %v", err)
+		}
+
+		fakeRunner := NewFakeCommandRunner(FakeCommandExpectation{
+			Command: "tar",
+			Result:  ShellResult{Stderr: "tar: write error", ExitCode: 2},
+		})
+
+		_, err = FileClerk{CommandRunner: fakeRunner}.CompressFile(sourceFile, nil)
+		shellErr := &ShellError{}
+		if !errors.As(err, &shellErr) || shellErr.ExitCode != 2 {
+			t.Errorf("MissingExpectedError: ShellError (%v)", err)
+		}
+	})
 }
 
 func TestDecompressFile(t *testing.T) {
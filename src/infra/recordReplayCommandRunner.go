@@ -0,0 +1,225 @@
+package tkInfra
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"os"
+	"path/filepath"
+	"slices"
+	"strings"
+	"sync"
+)
+
+const (
+	CommandRunnerRecordEnvVarName string = "COMMAND_RUNNER_RECORD"
+
+	errCommandRunnerFixtureReadError  string = "CommandRunnerFixtureReadError"
+	errCommandRunnerFixtureWriteError string = "CommandRunnerFixtureWriteError"
+	errCommandRunnerFixtureNotFound   string = "CommandRunnerFixtureNotFound"
+)
+
+// CommandRunnerFixture is a recorded command run. Err holds the error message, with
+// TerminationReason/TerminationSignal set when the command was terminated.
+type CommandRunnerFixture struct {
+	Command           string      `json:"command"`
+	Args              []string    `json:"args"`
+	Result            ShellResult `json:"result"`
+	Err               *string     `json:"err"`
+	TerminationReason *string     `json:"terminationReason"`
+	TerminationSignal *string     `json:"terminationSignal"`
+}
+
+// RecordReplayCommandRunnerSettings.ShouldRecord falls back to the
+// COMMAND_RUNNER_RECORD env var ("true"). When recording, every command runs through
+// Runner (a Shell by default) and the fixture file is rewritten with the runs so far,
+// merged into the fixtures it already had: the first run of a command and args replaces
+// their previous fixtures, the other commands' are kept, so recording a single test
+// doesn't wipe the others. Otherwise the fixture file is loaded and the commands are
+// answered from it, so CI doesn't need the binaries. ArgsNormalizer, if any, is applied to the args before
+// they're recorded or matched, e.g. to replace the temporary directories paths.
+type RecordReplayCommandRunnerSettings struct {
+	FixtureFilePath string
+	ShouldRecord    *bool
+	Runner          CommandRunner
+	ArgsNormalizer  func(rawArg string) string
+}
+
+// RecordReplayCommandRunner replays each fixture once, in the recorded order among the
+// fixtures of the same command and args. Only the output is replayed: the files the
+// recorded commands created or changed are not.
+type RecordReplayCommandRunner struct {
+	mutex              sync.Mutex
+	settings           RecordReplayCommandRunnerSettings
+	shouldRecord       bool
+	fixtures           []CommandRunnerFixture
+	consumedIndexesSet map[int]struct{}
+	recordedKeysSet    map[string]struct{}
+}
+
+func NewRecordReplayCommandRunner(
+	settings RecordReplayCommandRunnerSettings,
+) (*RecordReplayCommandRunner, error) {
+	shouldRecord := os.Getenv(CommandRunnerRecordEnvVarName) == "true"
+	if settings.ShouldRecord != nil {
+		shouldRecord = *settings.ShouldRecord
+	}
+	settings.Runner = commandRunnerOrDefault(settings.Runner)
+
+	runner := &RecordReplayCommandRunner{
+		settings:           settings,
+		shouldRecord:       shouldRecord,
+		fixtures:           []CommandRunnerFixture{},
+		consumedIndexesSet: map[int]struct{}{},
+		recordedKeysSet:    map[string]struct{}{},
+	}
+
+	fixturesBytes, err := os.ReadFile(settings.FixtureFilePath)
+	if err != nil {
+		if shouldRecord && errors.Is(err, os.ErrNotExist) {
+			return runner, nil
+		}
+		return nil, errors.New(errCommandRunnerFixtureReadError + ": " + err.Error())
+	}
+	err = json.Unmarshal(fixturesBytes, &runner.fixtures)
+	if err != nil {
+		return nil, errors.New(errCommandRunnerFixtureReadError + ": " + err.Error())
+	}
+
+	return runner, nil
+}
+
+func (runner *RecordReplayCommandRunner) argsNormalizer(rawArgs []string) []string {
+	if runner.settings.ArgsNormalizer == nil {
+		return rawArgs
+	}
+
+	normalizedArgs := make([]string, 0, len(rawArgs))
+	for _, rawArg := range rawArgs {
+		normalizedArgs = append(normalizedArgs, runner.settings.ArgsNormalizer(rawArg))
+	}
+	return normalizedArgs
+}
+
+func (fixture CommandRunnerFixture) key() string {
+	return strings.Join(append([]string{fixture.Command}, fixture.Args...), "\x00")
+}
+
+func (runner *RecordReplayCommandRunner) record(
+	ctx context.Context, settings ShellSettings,
+) (ShellResult, error) {
+	shellResult, err := runner.settings.Runner.RunCommand(ctx, settings)
+
+	fixture := CommandRunnerFixture{
+		Command: settings.Command,
+		Args:    runner.argsNormalizer(settings.Args),
+		Result:  shellResult,
+	}
+	if err != nil {
+		errMessage := err.Error()
+		fixture.Err = &errMessage
+
+		terminatedErr := &ShellTerminatedError{}
+		if errors.As(err, &terminatedErr) {
+			fixture.TerminationReason = &terminatedErr.Reason
+			fixture.TerminationSignal = &terminatedErr.Signal
+		}
+	}
+
+	runner.mutex.Lock()
+	defer runner.mutex.Unlock()
+	fixtureKey := fixture.key()
+	if _, isRecorded := runner.recordedKeysSet[fixtureKey]; !isRecorded {
+		runner.recordedKeysSet[fixtureKey] = struct{}{}
+		runner.fixtures = slices.DeleteFunc(
+			runner.fixtures, func(previousFixture CommandRunnerFixture) bool {
+				return previousFixture.key() == fixtureKey
+			},
+		)
+	}
+	runner.fixtures = append(runner.fixtures, fixture)
+
+	fixturesBytes, marshalErr := json.MarshalIndent(runner.fixtures, "", "  ")
+	if marshalErr != nil {
+		return shellResult, errors.New(errCommandRunnerFixtureWriteError + ": " + marshalErr.Error())
+	}
+	writeErr := os.MkdirAll(filepath.Dir(runner.settings.FixtureFilePath), 0755)
+	if writeErr == nil {
+		writeErr = os.WriteFile(runner.settings.FixtureFilePath, fixturesBytes, 0644)
+	}
+	if writeErr != nil {
+		return shellResult, errors.New(errCommandRunnerFixtureWriteError + ": " + writeErr.Error())
+	}
+
+	return shellResult, err
+}
+
+// replayErrFactory rebuilds the recorded error with the type Shell returned.
+func (fixture CommandRunnerFixture) replayErrFactory() error {
+	if fixture.Err == nil {
+		return nil
+	}
+
+	if fixture.TerminationReason != nil && fixture.TerminationSignal != nil {
+		terminationCause := context.DeadlineExceeded
+		if *fixture.TerminationReason == "CommandCanceled" {
+			terminationCause = context.Canceled
+		}
+		return &ShellTerminatedError{
+			StdErr: fixture.Result.Stderr,
+			Reason: *fixture.TerminationReason,
+			Signal: *fixture.TerminationSignal,
+			cause:  terminationCause,
+		}
+	}
+
+	shellErr := &ShellError{}
+	if json.Unmarshal([]byte(*fixture.Err), shellErr) == nil && shellErr.ExitCode != 0 {
+		return shellErr
+	}
+
+	return errors.New(*fixture.Err)
+}
+
+func (runner *RecordReplayCommandRunner) replay(settings ShellSettings) (ShellResult, error) {
+	normalizedArgs := runner.argsNormalizer(settings.Args)
+
+	runner.mutex.Lock()
+	fixtureIndex := -1
+	for candidateIndex, fixture := range runner.fixtures {
+		if _, isConsumed := runner.consumedIndexesSet[candidateIndex]; isConsumed {
+			continue
+		}
+		if fixture.Command != settings.Command || !slices.Equal(fixture.Args, normalizedArgs) {
+			continue
+		}
+		fixtureIndex = candidateIndex
+		break
+	}
+	if fixtureIndex < 0 {
+		runner.mutex.Unlock()
+		return ShellResult{ExitCode: -1}, errors.New(
+			errCommandRunnerFixtureNotFound + ": " +
+				strings.Join(append([]string{settings.Command}, normalizedArgs...), " "),
+		)
+	}
+	runner.consumedIndexesSet[fixtureIndex] = struct{}{}
+	fixture := runner.fixtures[fixtureIndex]
+	runner.mutex.Unlock()
+
+	shellResult, err := commandRunnerOutputReplayer(settings, fixture.Result)
+	if err != nil {
+		return shellResult, err
+	}
+
+	return shellResult, fixture.replayErrFactory()
+}
+
+func (runner *RecordReplayCommandRunner) RunCommand(
+	ctx context.Context, settings ShellSettings,
+) (ShellResult, error) {
+	if runner.shouldRecord {
+		return runner.record(ctx, settings)
+	}
+	return runner.replay(settings)
+}
@@ -0,0 +1,132 @@
+package tkInfra
+
+import (
+	"context"
+	"errors"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestRecordReplayCommandRunner(t *testing.T) {
+	fixtureFilePath := filepath.Join(t.TempDir(), "fixtures", "commands.json")
+	workDir := t.TempDir()
+	argsNormalizer := func(rawArg string) string {
+		return strings.ReplaceAll(rawArg, workDir, "$WORK_DIR")
+	}
+
+	commandSettings := []ShellSettings{
+		{Command: "date", Args: []string{"+%s%N"}},
+		{Command: "date", Args: []string{"+%s%N"}},
+		{Command: "ls", Args: []string{workDir + "/missing"}},
+	}
+
+	t.Run("Record", func(t *testing.T) {
+		shouldRecord := true
+		recordRunner, err := NewRecordReplayCommandRunner(RecordReplayCommandRunnerSettings{
+			FixtureFilePath: fixtureFilePath,
+			ShouldRecord:    &shouldRecord,
+			ArgsNormalizer:  argsNormalizer,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		for _, settings := range commandSettings[:2] {
+			_, err = recordRunner.RunCommand(context.Background(), settings)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+		}
+		_, err = recordRunner.RunCommand(context.Background(), commandSettings[2])
+		if err == nil {
+			t.Fatal("MissingExpectedError: ShellError")
+		}
+	})
+
+	t.Run("Replay", func(t *testing.T) {
+		t.Setenv(CommandRunnerRecordEnvVarName, "")
+		replayRunner, err := NewRecordReplayCommandRunner(RecordReplayCommandRunnerSettings{
+			FixtureFilePath: fixtureFilePath,
+			ArgsNormalizer:  argsNormalizer,
+			Runner:          NewFakeCommandRunner(),
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+
+		firstResult, err := replayRunner.RunCommand(context.Background(), commandSettings[0])
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		secondResult, err := replayRunner.RunCommand(context.Background(), commandSettings[1])
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if firstResult.Stdout == "" || firstResult.Stdout == secondResult.Stdout {
+			t.Errorf("UnexpectedReplayOrder: '%s' '%s'", firstResult.Stdout, secondResult.Stdout)
+		}
+
+		_, err = replayRunner.RunCommand(context.Background(), commandSettings[2])
+		shellErr := &ShellError{}
+		if !errors.As(err, &shellErr) || shellErr.ExitCode == 0 || shellErr.StdErr == "" {
+			t.Errorf("MissingExpectedError: ShellError (%v)", err)
+		}
+
+		_, err = replayRunner.RunCommand(context.Background(), commandSettings[0])
+		if err == nil || !strings.HasPrefix(err.Error(), errCommandRunnerFixtureNotFound) {
+			t.Errorf("MissingExpectedError: %s (%v)", errCommandRunnerFixtureNotFound, err)
+		}
+	})
+
+	t.Run("RecordMergesExistingFixtures", func(t *testing.T) {
+		shouldRecord := true
+		recordRunner, err := NewRecordReplayCommandRunner(RecordReplayCommandRunnerSettings{
+			FixtureFilePath: fixtureFilePath,
+			ShouldRecord:    &shouldRecord,
+			ArgsNormalizer:  argsNormalizer,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		_, err = recordRunner.RunCommand(context.Background(), commandSettings[2])
+		if err == nil {
+			t.Fatal("MissingExpectedError: ShellError")
+		}
+
+		shouldRecord = false
+		replayRunner, err := NewRecordReplayCommandRunner(RecordReplayCommandRunnerSettings{
+			FixtureFilePath: fixtureFilePath,
+			ShouldRecord:    &shouldRecord,
+			ArgsNormalizer:  argsNormalizer,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		for _, settings := range commandSettings[:2] {
+			_, err = replayRunner.RunCommand(context.Background(), settings)
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+		}
+		_, err = replayRunner.RunCommand(context.Background(), commandSettings[2])
+		if err == nil || strings.HasPrefix(err.Error(), errCommandRunnerFixtureNotFound) {
+			t.Errorf("MissingExpectedError: ShellError (%v)", err)
+		}
+		_, err = replayRunner.RunCommand(context.Background(), commandSettings[2])
+		if err == nil || !strings.HasPrefix(err.Error(), errCommandRunnerFixtureNotFound) {
+			t.Errorf("MissingExpectedError: %s (%v)", errCommandRunnerFixtureNotFound, err)
+		}
+	})
+
+	t.Run("MissingFixtureFile", func(t *testing.T) {
+		shouldRecord := false
+		_, err := NewRecordReplayCommandRunner(RecordReplayCommandRunnerSettings{
+			FixtureFilePath: filepath.Join(t.TempDir(), "missing.json"),
+			ShouldRecord:    &shouldRecord,
+		})
+		if err == nil {
+			t.Error("MissingExpectedError: CommandRunnerFixtureReadError")
+		}
+	})
+}
@@ -124,12 +124,21 @@ func (resolver *PublicIpAddressResolver) Resolve() (
 }
 
 func ReadServerPrivateIpAddress() (ipAddress tkValueObject.IpAddress, err error) {
-	rawIpAddress, err := NewShell(
-		ShellSettings{Command: "hostname", Args: []string{"-I"}},
-	).Run()
+	return ReadServerPrivateIpAddressWithRunner(Shell{})
+}
+
+// ReadServerPrivateIpAddressWithRunner reads the first "hostname -I" address through
+// the given CommandRunner.
+func ReadServerPrivateIpAddressWithRunner(
+	commandRunner CommandRunner,
+) (ipAddress tkValueObject.IpAddress, err error) {
+	shellResult, err := commandRunnerOrDefault(commandRunner).RunCommand(
+		context.Background(), ShellSettings{Command: "hostname", Args: []string{"-I"}},
+	)
 	if err != nil {
 		return ipAddress, err
 	}
+	rawIpAddress := strings.TrimSpace(shellResult.Stdout)
 
 	rawIpAddresses := strings.Split(rawIpAddress, " ")
 	if len(rawIpAddresses) > 0 {
@@ -25,6 +25,41 @@ func TestServerIpAddress(t *testing.T) {
 		}
 	})
 
+	t.Run("PrivateIpAddressWithFakeCommandRunner", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name          string
+			rawStdout     string
+			expectedIp    string
+			shouldSucceed bool
+		}{
+			{"FirstAddress", "10.0.0.5 172.17.0.1 \n", "10.0.0.5", true},
+			{"NoAddress", " \n", "", false},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				fakeRunner := NewFakeCommandRunner(FakeCommandExpectation{
+					Command: "hostname", Args: []string{"-I"},
+					Result: ShellResult{Stdout: testCase.rawStdout},
+				})
+
+				ipAddress, err := ReadServerPrivateIpAddressWithRunner(fakeRunner)
+				if !testCase.shouldSucceed {
+					if err == nil {
+						t.Error("MissingExpectedError: PrivateIpAddressNotFound")
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if ipAddress.String() != testCase.expectedIp {
+					t.Errorf("UnexpectedIpAddress: '%s'", ipAddress.String())
+				}
+			})
+		}
+	})
+
 	t.Run("PublicIpAddressEnvVarTakesPriority", func(t *testing.T) {
 		rawEnvIpAddress := "203.0.113.42"
 		t.Setenv(ServerPublicIpAddressEnvVarName, rawEnvIpAddress)
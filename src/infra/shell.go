@@ -1,8 +1,10 @@
 package tkInfra
 
 import (
-	"bytes"
+	"context"
 	"encoding/json"
+	"errors"
+	"io"
 	"os"
 	"os/exec"
 	"os/user"
@@ -10,12 +12,40 @@ import (
 	"strconv"
 	"strings"
 	"syscall"
+	"time"
+
+	"golang.org/x/sys/unix"
+)
+
+const (
+	ShellExecutionTimeoutSecsDefault   uint64        = 1800
+	ShellExecutionTimeoutSecsHardLimit uint64        = 3600
+	ShellTerminationGracePeriodDefault time.Duration = 5 * time.Second
+	ShellStderrTailMaxBytesDefault     uint64        = 64 * 1024
 )
 
 type Shell struct {
 	runtimeSettings ShellSettings
 }
 
+// ShellSettings.ExecutionTimeoutSecs defaults to ShellExecutionTimeoutSecsDefault and
+// is capped to ShellExecutionTimeoutSecsHardLimit unless ShouldDisableTimeoutHardLimit
+// is set. Once the timeout (or the RunWithContext context) expires, the command process
+// group receives SIGTERM and, TerminationGracePeriod later, SIGKILL.
+//
+// Stdin feeds the command until it reaches EOF or the command exits, whichever comes
+// first; a Stdin that never reaches EOF doesn't hold the run, but its copying goroutine
+// lingers until its Read returns. Once the command exited, copying the output gets
+// TerminationGracePeriod to finish; then the pipes are closed and, if the command
+// succeeded, exec.ErrWaitDelay is returned, e.g. when a background process keeps the
+// output open.
+// The output is captured in memory (stdout unless StdoutFilePath is set) and also
+// copied to the StdoutWriter/StderrWriter tees and, line by line, to the line callbacks,
+// which run on the output copying goroutines, stdout and stderr concurrently.
+// MaxBufferedBytes caps each in-memory buffer, keeping the output tail; stderr is
+// always kept, up to ShellStderrTailMaxBytesDefault by default, so errors carry its tail.
+//
+// ExecutionPolicy optionally restricts the commands and args (see ShellExecutionPolicy).
 type ShellSettings struct {
 	Command                         string
 	Args                            []string
@@ -25,9 +55,17 @@ type ShellSettings struct {
 	Username                        string
 	WorkingDirectory                string
 	ExecutionTimeoutSecs            uint64
+	TerminationGracePeriod          time.Duration
 	Envs                            []string
 	StdoutFilePath                  string
 	StderrFilePath                  string
+	Stdin                           io.Reader
+	StdoutWriter                    io.Writer
+	StderrWriter                    io.Writer
+	StdoutLineCallback              func(line string)
+	StderrLineCallback              func(line string)
+	MaxBufferedBytes                uint64
+	ExecutionPolicy                 *ShellExecutionPolicy
 }
 
 func NewShell(settings ShellSettings) Shell {
@@ -44,6 +82,25 @@ func (e *ShellError) Error() string {
 	return string(jsonError)
 }
 
+// ShellTerminatedError is returned when the command was terminated because its
+// deadline was exceeded or its context canceled, whatever its exit code. It unwraps to
+// context.DeadlineExceeded or context.Canceled.
+type ShellTerminatedError struct {
+	StdErr string `json:"stdErr"`
+	Reason string `json:"reason"`
+	Signal string `json:"signal"`
+	cause  error
+}
+
+func (e *ShellTerminatedError) Error() string {
+	jsonError, _ := json.Marshal(e)
+	return string(jsonError)
+}
+
+func (e *ShellTerminatedError) Unwrap() error {
+	return e.cause
+}
+
 func (shell Shell) sysCallCredentialsFactory() (*syscall.Credential, error) {
 	userStruct, err := user.Lookup(shell.runtimeSettings.Username)
 	if err != nil {
@@ -66,50 +123,47 @@ func (shell Shell) sysCallCredentialsFactory() (*syscall.Credential, error) {
 
 type preparedExec struct {
 	ExecCmd           *exec.Cmd
-	StdoutBytesBuffer *bytes.Buffer
+	StdinPipe         io.WriteCloser
+	StdoutTailBuffer  *shellTailBuffer
 	StdoutFileHandler *os.File
-	StderrBytesBuffer *bytes.Buffer
+	StdoutLineWriter  *shellLineWriter
+	StderrTailBuffer  *shellTailBuffer
 	StderrFileHandler *os.File
+	StderrLineWriter  *shellLineWriter
 	Err               error
 }
 
 func (shell Shell) prepareExec() preparedExec {
-	if shell.runtimeSettings.ShouldUseSubShell {
-		subShellCmd := shell.runtimeSettings.Command + " " +
-			strings.Join(shell.runtimeSettings.Args, " ")
-		subShellArgs := []string{"-c", "source /etc/profile; " + subShellCmd}
-		shell.runtimeSettings.Command = "bash"
-		shell.runtimeSettings.Args = subShellArgs
-	}
-
-	timeoutSecsDefault := uint64(1800)
-	if shell.runtimeSettings.ExecutionTimeoutSecs == 0 {
-		shell.runtimeSettings.ExecutionTimeoutSecs = timeoutSecsDefault
+	executionPolicy := shell.runtimeSettings.ExecutionPolicy
+	if executionPolicy != nil {
+		err := executionPolicy.validate(shell.runtimeSettings)
+		if err != nil {
+			return preparedExec{Err: err}
+		}
 	}
 
-	timeoutSecsHardLimit := uint64(3600)
-	if shell.runtimeSettings.ExecutionTimeoutSecs > timeoutSecsHardLimit &&
-		!shell.runtimeSettings.ShouldDisableTimeoutHardLimit {
-		shell.runtimeSettings.ExecutionTimeoutSecs = timeoutSecsHardLimit
+	if shell.runtimeSettings.ShouldUseSubShell {
+		subShellArgs := shell.runtimeSettings.Args
+		if executionPolicy != nil {
+			subShellArgs = executionPolicy.subShellArgsQuoter(subShellArgs)
+		}
+		subShellCmd := shell.runtimeSettings.Command + " " + strings.Join(subShellArgs, " ")
+		shell.runtimeSettings.Command = "bash"
+		shell.runtimeSettings.Args = []string{"-c", "source /etc/profile; " + subShellCmd}
 	}
 
-	timeoutSecsStr := strconv.FormatUint(shell.runtimeSettings.ExecutionTimeoutSecs, 10)
-
-	timeoutArgs := []string{timeoutSecsStr, shell.runtimeSettings.Command}
-	timeoutArgs = slices.Concat(timeoutArgs, shell.runtimeSettings.Args)
-	shell.runtimeSettings.Command = "timeout"
-	shell.runtimeSettings.Args = timeoutArgs
-
 	execCmd := exec.Command(
 		shell.runtimeSettings.Command, shell.runtimeSettings.Args...,
 	)
+	// Own process group, so the termination signals reach the grandchildren too.
+	execCmd.SysProcAttr = &syscall.SysProcAttr{Setpgid: true}
 	if shell.runtimeSettings.Username != "" {
 		sysCallCredentials, err := shell.sysCallCredentialsFactory()
 		if err != nil && !shell.runtimeSettings.ShouldIgnoreUsernameLookupError {
 			return preparedExec{Err: err}
 		}
 		if err == nil {
-			execCmd.SysProcAttr = &syscall.SysProcAttr{Credential: sysCallCredentials}
+			execCmd.SysProcAttr.Credential = sysCallCredentials
 		}
 	}
 
@@ -117,68 +171,316 @@ func (shell Shell) prepareExec() preparedExec {
 		execCmd.Dir = shell.runtimeSettings.WorkingDirectory
 	}
 
-	var stdoutBytesBuffer bytes.Buffer
-	execCmd.Stdout = &stdoutBytesBuffer
+	// Otherwise Wait blocks until the output pipes close, even after the command exited.
+	execCmd.WaitDelay = shell.terminationGracePeriodFactory()
+
+	stdoutTailBuffer := newShellTailBuffer(shell.runtimeSettings.MaxBufferedBytes)
+	stdoutWriters := []io.Writer{stdoutTailBuffer}
+	var stdoutFileHandler *os.File
 	if shell.runtimeSettings.StdoutFilePath != "" {
-		stdoutFileHandler, err := os.Create(shell.runtimeSettings.StdoutFilePath)
+		var err error
+		stdoutFileHandler, err = os.Create(shell.runtimeSettings.StdoutFilePath)
 		if err != nil {
 			return preparedExec{Err: err}
 		}
-		execCmd.Stdout = stdoutFileHandler
+		stdoutWriters = []io.Writer{stdoutFileHandler}
 	}
 
-	var stderrBytesBuffer bytes.Buffer
-	execCmd.Stderr = &stderrBytesBuffer
+	stderrTailMaxBytes := shell.runtimeSettings.MaxBufferedBytes
+	if stderrTailMaxBytes == 0 {
+		stderrTailMaxBytes = ShellStderrTailMaxBytesDefault
+	}
+	stderrTailBuffer := newShellTailBuffer(stderrTailMaxBytes)
+	stderrWriters := []io.Writer{stderrTailBuffer}
+	var stderrFileHandler *os.File
 	if shell.runtimeSettings.StderrFilePath != "" {
-		stderrFileHandler, err := os.Create(shell.runtimeSettings.StderrFilePath)
+		var err error
+		stderrFileHandler, err = os.Create(shell.runtimeSettings.StderrFilePath)
 		if err != nil {
+			if stdoutFileHandler != nil {
+				stdoutFileHandler.Close()
+			}
 			return preparedExec{Err: err}
 		}
-		execCmd.Stderr = stderrFileHandler
+		stderrWriters = append(stderrWriters, stderrFileHandler)
+	}
+
+	if shell.runtimeSettings.StdoutWriter != nil {
+		stdoutWriters = append(stdoutWriters, shell.runtimeSettings.StdoutWriter)
+	}
+	if shell.runtimeSettings.StderrWriter != nil {
+		stderrWriters = append(stderrWriters, shell.runtimeSettings.StderrWriter)
+	}
+
+	var stdoutLineWriter, stderrLineWriter *shellLineWriter
+	if shell.runtimeSettings.StdoutLineCallback != nil {
+		stdoutLineWriter = newShellLineWriter(shell.runtimeSettings.StdoutLineCallback)
+		stdoutWriters = append(stdoutWriters, stdoutLineWriter)
+	}
+	if shell.runtimeSettings.StderrLineCallback != nil {
+		stderrLineWriter = newShellLineWriter(shell.runtimeSettings.StderrLineCallback)
+		stderrWriters = append(stderrWriters, stderrLineWriter)
+	}
+
+	// A single *os.File is handed to the command as is, sparing the copying goroutine.
+	execCmd.Stdout = stdoutWriters[0]
+	if len(stdoutWriters) > 1 {
+		execCmd.Stdout = io.MultiWriter(stdoutWriters...)
 	}
+	execCmd.Stderr = io.MultiWriter(stderrWriters...)
 
 	execCmd.Env = append(execCmd.Environ(), "DEBIAN_FRONTEND=noninteractive")
 	execCmd.Env = slices.Concat(execCmd.Env, shell.runtimeSettings.Envs)
 
+	// Files are handed to the command as is. Other readers are copied by RunDetailed,
+	// since the copy done by exec holds Wait until they reach EOF, whatever WaitDelay.
+	var stdinPipe io.WriteCloser
+	_, isStdinFile := shell.runtimeSettings.Stdin.(*os.File)
+	if shell.runtimeSettings.Stdin == nil || isStdinFile {
+		execCmd.Stdin = shell.runtimeSettings.Stdin
+	} else {
+		var err error
+		stdinPipe, err = execCmd.StdinPipe()
+		if err != nil {
+			for _, fileHandler := range []*os.File{stdoutFileHandler, stderrFileHandler} {
+				if fileHandler != nil {
+					fileHandler.Close()
+				}
+			}
+			return preparedExec{Err: err}
+		}
+	}
+
 	return preparedExec{
 		ExecCmd:           execCmd,
-		StdoutBytesBuffer: &stdoutBytesBuffer,
-		StderrBytesBuffer: &stderrBytesBuffer,
+		StdinPipe:         stdinPipe,
+		StdoutTailBuffer:  stdoutTailBuffer,
+		StdoutFileHandler: stdoutFileHandler,
+		StdoutLineWriter:  stdoutLineWriter,
+		StderrTailBuffer:  stderrTailBuffer,
+		StderrFileHandler: stderrFileHandler,
+		StderrLineWriter:  stderrLineWriter,
+	}
+}
+
+func (shell Shell) executionTimeoutFactory() time.Duration {
+	executionTimeoutSecs := shell.runtimeSettings.ExecutionTimeoutSecs
+	if executionTimeoutSecs == 0 {
+		executionTimeoutSecs = ShellExecutionTimeoutSecsDefault
+	}
+
+	if executionTimeoutSecs > ShellExecutionTimeoutSecsHardLimit &&
+		!shell.runtimeSettings.ShouldDisableTimeoutHardLimit {
+		executionTimeoutSecs = ShellExecutionTimeoutSecsHardLimit
 	}
+
+	return time.Duration(executionTimeoutSecs) * time.Second
+}
+
+func (shell Shell) terminationGracePeriodFactory() time.Duration {
+	if shell.runtimeSettings.TerminationGracePeriod <= 0 {
+		return ShellTerminationGracePeriodDefault
+	}
+	return shell.runtimeSettings.TerminationGracePeriod
+}
+
+// processGroupTerminator signals the command process group once the context is done:
+// SIGTERM first and SIGKILL when it's still running after the grace period. It returns
+// the name of the last signal sent, if any, once the command exited. Waiting for the
+// exit after SIGKILL is bounded by the exec WaitDelay, which closes the pipes a process
+// that left the group (e.g. via setsid) may keep open.
+//
+// The context may be done while Wait still reads the pipes of a command which already
+// exited, held open by its background children: those are signaled too, but nothing is
+// returned since the command itself completed.
+func (shell Shell) processGroupTerminator(
+	runCtx context.Context, commandProcess *os.Process, commandExited <-chan struct{},
+) (lastSignalSent <-chan string) {
+	terminationGracePeriod := shell.terminationGracePeriodFactory()
+
+	lastSignalChan := make(chan string, 1)
+	go func() {
+		defer close(lastSignalChan)
+
+		select {
+		case <-commandExited:
+			return
+		case <-runCtx.Done():
+		}
+
+		select {
+		case <-commandExited:
+			return
+		default:
+		}
+		// Once Wait reaped the command, signaling it fails with os.ErrProcessDone.
+		isCommandRunning := commandProcess.Signal(syscall.Signal(0)) == nil
+
+		processGroupId := commandProcess.Pid
+		_ = syscall.Kill(-processGroupId, syscall.SIGTERM)
+		gracePeriodTimer := time.NewTimer(terminationGracePeriod)
+		defer gracePeriodTimer.Stop()
+
+		lastSignal := "SIGTERM"
+		select {
+		case <-commandExited:
+		case <-gracePeriodTimer.C:
+			_ = syscall.Kill(-processGroupId, syscall.SIGKILL)
+			<-commandExited
+			lastSignal = "SIGKILL"
+		}
+		if isCommandRunning {
+			lastSignalChan <- lastSignal
+		}
+	}()
+
+	return lastSignalChan
 }
 
 func (shell Shell) Run() (stdoutStr string, err error) {
+	return shell.RunWithContext(context.Background())
+}
+
+// RunWithContext runs the command until it exits, ExecutionTimeoutSecs elapses or the
+// context is done, whichever comes first. In the latter two cases the whole process
+// group is terminated and a *ShellTerminatedError returned.
+func (shell Shell) RunWithContext(ctx context.Context) (stdoutStr string, err error) {
+	shellResult, err := shell.RunDetailed(ctx)
+	return strings.TrimSpace(shellResult.Stdout), err
+}
+
+// ShellResult describes a command run and is JSON-serializable, so it can be stored as
+// activity record details. Stdout and Stderr are the captured (possibly truncated)
+// output tails, untrimmed. ExitCode is -1 when the command didn't start or was killed by
+// the Signal. DurationMs is in milliseconds.
+type ShellResult struct {
+	CommandLine       string    `json:"commandLine"`
+	Stdout            string    `json:"stdout"`
+	Stderr            string    `json:"stderr"`
+	ExitCode          int       `json:"exitCode"`
+	Signal            *string   `json:"signal"`
+	StartedAt         time.Time `json:"startedAt"`
+	FinishedAt        time.Time `json:"finishedAt"`
+	DurationMs        int64     `json:"durationMs"`
+	IsStdoutTruncated bool      `json:"isStdoutTruncated"`
+	IsStderrTruncated bool      `json:"isStderrTruncated"`
+}
+
+// commandLineFactory returns the command line actually executed (subshell wrapping and
+// executable path resolution included), each argument quoted.
+func (preparedExec preparedExec) commandLineFactory() string {
+	quotedArgs := make([]string, 0, len(preparedExec.ExecCmd.Args))
+	for argIndex, rawArg := range preparedExec.ExecCmd.Args {
+		if argIndex == 0 {
+			rawArg = preparedExec.ExecCmd.Path
+		}
+		quotedArgs = append(quotedArgs, ShellEscape{}.Quote(rawArg))
+	}
+	return strings.Join(quotedArgs, " ")
+}
+
+// RunDetailed runs the command like RunWithContext, returning the whole ShellResult,
+// which is also filled when an error is returned (a *ShellError when the command exits
+// with a non-zero code, unless it was terminated). The ExecutionPolicy AuditHook, if
+// any, is called once it returns.
+func (shell Shell) RunDetailed(ctx context.Context) (shellResult ShellResult, err error) {
+	shellResult.ExitCode = -1
+	executionPolicy := shell.runtimeSettings.ExecutionPolicy
+	if executionPolicy != nil && executionPolicy.AuditHook != nil {
+		defer func() {
+			executionPolicy.AuditHook(shell.auditEntryFactory(shellResult, err))
+		}()
+	}
+
 	preparedExec := shell.prepareExec()
 	if preparedExec.Err != nil {
-		return stdoutStr, preparedExec.Err
+		return shellResult, preparedExec.Err
 	}
+	defer func() {
+		if preparedExec.StdoutFileHandler != nil {
+			preparedExec.StdoutFileHandler.Close()
+		}
+		if preparedExec.StderrFileHandler != nil {
+			preparedExec.StderrFileHandler.Close()
+		}
+	}()
+	shellResult.CommandLine = preparedExec.commandLineFactory()
+
+	runCtx, cancelRunCtx := context.WithTimeout(ctx, shell.executionTimeoutFactory())
+	defer cancelRunCtx()
 
-	preparedExec.Err = preparedExec.ExecCmd.Run()
-	if preparedExec.StdoutFileHandler != nil {
-		preparedExec.StdoutFileHandler.Close()
+	shellResult.StartedAt = time.Now().UTC()
+	err = preparedExec.ExecCmd.Start()
+	if err != nil {
+		shellResult.FinishedAt = time.Now().UTC()
+		return shellResult, err
 	}
-	if preparedExec.StderrFileHandler != nil {
-		preparedExec.StderrFileHandler.Close()
+
+	if preparedExec.StdinPipe != nil {
+		go func(stdinReader io.Reader, stdinPipe io.WriteCloser) {
+			_, _ = io.Copy(stdinPipe, stdinReader)
+			_ = stdinPipe.Close()
+		}(shell.runtimeSettings.Stdin, preparedExec.StdinPipe)
 	}
 
-	if preparedExec.StdoutBytesBuffer != nil {
-		stdoutStr = strings.TrimSpace(preparedExec.StdoutBytesBuffer.String())
+	commandExited := make(chan struct{})
+	lastSignalSent := shell.processGroupTerminator(
+		runCtx, preparedExec.ExecCmd.Process, commandExited,
+	)
+	waitErr := preparedExec.ExecCmd.Wait()
+	close(commandExited)
+	terminationSignal, wasTerminated := <-lastSignalSent
+
+	shellResult.FinishedAt = time.Now().UTC()
+	shellResult.DurationMs = shellResult.FinishedAt.Sub(shellResult.StartedAt).Milliseconds()
+
+	// Wait returned, so the copying goroutines are done writing.
+	if preparedExec.StdoutLineWriter != nil {
+		preparedExec.StdoutLineWriter.flush()
+	}
+	if preparedExec.StderrLineWriter != nil {
+		preparedExec.StderrLineWriter.flush()
 	}
-	if preparedExec.Err == nil {
-		return stdoutStr, nil
+
+	shellResult.Stdout = preparedExec.StdoutTailBuffer.String()
+	shellResult.IsStdoutTruncated = preparedExec.StdoutTailBuffer.truncatedBytes > 0
+	shellResult.Stderr = preparedExec.StderrTailBuffer.String()
+	shellResult.IsStderrTruncated = preparedExec.StderrTailBuffer.truncatedBytes > 0
+
+	processState := preparedExec.ExecCmd.ProcessState
+	if processState != nil {
+		shellResult.ExitCode = processState.ExitCode()
+		waitStatus, assertOk := processState.Sys().(syscall.WaitStatus)
+		if assertOk && waitStatus.Signaled() {
+			signalName := unix.SignalName(waitStatus.Signal())
+			shellResult.Signal = &signalName
+		}
 	}
 
-	if exitErr, assertOk := preparedExec.Err.(*exec.ExitError); assertOk {
-		stdErrStr := preparedExec.StderrBytesBuffer.String()
-		if exitErr.ExitCode() == 124 {
-			stdErrStr = "CommandDeadlineExceeded"
+	if wasTerminated {
+		terminationReason := "CommandDeadlineExceeded"
+		if errors.Is(runCtx.Err(), context.Canceled) {
+			terminationReason = "CommandCanceled"
 		}
 
-		return stdoutStr, &ShellError{
-			StdErr:   stdErrStr,
-			ExitCode: exitErr.ExitCode(),
+		return shellResult, &ShellTerminatedError{
+			StdErr: shellResult.Stderr,
+			Reason: terminationReason,
+			Signal: terminationSignal,
+			cause:  runCtx.Err(),
+		}
+	}
+
+	if waitErr == nil {
+		return shellResult, nil
+	}
+
+	if _, assertOk := waitErr.(*exec.ExitError); assertOk {
+		return shellResult, &ShellError{
+			StdErr:   shellResult.Stderr,
+			ExitCode: shellResult.ExitCode,
 		}
 	}
 
-	return stdoutStr, preparedExec.Err
+	return shellResult, waitErr
 }
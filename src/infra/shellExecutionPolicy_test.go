@@ -0,0 +1,260 @@
+package tkInfra
+
+import (
+	"context"
+	"encoding/json"
+	"slices"
+	"strings"
+	"testing"
+	"unicode/utf8"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestShellExecutionPolicy(t *testing.T) {
+	allowedCommands := []tkValueObject.UnixCommand{"echo", "printf"}
+
+	t.Run("Validation", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name              string
+			command           string
+			args              []string
+			shouldUseSubShell bool
+			expectedOutput    string
+			expectedErr       string
+		}{
+			{"AllowedCommand", "echo", []string{"hello", "world"}, false, "hello world", ""},
+			{"CommandNotAllowed", "cat", []string{"/etc/passwd"}, false, "", errShellCommandNotAllowed},
+			{"QuotesAndSpacesAllowed", "echo", []string{"it's \"fine\""}, false, "it's \"fine\"", ""},
+			{"SemicolonArg", "echo", []string{"a; rm -rf /"}, false, "", errShellArgContainsMetachars + ": 0"},
+			{"SubstitutionArg", "echo", []string{"ok", "$(id)"}, false, "", errShellArgContainsMetachars + ": 1"},
+			{"BacktickArg", "echo", []string{"`id`"}, false, "", errShellArgContainsMetachars},
+			{"NewlineArg", "echo", []string{"a\nb"}, false, "", errShellArgContainsMetachars},
+			{"SubShellArgsQuoted", "echo", []string{"a; echo injected", "$(id)"}, true, "a; echo injected $(id)", ""},
+			{"SubShellCommandNotAllowed", "id", nil, true, "", errShellCommandNotAllowed},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				stdout, err := NewShell(ShellSettings{
+					Command:           testCase.command,
+					Args:              testCase.args,
+					ShouldUseSubShell: testCase.shouldUseSubShell,
+					ExecutionPolicy:   &ShellExecutionPolicy{AllowedCommands: allowedCommands},
+				}).Run()
+				if testCase.expectedErr != "" {
+					if err == nil || !strings.HasPrefix(err.Error(), testCase.expectedErr) {
+						t.Errorf("MissingExpectedError: %s (%v)", testCase.expectedErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if stdout != testCase.expectedOutput {
+					t.Errorf("UnexpectedOutput: '%s' vs '%s'", stdout, testCase.expectedOutput)
+				}
+			})
+		}
+	})
+
+	t.Run("RestrictArgsToWords", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name           string
+			args           []string
+			expectedOutput string
+			expectedErr    string
+		}{
+			{"SingleWords", []string{"hello", "world"}, "hello world", ""},
+			{"Space", []string{"hello world"}, "", errShellArgNotSingleWord + ": 0"},
+			{"Tab", []string{"ok", "a\tb"}, "", errShellArgNotSingleWord + ": 1"},
+			{"SingleQuote", []string{"it's"}, "", errShellArgNotSingleWord},
+			{"DoubleQuote", []string{`"quoted"`}, "", errShellArgNotSingleWord},
+			{"LeadingDash", []string{"-oProxyCommand=id"}, "", errShellArgLooksLikeOption + ": 0"},
+			{"OptionsBeforeSeparator", []string{"-n", "--", "value"}, "-- value", ""},
+			{"LeadingDashAfterSeparator", []string{"-n", "--", "-oProxyCommand=id"}, "", errShellArgLooksLikeOption + ": 2"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				stdout, err := NewShell(ShellSettings{
+					Command: "echo",
+					Args:    testCase.args,
+					ExecutionPolicy: &ShellExecutionPolicy{
+						AllowedCommands: allowedCommands, ShouldRestrictArgsToWords: true,
+					},
+				}).Run()
+				if testCase.expectedErr != "" {
+					if err == nil || !strings.HasPrefix(err.Error(), testCase.expectedErr) {
+						t.Errorf("MissingExpectedError: %s (%v)", testCase.expectedErr, err)
+					}
+					return
+				}
+				if err != nil {
+					t.Fatalf("UnexpectedError: '%s'", err.Error())
+				}
+				if stdout != testCase.expectedOutput {
+					t.Errorf("UnexpectedOutput: '%s' vs '%s'", stdout, testCase.expectedOutput)
+				}
+			})
+		}
+	})
+
+	t.Run("NoAllowedCommands", func(t *testing.T) {
+		stdout, err := NewShell(ShellSettings{
+			Command: "printf", Args: []string{"%s", "any"},
+			ExecutionPolicy: &ShellExecutionPolicy{},
+		}).Run()
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if stdout != "any" {
+			t.Errorf("UnexpectedOutput: '%s'", stdout)
+		}
+	})
+
+	t.Run("CommandValidatedInEveryMode", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name              string
+			command           string
+			shouldUseSubShell bool
+		}{
+			{"SubShellChainedCommand", "echo ok; id", true},
+			{"SubShellSubstitutionCommand", "$(id)", true},
+			{"SubShellCommandWithArgs", "echo ok", true},
+			{"SubShellQuotedCommand", "'echo'", true},
+			{"ChainedCommand", "echo;id", false},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				_, err := NewShell(ShellSettings{
+					Command:           testCase.command,
+					ShouldUseSubShell: testCase.shouldUseSubShell,
+					ExecutionPolicy:   &ShellExecutionPolicy{},
+				}).Run()
+				if err == nil || !strings.HasPrefix(err.Error(), errShellCommandInvalid) {
+					t.Errorf("MissingExpectedError: %s (%v)", errShellCommandInvalid, err)
+				}
+			})
+		}
+	})
+
+	t.Run("AuditHook", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name             string
+			command          string
+			args             []string
+			expectedExitCode int
+			shouldHaveErr    bool
+		}{
+			{"Succeeded", "true", nil, 0, false},
+			{"Failed", "false", nil, 1, true},
+			{"Rejected", "echo", []string{"a|b"}, -1, true},
+			{"NotStarted", "nonexistentcommand", nil, -1, true},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				auditEntries := []ShellAuditEntry{}
+				_, _ = NewShell(ShellSettings{
+					Command:          testCase.command,
+					Args:             testCase.args,
+					WorkingDirectory: "/tmp",
+					ExecutionPolicy: &ShellExecutionPolicy{
+						AuditHook: func(auditEntry ShellAuditEntry) {
+							auditEntries = append(auditEntries, auditEntry)
+						},
+					},
+				}).RunWithContext(context.Background())
+
+				if len(auditEntries) != 1 {
+					t.Fatalf("UnexpectedAuditEntriesCount: %d", len(auditEntries))
+				}
+				auditEntry := auditEntries[0]
+				if auditEntry.Command != testCase.command || auditEntry.WorkingDirectory != "/tmp" {
+					t.Errorf("UnexpectedAuditEntry: %+v", auditEntry)
+				}
+				if auditEntry.ExitCode != testCase.expectedExitCode {
+					t.Errorf("UnexpectedExitCode: %d", auditEntry.ExitCode)
+				}
+				if (auditEntry.Err != nil) != testCase.shouldHaveErr {
+					t.Errorf("UnexpectedAuditErr: %v", auditEntry.Err)
+				}
+
+				_, err := json.Marshal(auditEntry)
+				if err != nil {
+					t.Errorf("UnexpectedError: '%s'", err.Error())
+				}
+			})
+		}
+	})
+
+	t.Run("AuditArgsMasking", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name               string
+			args               []string
+			sensitivePatterns  []string
+			expectedMaskedArgs []string
+		}{
+			{"NoArgs", nil, nil, nil},
+			{"NotSensitive", []string{"-n", "--user=admin", "host"}, nil, []string{"-n", "--user=admin", "host"}},
+			{"OptionValue", []string{"--password=hunter2", "-api-key=abc"}, nil, []string{"--password=[REDACTED]", "-api-key=[REDACTED]"}},
+			{"NextArgValue", []string{"--token", "abc", "next"}, nil, []string{"--token", "[REDACTED]", "next"}},
+			{"HeaderValue", []string{"-H", "Authorization: Bearer abc"}, nil, []string{"-H", "Authorization: [REDACTED]"}},
+			{"EnvValue", []string{"DB_PASSWORD=hunter2"}, nil, []string{"DB_PASSWORD=[REDACTED]"}},
+			{"UrlPassword", []string{"mysql://root:hunter2@db/trail"}, nil, []string{"mysql://root:[REDACTED]@db/trail"}},
+			{"CustomPatterns", []string{"--pin=1234", "--password=x"}, []string{"PIN"}, []string{"--pin=[REDACTED]", "--password=x"}},
+			{"PatternsDisabled", []string{"--password=x"}, []string{}, []string{"--password=x"}},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				auditEntries := []ShellAuditEntry{}
+				_, _ = NewShell(ShellSettings{
+					Command: "true",
+					Args:    testCase.args,
+					ExecutionPolicy: &ShellExecutionPolicy{
+						AuditSensitiveArgPatterns: testCase.sensitivePatterns,
+						AuditHook: func(auditEntry ShellAuditEntry) {
+							auditEntries = append(auditEntries, auditEntry)
+						},
+					},
+				}).Run()
+
+				if len(auditEntries) != 1 {
+					t.Fatalf("UnexpectedAuditEntriesCount: %d", len(auditEntries))
+				}
+				if !slices.Equal(auditEntries[0].Args, testCase.expectedMaskedArgs) {
+					t.Errorf("UnexpectedMaskedArgs: %q", auditEntries[0].Args)
+				}
+			})
+		}
+	})
+
+	t.Run("AuditErrTruncated", func(t *testing.T) {
+		auditEntries := []ShellAuditEntry{}
+		_, err := NewShell(ShellSettings{
+			Command: "ls",
+			Args:    []string{"/missing-" + strings.Repeat("é", 100)},
+			ExecutionPolicy: &ShellExecutionPolicy{
+				AuditErrMaxBytes: 64,
+				AuditHook: func(auditEntry ShellAuditEntry) {
+					auditEntries = append(auditEntries, auditEntry)
+				},
+			},
+		}).Run()
+		if err == nil || len(err.Error()) <= 64 {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+
+		if len(auditEntries) != 1 || auditEntries[0].Err == nil {
+			t.Fatalf("MissingAuditErr: %+v", auditEntries)
+		}
+		auditErr := *auditEntries[0].Err
+		if len(auditErr) > 64 || !strings.HasPrefix(auditErr, shellAuditErrTruncatedPrefix) ||
+			!strings.Contains(auditErr, "exitCode") || !utf8.ValidString(auditErr) {
+			t.Errorf("UnexpectedAuditErr: '%s'", auditErr)
+		}
+	})
+}
@@ -0,0 +1,101 @@
+package tkInfra
+
+import (
+	"bytes"
+	"strings"
+)
+
+const shellLineMaxBytes int = 64 * 1024
+
+// shellTailBuffer keeps the last maxBytes written to it (everything when maxBytes is
+// zero), so a chatty command can't exhaust the memory.
+type shellTailBuffer struct {
+	maxBytes       int
+	buffer         []byte
+	truncatedBytes uint64
+}
+
+func newShellTailBuffer(maxBytes uint64) *shellTailBuffer {
+	return &shellTailBuffer{maxBytes: int(maxBytes)}
+}
+
+func (tailBuffer *shellTailBuffer) Write(chunk []byte) (int, error) {
+	if tailBuffer.maxBytes == 0 {
+		tailBuffer.buffer = append(tailBuffer.buffer, chunk...)
+		return len(chunk), nil
+	}
+
+	if len(chunk) >= tailBuffer.maxBytes {
+		tailBuffer.truncatedBytes += uint64(len(tailBuffer.buffer) + len(chunk) - tailBuffer.maxBytes)
+		tailBuffer.buffer = append(
+			tailBuffer.buffer[:0], chunk[len(chunk)-tailBuffer.maxBytes:]...,
+		)
+		return len(chunk), nil
+	}
+
+	tailBuffer.buffer = append(tailBuffer.buffer, chunk...)
+	overflowBytes := len(tailBuffer.buffer) - tailBuffer.maxBytes
+	if overflowBytes > 0 {
+		tailBuffer.truncatedBytes += uint64(overflowBytes)
+		tailBuffer.buffer = append(tailBuffer.buffer[:0], tailBuffer.buffer[overflowBytes:]...)
+	}
+
+	return len(chunk), nil
+}
+
+// String drops the partial UTF-8 sequence the truncation may have left at the start.
+func (tailBuffer *shellTailBuffer) String() string {
+	if tailBuffer.truncatedBytes == 0 {
+		return string(tailBuffer.buffer)
+	}
+	return strings.ToValidUTF8(string(tailBuffer.buffer), "")
+}
+
+// shellLineWriter calls the callback with every line written to it, without the line
+// break. Lines longer than shellLineMaxBytes are split, and flush emits the last line
+// when the output doesn't end with a line break.
+type shellLineWriter struct {
+	callback    func(line string)
+	partialLine []byte
+}
+
+func newShellLineWriter(callback func(line string)) *shellLineWriter {
+	return &shellLineWriter{callback: callback}
+}
+
+func (lineWriter *shellLineWriter) emit(line []byte) {
+	lineWriter.callback(string(bytes.TrimSuffix(line, []byte("\r"))))
+}
+
+func (lineWriter *shellLineWriter) Write(chunk []byte) (int, error) {
+	writtenBytes := len(chunk)
+	for {
+		lineBreakIndex := bytes.IndexByte(chunk, '\n')
+		if lineBreakIndex < 0 {
+			break
+		}
+
+		lineWriter.partialLine = append(lineWriter.partialLine, chunk[:lineBreakIndex]...)
+		lineWriter.emit(lineWriter.partialLine)
+		lineWriter.partialLine = lineWriter.partialLine[:0]
+		chunk = chunk[lineBreakIndex+1:]
+	}
+
+	lineWriter.partialLine = append(lineWriter.partialLine, chunk...)
+	for len(lineWriter.partialLine) >= shellLineMaxBytes {
+		lineWriter.emit(lineWriter.partialLine[:shellLineMaxBytes])
+		lineWriter.partialLine = append(
+			lineWriter.partialLine[:0], lineWriter.partialLine[shellLineMaxBytes:]...,
+		)
+	}
+
+	return writtenBytes, nil
+}
+
+func (lineWriter *shellLineWriter) flush() {
+	if len(lineWriter.partialLine) == 0 {
+		return
+	}
+	lineWriter.emit(lineWriter.partialLine)
+	lineWriter.partialLine = lineWriter.partialLine[:0]
+}
@@ -0,0 +1,75 @@
+package tkInfra
+
+import (
+	"slices"
+	"strings"
+	"testing"
+)
+
+func TestShellOutputWriter(t *testing.T) {
+	t.Run("TailBuffer", func(t *testing.T) {
+		testCaseStructs := []struct {
+			name           string
+			maxBytes       uint64
+			chunks         []string
+			expectedOutput string
+		}{
+			{"Unbounded", 0, []string{"abc", "def"}, "abcdef"},
+			{"WithinCap", 8, []string{"abc", "def"}, "abcdef"},
+			{"KeepsTail", 4, []string{"abc", "def"}, "cdef"},
+			{"ChunkLargerThanCap", 4, []string{"ab", "cdefgh"}, "efgh"},
+			{"DropsPartialRune", 3, []string{"aaé", "bc"}, "bc"},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				tailBuffer := newShellTailBuffer(testCase.maxBytes)
+				for _, chunk := range testCase.chunks {
+					writtenBytes, err := tailBuffer.Write([]byte(chunk))
+					if err != nil || writtenBytes != len(chunk) {
+						t.Fatalf("WriteFailed: %d %v", writtenBytes, err)
+					}
+				}
+				if tailBuffer.String() != testCase.expectedOutput {
+					t.Errorf("UnexpectedOutput: '%s' vs '%s'", tailBuffer.String(), testCase.expectedOutput)
+				}
+			})
+		}
+	})
+
+	t.Run("LineWriter", func(t *testing.T) {
+		longLine := strings.Repeat("x", shellLineMaxBytes+10)
+		testCaseStructs := []struct {
+			name          string
+			chunks        []string
+			expectedLines []string
+		}{
+			{"SingleChunk", []string{"a\nb\n"}, []string{"a", "b"}},
+			{"SplitAcrossChunks", []string{"ab", "c\nd", "e\n"}, []string{"abc", "de"}},
+			{"CarriageReturn", []string{"a\r\n"}, []string{"a"}},
+			{"EmptyLine", []string{"\n"}, []string{""}},
+			{"UnterminatedLastLine", []string{"a\nb"}, []string{"a", "b"}},
+			{
+				"LongLine", []string{longLine},
+				[]string{longLine[:shellLineMaxBytes], longLine[shellLineMaxBytes:]},
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				emittedLines := []string{}
+				lineWriter := newShellLineWriter(func(line string) {
+					emittedLines = append(emittedLines, line)
+				})
+				for _, chunk := range testCase.chunks {
+					_, _ = lineWriter.Write([]byte(chunk))
+				}
+				lineWriter.flush()
+
+				if !slices.Equal(emittedLines, testCase.expectedLines) {
+					t.Errorf("UnexpectedLines: %q vs %q", emittedLines, testCase.expectedLines)
+				}
+			})
+		}
+	})
+}
@@ -1,7 +1,20 @@
 package tkInfra
 
 import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"os"
+	"path/filepath"
+	"slices"
+	"strconv"
+	"strings"
+	"sync"
+	"syscall"
 	"testing"
+	"time"
 )
 
 func TestShell(t *testing.T) {
@@ -68,4 +81,351 @@ func TestShell(t *testing.T) {
 			}
 		}
 	})
+
+	t.Run("RunWithContext", func(t *testing.T) {
+		deadlineCtxFactory := func() (context.Context, context.CancelFunc) {
+			return context.WithTimeout(context.Background(), 300*time.Millisecond)
+		}
+		canceledCtxFactory := func() (context.Context, context.CancelFunc) {
+			canceledCtx, cancelCtx := context.WithCancel(context.Background())
+			time.AfterFunc(300*time.Millisecond, cancelCtx)
+			return canceledCtx, cancelCtx
+		}
+		backgroundCtxFactory := func() (context.Context, context.CancelFunc) {
+			return context.WithCancel(context.Background())
+		}
+
+		testCaseStructs := []struct {
+			name           string
+			ctxFactory     func() (context.Context, context.CancelFunc)
+			settings       ShellSettings
+			expectedCause  error
+			expectedReason string
+			expectedSignal string
+		}{
+			{
+				"DeadlineExceeded", deadlineCtxFactory,
+				ShellSettings{Command: "sleep", Args: []string{"5"}},
+				context.DeadlineExceeded, "CommandDeadlineExceeded", "SIGTERM",
+			},
+			{
+				"Canceled", canceledCtxFactory,
+				ShellSettings{Command: "sleep", Args: []string{"5"}},
+				context.Canceled, "CommandCanceled", "SIGTERM",
+			},
+			{
+				"ExecutionTimeoutSecs", backgroundCtxFactory,
+				ShellSettings{Command: "sleep", Args: []string{"5"}, ExecutionTimeoutSecs: 1},
+				context.DeadlineExceeded, "CommandDeadlineExceeded", "SIGTERM",
+			},
+			{
+				"SigtermIgnored", deadlineCtxFactory,
+				ShellSettings{
+					Command:                "bash",
+					Args:                   []string{"-c", "trap '' TERM; sleep 5"},
+					TerminationGracePeriod: 100 * time.Millisecond,
+				},
+				context.DeadlineExceeded, "CommandDeadlineExceeded", "SIGKILL",
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			t.Run(testCase.name, func(t *testing.T) {
+				runCtx, cancelRunCtx := testCase.ctxFactory()
+				defer cancelRunCtx()
+
+				startedAt := time.Now()
+				_, err := NewShell(testCase.settings).RunWithContext(runCtx)
+				if time.Since(startedAt) > 3*time.Second {
+					t.Errorf("CommandNotTerminated: %s", time.Since(startedAt))
+				}
+
+				terminatedErr := &ShellTerminatedError{}
+				if !errors.As(err, &terminatedErr) {
+					t.Fatalf("MissingExpectedError: ShellTerminatedError (got %v)", err)
+				}
+				if !errors.Is(err, testCase.expectedCause) {
+					t.Errorf("UnexpectedCause: %v vs %v", terminatedErr.cause, testCase.expectedCause)
+				}
+				if terminatedErr.Reason != testCase.expectedReason {
+					t.Errorf("UnexpectedReason: '%s' vs '%s'", terminatedErr.Reason, testCase.expectedReason)
+				}
+				if terminatedErr.Signal != testCase.expectedSignal {
+					t.Errorf("UnexpectedSignal: '%s' vs '%s'", terminatedErr.Signal, testCase.expectedSignal)
+				}
+			})
+		}
+	})
+
+	t.Run("LegitimateExitCode124", func(t *testing.T) {
+		_, err := NewShell(
+			ShellSettings{Command: "bash", Args: []string{"-c", "exit 124"}},
+		).RunWithContext(context.Background())
+
+		shellErr := &ShellError{}
+		if !errors.As(err, &shellErr) || shellErr.ExitCode != 124 {
+			t.Errorf("UnexpectedError: %v", err)
+		}
+	})
+
+	t.Run("GrandchildrenTerminated", func(t *testing.T) {
+		runCtx, cancelRunCtx := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancelRunCtx()
+
+		grandchildPid, err := NewShell(ShellSettings{
+			Command: "bash", Args: []string{"-c", "sleep 30 & echo $!; wait"},
+		}).RunWithContext(runCtx)
+		if !errors.Is(err, context.DeadlineExceeded) {
+			t.Fatalf("MissingExpectedError: DeadlineExceeded (got %v)", err)
+		}
+
+		time.Sleep(100 * time.Millisecond)
+		processStat, err := os.ReadFile("/proc/" + grandchildPid + "/stat")
+		if err != nil {
+			return
+		}
+		// Zombies are dead already, just not reaped by the subreaper.
+		if !strings.Contains(string(processStat), ") Z ") {
+			t.Errorf("GrandchildSurvived: %s", grandchildPid)
+		}
+	})
+
+	t.Run("DetachedGrandchildHoldingPipes", func(t *testing.T) {
+		runCtx, cancelRunCtx := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancelRunCtx()
+
+		startedAt := time.Now()
+		shellResult, err := NewShell(ShellSettings{
+			Command:                "bash",
+			Args:                   []string{"-c", "trap '' TERM; setsid sleep 30 & echo $!; sleep 30"},
+			TerminationGracePeriod: 100 * time.Millisecond,
+		}).RunDetailed(runCtx)
+		if time.Since(startedAt) > 3*time.Second {
+			t.Errorf("RunNotEnded: %s", time.Since(startedAt))
+		}
+		grandchildPid, _ := strconv.Atoi(strings.TrimSpace(shellResult.Stdout))
+		if grandchildPid > 0 {
+			_ = syscall.Kill(grandchildPid, syscall.SIGKILL)
+		}
+
+		terminatedErr := &ShellTerminatedError{}
+		if !errors.As(err, &terminatedErr) {
+			t.Fatalf("MissingExpectedError: ShellTerminatedError (got %v)", err)
+		}
+		if terminatedErr.Signal != "SIGKILL" {
+			t.Errorf("UnexpectedSignal: '%s'", terminatedErr.Signal)
+		}
+	})
+
+	t.Run("DeadlineAfterCommandExited", func(t *testing.T) {
+		runCtx, cancelRunCtx := context.WithTimeout(context.Background(), 300*time.Millisecond)
+		defer cancelRunCtx()
+
+		// The background sleep keeps the pipes open, so the deadline lands during Wait.
+		startedAt := time.Now()
+		shellResult, err := NewShell(ShellSettings{
+			Command:                "bash",
+			Args:                   []string{"-c", "sleep 30 & exit 0"},
+			TerminationGracePeriod: 5 * time.Second,
+		}).RunDetailed(runCtx)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if shellResult.ExitCode != 0 || shellResult.Signal != nil {
+			t.Errorf("UnexpectedExit: %d %v", shellResult.ExitCode, shellResult.Signal)
+		}
+		// The background children are still terminated with the process group.
+		if time.Since(startedAt) > 3*time.Second {
+			t.Errorf("BackgroundChildSurvived: %s", time.Since(startedAt))
+		}
+	})
+
+	t.Run("Streaming", func(t *testing.T) {
+		t.Run("StdinTeesAndLineCallbacks", func(t *testing.T) {
+			var stdoutTee, stderrTee bytes.Buffer
+			linesMutex := sync.Mutex{}
+			stdoutLines, stderrLines := []string{}, []string{}
+			shellOutput, err := NewShell(ShellSettings{
+				Command:      "bash",
+				Args:         []string{"-c", "while read line; do echo \"out:$line\"; echo \"err:$line\" >&2; done"},
+				Stdin:        strings.NewReader("a\nb\n"),
+				StdoutWriter: &stdoutTee,
+				StderrWriter: &stderrTee,
+				StdoutLineCallback: func(line string) {
+					linesMutex.Lock()
+					defer linesMutex.Unlock()
+					stdoutLines = append(stdoutLines, line)
+				},
+				StderrLineCallback: func(line string) {
+					linesMutex.Lock()
+					defer linesMutex.Unlock()
+					stderrLines = append(stderrLines, line)
+				},
+			}).Run()
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+
+			if shellOutput != "out:a\nout:b" || stdoutTee.String() != "out:a\nout:b\n" {
+				t.Errorf("UnexpectedStdout: '%s' '%s'", shellOutput, stdoutTee.String())
+			}
+			if stderrTee.String() != "err:a\nerr:b\n" {
+				t.Errorf("UnexpectedStderrTee: '%s'", stderrTee.String())
+			}
+			if !slices.Equal(stdoutLines, []string{"out:a", "out:b"}) ||
+				!slices.Equal(stderrLines, []string{"err:a", "err:b"}) {
+				t.Errorf("UnexpectedLines: %q %q", stdoutLines, stderrLines)
+			}
+		})
+
+		t.Run("StdinNeverReachingEof", func(t *testing.T) {
+			stdinReader, stdinWriter := io.Pipe()
+			defer stdinWriter.Close()
+
+			startedAt := time.Now()
+			shellResult, err := NewShell(ShellSettings{
+				Command:                "true",
+				Stdin:                  stdinReader,
+				ExecutionTimeoutSecs:   1,
+				TerminationGracePeriod: 100 * time.Millisecond,
+			}).RunDetailed(context.Background())
+			if time.Since(startedAt) > 3*time.Second {
+				t.Errorf("RunNotEnded: %s", time.Since(startedAt))
+			}
+
+			if err != nil {
+				t.Errorf("UnexpectedError: '%s'", err.Error())
+			}
+			if shellResult.ExitCode != 0 {
+				t.Errorf("UnexpectedExitCode: %d", shellResult.ExitCode)
+			}
+		})
+
+		t.Run("MaxBufferedBytes", func(t *testing.T) {
+			shellOutput, err := NewShell(ShellSettings{
+				Command:          "bash",
+				Args:             []string{"-c", "seq 1 10000; seq 1 10000 >&2; exit 3"},
+				MaxBufferedBytes: 16,
+			}).Run()
+
+			if !strings.HasSuffix(shellOutput, "9999\n10000") || len(shellOutput) > 16 {
+				t.Errorf("UnexpectedStdoutTail: '%s'", shellOutput)
+			}
+			shellErr := &ShellError{}
+			if !errors.As(err, &shellErr) || shellErr.ExitCode != 3 {
+				t.Fatalf("UnexpectedError: %v", err)
+			}
+			if shellErr.StdErr != "9998\n9999\n10000\n" {
+				t.Errorf("UnexpectedStderrTail: '%s'", shellErr.StdErr)
+			}
+		})
+
+		t.Run("StderrTailWithFilePath", func(t *testing.T) {
+			stderrFilePath := filepath.Join(t.TempDir(), "stderr.log")
+			_, err := NewShell(ShellSettings{
+				Command:        "bash",
+				Args:           []string{"-c", "echo failed >&2; exit 1"},
+				StderrFilePath: stderrFilePath,
+			}).Run()
+
+			shellErr := &ShellError{}
+			if !errors.As(err, &shellErr) || shellErr.StdErr != "failed\n" {
+				t.Errorf("UnexpectedError: %v", err)
+			}
+			stderrFileContent, _ := os.ReadFile(stderrFilePath)
+			if string(stderrFileContent) != "failed\n" {
+				t.Errorf("UnexpectedStderrFileContent: '%s'", stderrFileContent)
+			}
+		})
+	})
+
+	t.Run("RunDetailed", func(t *testing.T) {
+		t.Run("StderrOnSuccess", func(t *testing.T) {
+			shellResult, err := NewShell(ShellSettings{
+				Command: "bash", Args: []string{"-c", "echo out; echo warn >&2"},
+			}).RunDetailed(context.Background())
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+
+			if shellResult.Stdout != "out\n" || shellResult.Stderr != "warn\n" {
+				t.Errorf("UnexpectedOutput: '%s' '%s'", shellResult.Stdout, shellResult.Stderr)
+			}
+			if shellResult.ExitCode != 0 || shellResult.Signal != nil {
+				t.Errorf("UnexpectedExitMetadata: %d %v", shellResult.ExitCode, shellResult.Signal)
+			}
+			if !strings.HasSuffix(shellResult.CommandLine, "bash -c 'echo out; echo warn >&2'") ||
+				!strings.HasPrefix(shellResult.CommandLine, "/") {
+				t.Errorf("UnexpectedCommandLine: %s", shellResult.CommandLine)
+			}
+			durationMs := shellResult.FinishedAt.Sub(shellResult.StartedAt).Milliseconds()
+			if !shellResult.FinishedAt.After(shellResult.StartedAt) ||
+				shellResult.DurationMs != durationMs {
+				t.Errorf("UnexpectedTiming: %d", shellResult.DurationMs)
+			}
+			if shellResult.IsStdoutTruncated || shellResult.IsStderrTruncated {
+				t.Error("UnexpectedTruncation")
+			}
+
+			jsonResult, err := json.Marshal(shellResult)
+			if err != nil || !strings.Contains(string(jsonResult), `"stderr":"warn\n"`) ||
+				!strings.Contains(string(jsonResult), `"durationMs":`) {
+				t.Errorf("UnexpectedJsonResult: %s %v", jsonResult, err)
+			}
+		})
+
+		t.Run("KilledBySignal", func(t *testing.T) {
+			shellResult, err := NewShell(ShellSettings{
+				Command: "bash", Args: []string{"-c", "echo partial; kill -KILL $$"},
+			}).RunDetailed(context.Background())
+
+			shellErr := &ShellError{}
+			if !errors.As(err, &shellErr) || shellErr.ExitCode != -1 {
+				t.Errorf("UnexpectedError: %v", err)
+			}
+			if shellResult.Signal == nil || *shellResult.Signal != "SIGKILL" {
+				t.Errorf("UnexpectedSignal: %v", shellResult.Signal)
+			}
+			if shellResult.Stdout != "partial\n" {
+				t.Errorf("UnexpectedStdout: '%s'", shellResult.Stdout)
+			}
+		})
+
+		t.Run("Truncated", func(t *testing.T) {
+			shellResult, err := NewShell(ShellSettings{
+				Command: "seq", Args: []string{"1", "100"}, MaxBufferedBytes: 4,
+			}).RunDetailed(context.Background())
+			if err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+			if !shellResult.IsStdoutTruncated || shellResult.Stdout != "100\n" {
+				t.Errorf("UnexpectedTruncatedStdout: '%s'", shellResult.Stdout)
+			}
+		})
+
+		t.Run("NotStarted", func(t *testing.T) {
+			shellResult, err := NewShell(
+				ShellSettings{Command: "nonexistentcommand"},
+			).RunDetailed(context.Background())
+			if err == nil {
+				t.Fatal("MissingExpectedError: CommandNotFound")
+			}
+			if shellResult.ExitCode != -1 || shellResult.CommandLine != "nonexistentcommand" {
+				t.Errorf("UnexpectedResult: %d '%s'", shellResult.ExitCode, shellResult.CommandLine)
+			}
+		})
+	})
+
+	t.Run("CommandRunner", func(t *testing.T) {
+		var commandRunner CommandRunner = NewShell(ShellSettings{Command: "false"})
+		shellResult, err := commandRunner.RunCommand(
+			context.Background(), ShellSettings{Command: "echo", Args: []string{"given"}},
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		if shellResult.Stdout != "given\n" {
+			t.Errorf("UnexpectedStdout: '%s'", shellResult.Stdout)
+		}
+	})
 }
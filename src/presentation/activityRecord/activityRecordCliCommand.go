@@ -0,0 +1,90 @@
+package tkPresentationActivityRecord
+
+import (
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+	"github.com/iancoleman/strcase"
+	"github.com/spf13/cobra"
+	"github.com/spf13/pflag"
+)
+
+var activityRecordCliFilterFlagsUsage = map[string]string{
+	"record-id":                  "Record ID",
+	"record-level":               "Record level (DEBUG, INFO, WARNING, ERROR, SECURITY)",
+	"record-code":                "Record code",
+	"affected-resources":         "Affected resources SRIs, separated by ';' or ','",
+	"record-operator-sri":        "Record operator SRI",
+	"record-operator-ip-address": "Record operator IP address",
+	"record-correlation-id":      "Record correlation ID (the request X-Request-Id)",
+	"created-before-at":          "Created before (Unix time, RFC 3339, date or relative, e.g. \"24h ago\")",
+	"created-after-at":           "Created after (Unix time, RFC 3339, date or relative, e.g. \"24h ago\")",
+	"account-id":                 "Scope the records to this account ID",
+}
+
+var activityRecordCliReadFlagsUsage = map[string]string{
+	"record-details-filters": `RecordDetails filters as JSON, e.g. [{"path":"user.role","value":"admin"}]`,
+	"page-number":            "Page number",
+	"items-per-page":         "Items per page",
+	"sort-by":                "Sort by field",
+	"sort-direction":         "Sort direction (asc, desc)",
+	"last-seen-id":           "Last seen ID",
+	"cursor":                 "Pagination cursor (nextCursor of the previous page)",
+}
+
+// activityRecordCliInputBuilder maps the flags explicitly set to the liaison input keys
+// ("record-operator-sri" to "recordOperatorSri"). CLI operators have direct access to
+// the trail database, so they act as the system account, from the local IP address.
+func activityRecordCliInputBuilder(cliFlags *pflag.FlagSet) map[string]any {
+	untrustedInput := map[string]any{
+		"operatorAccountId": tkValueObject.AccountIdSystem,
+		"operatorSri":       tkValueObject.NewSriAccount(tkValueObject.AccountIdSystem),
+		"operatorIpAddress": tkValueObject.IpAddressLocal,
+	}
+
+	cliFlags.Visit(func(cliFlag *pflag.Flag) {
+		untrustedInput[strcase.ToLowerCamel(cliFlag.Name)] = cliFlag.Value.String()
+	})
+
+	return untrustedInput
+}
+
+// ActivityRecordCliCommandBuilder returns the "activity-record" command with its "get"
+// and "delete" subcommands, rendered by tkPresentation.LiaisonCliResponseRenderer.
+func ActivityRecordCliCommandBuilder(liaison *ActivityRecordLiaison) *cobra.Command {
+	rootCmd := &cobra.Command{
+		Use:   "activity-record",
+		Short: "ActivityRecordManagement",
+	}
+
+	getCmd := &cobra.Command{
+		Use:   "get",
+		Short: "ReadActivityRecords",
+		Run: func(cmd *cobra.Command, args []string) {
+			tkPresentation.LiaisonCliResponseRenderer(
+				liaison.Read(activityRecordCliInputBuilder(cmd.Flags())),
+			)
+		},
+	}
+	for flagName, flagUsage := range activityRecordCliFilterFlagsUsage {
+		getCmd.Flags().String(flagName, "", flagUsage)
+	}
+	for flagName, flagUsage := range activityRecordCliReadFlagsUsage {
+		getCmd.Flags().String(flagName, "", flagUsage)
+	}
+
+	deleteCmd := &cobra.Command{
+		Use:   "delete",
+		Short: "DeleteActivityRecords",
+		Run: func(cmd *cobra.Command, args []string) {
+			tkPresentation.LiaisonCliResponseRenderer(
+				liaison.Delete(activityRecordCliInputBuilder(cmd.Flags())),
+			)
+		},
+	}
+	for flagName, flagUsage := range activityRecordCliFilterFlagsUsage {
+		deleteCmd.Flags().String(flagName, "", flagUsage)
+	}
+
+	rootCmd.AddCommand(getCmd, deleteCmd)
+	return rootCmd
+}
@@ -0,0 +1,56 @@
+package tkPresentationActivityRecord
+
+import (
+	"testing"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+)
+
+func TestActivityRecordCliCommandBuilder(t *testing.T) {
+	rootCmd := ActivityRecordCliCommandBuilder(NewActivityRecordLiaison(
+		ActivityRecordLiaisonSettings{},
+	))
+
+	getCmd, _, err := rootCmd.Find([]string{"get"})
+	if err != nil || getCmd.Name() != "get" {
+		t.Fatalf("GetCommandNotFound")
+	}
+
+	err = getCmd.ParseFlags([]string{
+		"--record-operator-sri", "sri://1:mailbox/info",
+		"--items-per-page", "5",
+		"--account-id", "1",
+	})
+	if err != nil {
+		t.Fatalf("UnexpectedError: '%s'", err.Error())
+	}
+
+	untrustedInput := activityRecordCliInputBuilder(getCmd.Flags())
+	expectedInput := map[string]any{
+		"operatorAccountId": tkValueObject.AccountIdSystem,
+		"operatorSri":       tkValueObject.NewSriAccount(tkValueObject.AccountIdSystem),
+		"operatorIpAddress": tkValueObject.IpAddressLocal,
+		"recordOperatorSri": "sri://1:mailbox/info",
+		"itemsPerPage":      "5",
+		"accountId":         "1",
+	}
+	if len(untrustedInput) != len(expectedInput) {
+		t.Errorf("UnexpectedInputKeys: %v", untrustedInput)
+	}
+	for inputKey, expectedValue := range expectedInput {
+		if untrustedInput[inputKey] != expectedValue {
+			t.Errorf(
+				"InputValueMismatch: expected %v, got %v [%s]",
+				expectedValue, untrustedInput[inputKey], inputKey,
+			)
+		}
+	}
+
+	deleteCmd, _, err := rootCmd.Find([]string{"delete"})
+	if err != nil || deleteCmd.Name() != "delete" {
+		t.Fatalf("DeleteCommandNotFound")
+	}
+	if deleteCmd.Flags().Lookup("cursor") != nil {
+		t.Errorf("DeleteCommandHasPaginationFlags")
+	}
+}
@@ -0,0 +1,81 @@
+package tkPresentationActivityRecord
+
+import (
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+	"github.com/labstack/echo/v4"
+)
+
+// ActivityRecordController exposes the ActivityRecordLiaison over HTTP. The operator
+// context ("operatorSri", "operatorAccountId" and "operatorIpAddress") must be set on
+// the Echo context by the authentication middleware. Its routes take no body, so the
+// requests without Content-Type are accepted.
+type ActivityRecordController struct {
+	liaison            *ActivityRecordLiaison
+	requestInputReader tkPresentation.ApiRequestInputReader
+}
+
+func NewActivityRecordController(liaison *ActivityRecordLiaison) *ActivityRecordController {
+	return &ActivityRecordController{
+		liaison: liaison,
+		requestInputReader: tkPresentation.ApiRequestInputReader{
+			ShouldAcceptMissingContentType: true,
+		},
+	}
+}
+
+func (controller *ActivityRecordController) Read(echoContext echo.Context) error {
+	requestInput, err := controller.requestInputReader.Reader(echoContext)
+	if err != nil {
+		return err
+	}
+
+	return tkPresentation.LiaisonApiResponseEmitter(
+		echoContext, controller.liaison.Read(requestInput),
+	)
+}
+
+func (controller *ActivityRecordController) Delete(echoContext echo.Context) error {
+	requestInput, err := controller.requestInputReader.Reader(echoContext)
+	if err != nil {
+		return err
+	}
+
+	return tkPresentation.LiaisonApiResponseEmitter(
+		echoContext, controller.liaison.Delete(requestInput),
+	)
+}
+
+// Stream accepts the same filters as Read (except for recordDetailsFilters and the
+// pagination) and keeps the connection open, sending the matching records as they
+// are created. See ActivityRecordStreamEmitter.
+func (controller *ActivityRecordController) Stream(echoContext echo.Context) error {
+	requestInput, err := controller.requestInputReader.Reader(echoContext)
+	if err != nil {
+		return err
+	}
+
+	filterDto, responseStatus, err := controller.liaison.scopedFiltersParser(requestInput)
+	if err != nil {
+		return tkPresentation.LiaisonApiResponseEmitter(
+			echoContext, tkPresentation.NewLiaisonResponseNoMessage(responseStatus, err.Error()),
+		)
+	}
+
+	return ActivityRecordStreamEmitter(
+		echoContext, ActivityRecordStreamEmitterSettings{
+			StreamHub:               controller.liaison.streamHub,
+			ActivityRecordQueryRepo: controller.liaison.activityRecordQueryRepo,
+			HeartbeatInterval:       controller.liaison.streamHeartbeatInterval,
+		}, filterDto,
+	)
+}
+
+// RegisterRoutes adds "GET /", "GET /stream/" (Server-Sent Events, when the liaison
+// has a StreamHub), "DELETE /" (by filters) and "DELETE /:recordId/" to the group,
+// e.g. echoInstance.Group("/v1/activity-record").
+func (controller *ActivityRecordController) RegisterRoutes(routerGroup *echo.Group) {
+	routerGroup.GET("/", controller.Read)
+	routerGroup.GET("/stream/", controller.Stream)
+	routerGroup.DELETE("/", controller.Delete)
+	routerGroup.DELETE("/:recordId/", controller.Delete)
+}
@@ -0,0 +1,83 @@
+package tkPresentationActivityRecord
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/labstack/echo/v4"
+)
+
+func TestActivityRecordController(t *testing.T) {
+	liaison := setupTestActivityRecordLiaison(t, []string{
+		"sri://0:account/1", "sri://0:account/2", "sri://2:mailbox/info",
+	})
+
+	echoInstance := echo.New()
+	routerGroup := echoInstance.Group(
+		"/v1/activity-record",
+		func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(echoContext echo.Context) error {
+				echoContext.Set("operatorAccountId", tkValueObject.AccountId(2))
+				echoContext.Set(
+					"operatorIpAddress", tkValueObject.IpAddress("127.0.0.1"),
+				)
+				return next(echoContext)
+			}
+		},
+	)
+	NewActivityRecordController(liaison).RegisterRoutes(routerGroup)
+
+	testCaseStructs := []struct {
+		httpMethod         string
+		requestPath        string
+		expectedHttpStatus int
+		expectedItemsCount int
+	}{
+		{http.MethodGet, "/v1/activity-record/", http.StatusOK, 2},
+		{http.MethodGet, "/v1/activity-record/?recordLevel=LOUD", http.StatusBadRequest, -1},
+		{http.MethodGet, "/v1/activity-record/stream/", http.StatusNotFound, -1},
+		{http.MethodDelete, "/v1/activity-record/", http.StatusBadRequest, -1},
+		{http.MethodDelete, "/v1/activity-record/1/", http.StatusOK, -1},
+		{http.MethodDelete, "/v1/activity-record/3/", http.StatusOK, -1},
+		{http.MethodGet, "/v1/activity-record/?itemsPerPage=5", http.StatusOK, 1},
+	}
+
+	for _, testCase := range testCaseStructs {
+		httpRequest := httptest.NewRequest(testCase.httpMethod, testCase.requestPath, nil)
+		httpRecorder := httptest.NewRecorder()
+		echoInstance.ServeHTTP(httpRecorder, httpRequest)
+
+		if httpRecorder.Code != testCase.expectedHttpStatus {
+			t.Errorf(
+				"HttpStatusMismatch: expected %d, got %d (%s) [%s %s]",
+				testCase.expectedHttpStatus, httpRecorder.Code, httpRecorder.Body.String(),
+				testCase.httpMethod, testCase.requestPath,
+			)
+			continue
+		}
+		if testCase.expectedItemsCount < 0 {
+			continue
+		}
+
+		responseBody := struct {
+			Body struct {
+				ActivityRecords []any `json:"activityRecords"`
+			} `json:"body"`
+		}{}
+		err := json.Unmarshal(httpRecorder.Body.Bytes(), &responseBody)
+		if err != nil {
+			t.Errorf("UnexpectedResponseBody: '%s'", err.Error())
+			continue
+		}
+		if len(responseBody.Body.ActivityRecords) != testCase.expectedItemsCount {
+			t.Errorf(
+				"ItemsCountMismatch: expected %d, got %d [%s]",
+				testCase.expectedItemsCount, len(responseBody.Body.ActivityRecords),
+				testCase.requestPath,
+			)
+		}
+	}
+}
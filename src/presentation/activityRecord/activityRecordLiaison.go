@@ -0,0 +1,377 @@
+package tkPresentationActivityRecord
+
+import (
+	"encoding/json"
+	"errors"
+	"strings"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraActivityRecord "github.com/goinfinite/tk/src/infra/activityRecord"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+)
+
+const (
+	errOperatorAccountIdRequired              string = "OperatorAccountIdRequired"
+	errDeleteActivityRecordRequiresFilter     string = "DeleteActivityRecordRequiresFilter"
+	errInvalidRecordDetailsFilters            string = "InvalidRecordDetailsFilters"
+	errInvalidAffectedResources               string = "InvalidAffectedResources"
+	errDeleteRecordDetailsFiltersNotSupported string = "DeleteRecordDetailsFiltersNotSupported"
+)
+
+type ActivityRecordLiaisonSettings struct {
+	ActivityRecordQueryRepo tkRepository.ActivityRecordQueryRepo
+	ActivityRecordCmdRepo   tkRepository.ActivityRecordCmdRepo
+	// DefaultPagination defaults to tkUseCase.ActivityRecordsDefaultPagination.
+	DefaultPagination *tkDto.Pagination
+	// StreamHub enables the ActivityRecordController "GET /stream/" route; it must be
+	// the same hub set in the cmd repo ActivityRecordRepoSettings.
+	StreamHub *tkInfraActivityRecord.ActivityRecordStreamHub
+	// StreamHeartbeatInterval defaults to 15 seconds.
+	StreamHeartbeatInterval time.Duration
+}
+
+// ActivityRecordLiaison turns the untrusted input maps, read by the API controller or
+// built by the CLI command, into use case calls so both share the parsing and the
+// account scoping. The operator account comes from "operatorAccountId", which
+// ApiRequestInputReader reads from the Echo context: system account operators may
+// scope to any account with "accountId", every other operator is restricted to theirs.
+//
+// Since "operatorSri", "operatorIpAddress" and "correlationId" are reserved for the
+// request context, the records filters on them are "recordOperatorSri",
+// "recordOperatorIpAddress" and "recordCorrelationId".
+type ActivityRecordLiaison struct {
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo
+	activityRecordCmdRepo   tkRepository.ActivityRecordCmdRepo
+	defaultPagination       tkDto.Pagination
+	streamHub               *tkInfraActivityRecord.ActivityRecordStreamHub
+	streamHeartbeatInterval time.Duration
+}
+
+func NewActivityRecordLiaison(settings ActivityRecordLiaisonSettings) *ActivityRecordLiaison {
+	defaultPagination := tkUseCase.ActivityRecordsDefaultPagination
+	if settings.DefaultPagination != nil {
+		defaultPagination = *settings.DefaultPagination
+	}
+
+	return &ActivityRecordLiaison{
+		activityRecordQueryRepo: settings.ActivityRecordQueryRepo,
+		activityRecordCmdRepo:   settings.ActivityRecordCmdRepo,
+		defaultPagination:       defaultPagination,
+		streamHub:               settings.StreamHub,
+		streamHeartbeatInterval: settings.StreamHeartbeatInterval,
+	}
+}
+
+func optionalValueObjectParser[TypedObject any](
+	rawValue any,
+	valueObjectConstructor func(any) (TypedObject, error),
+) (*TypedObject, error) {
+	if rawValue == nil || rawValue == "" {
+		return nil, nil
+	}
+
+	valueObject, err := valueObjectConstructor(rawValue)
+	if err != nil {
+		return nil, err
+	}
+
+	return &valueObject, nil
+}
+
+// accountScopeParser returns the account the records must be scoped to, nil meaning
+// every account (only for system account operators not asking for a specific one).
+func (liaison *ActivityRecordLiaison) accountScopeParser(
+	untrustedInput map[string]any,
+) (*tkValueObject.AccountId, error) {
+	operatorAccountId, err := optionalValueObjectParser(
+		untrustedInput["operatorAccountId"], tkValueObject.NewAccountId,
+	)
+	if err != nil || operatorAccountId == nil {
+		return nil, errors.New(errOperatorAccountIdRequired)
+	}
+
+	if *operatorAccountId != tkValueObject.AccountIdSystem {
+		return operatorAccountId, nil
+	}
+
+	return optionalValueObjectParser(untrustedInput["accountId"], tkValueObject.NewAccountId)
+}
+
+// recordDetailsFiltersParser accepts a list of {"path", "operator", "value"} objects
+// or its JSON encoding (e.g. from a query param). Missing operators mean "eq".
+func recordDetailsFiltersParser(
+	rawDetailsFilters any,
+) (detailsFilters []tkDto.ActivityRecordDetailsFilter, err error) {
+	if rawDetailsFilters == nil || rawDetailsFilters == "" {
+		return detailsFilters, nil
+	}
+
+	if rawDetailsFiltersJson, assertOk := rawDetailsFilters.(string); assertOk {
+		jsonDecoder := json.NewDecoder(strings.NewReader(rawDetailsFiltersJson))
+		jsonDecoder.UseNumber()
+		decodedDetailsFilters := []any{}
+		err = jsonDecoder.Decode(&decodedDetailsFilters)
+		if err != nil {
+			return detailsFilters, errors.New(errInvalidRecordDetailsFilters)
+		}
+		rawDetailsFilters = decodedDetailsFilters
+	}
+
+	rawDetailsFiltersSlice, assertOk := rawDetailsFilters.([]any)
+	if !assertOk {
+		return detailsFilters, errors.New(errInvalidRecordDetailsFilters)
+	}
+
+	for _, rawDetailsFilter := range rawDetailsFiltersSlice {
+		rawDetailsFilterMap, assertOk := rawDetailsFilter.(map[string]any)
+		if !assertOk {
+			return detailsFilters, errors.New(errInvalidRecordDetailsFilters)
+		}
+
+		detailsPath, err := tkValueObject.NewActivityRecordDetailsPath(rawDetailsFilterMap["path"])
+		if err != nil {
+			return detailsFilters, err
+		}
+
+		comparisonOperator := tkValueObject.ComparisonOperatorEqual
+		if rawDetailsFilterMap["operator"] != nil {
+			comparisonOperator, err = tkValueObject.NewComparisonOperator(
+				rawDetailsFilterMap["operator"],
+			)
+			if err != nil {
+				return detailsFilters, err
+			}
+		}
+
+		detailsFilters = append(detailsFilters, tkDto.ActivityRecordDetailsFilter{
+			Path:     detailsPath,
+			Operator: comparisonOperator,
+			Value:    rawDetailsFilterMap["value"],
+		})
+	}
+
+	return detailsFilters, nil
+}
+
+// filtersParser parses every filter shared by the read and delete requests. Filters
+// given but invalid are rejected instead of skipped, since dropping them would widen
+// the matched records.
+func (liaison *ActivityRecordLiaison) filtersParser(
+	untrustedInput map[string]any,
+) (filtersDto tkDto.ReadActivityRecordsRequest, err error) {
+	filtersDto.RecordId, err = optionalValueObjectParser(
+		untrustedInput["recordId"], tkValueObject.NewActivityRecordId,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	filtersDto.RecordLevel, err = optionalValueObjectParser(
+		untrustedInput["recordLevel"], tkValueObject.NewActivityRecordLevel,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	filtersDto.RecordCode, err = optionalValueObjectParser(
+		untrustedInput["recordCode"], tkValueObject.NewActivityRecordCode,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	filtersDto.AffectedResources = tkPresentation.StringSliceValueObjectParser(
+		untrustedInput["affectedResources"], tkValueObject.NewSystemResourceIdentifier,
+	)
+	isAffectedResourcesSet := untrustedInput["affectedResources"] != nil &&
+		untrustedInput["affectedResources"] != ""
+	if isAffectedResourcesSet && len(filtersDto.AffectedResources) == 0 {
+		return filtersDto, errors.New(errInvalidAffectedResources)
+	}
+
+	filtersDto.OperatorSri, err = optionalValueObjectParser(
+		untrustedInput["recordOperatorSri"], tkValueObject.NewSystemResourceIdentifier,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	filtersDto.OperatorIpAddress, err = optionalValueObjectParser(
+		untrustedInput["recordOperatorIpAddress"], tkValueObject.NewIpAddress,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	filtersDto.CorrelationId, err = optionalValueObjectParser(
+		untrustedInput["recordCorrelationId"], tkValueObject.NewCorrelationId,
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	timeParamNames := []string{"createdBeforeAt", "createdAfterAt"}
+	timeParamsPtrs, err := tkPresentation.StrictTimeParamsParser(timeParamNames, untrustedInput)
+	if err != nil {
+		return filtersDto, err
+	}
+	filtersDto.CreatedBeforeAt = timeParamsPtrs["createdBeforeAt"]
+	filtersDto.CreatedAfterAt = timeParamsPtrs["createdAfterAt"]
+
+	filtersDto.RecordDetailsFilters, err = recordDetailsFiltersParser(
+		untrustedInput["recordDetailsFilters"],
+	)
+	if err != nil {
+		return filtersDto, err
+	}
+
+	return filtersDto, nil
+}
+
+// scopedFiltersParser parses the filters already scoped to the operator account,
+// returning the response status matching the error, if any.
+func (liaison *ActivityRecordLiaison) scopedFiltersParser(
+	untrustedInput map[string]any,
+) (tkDto.ReadActivityRecordsRequest, tkPresentation.LiaisonResponseStatus, error) {
+	accountId, err := liaison.accountScopeParser(untrustedInput)
+	if err != nil {
+		return tkDto.ReadActivityRecordsRequest{},
+			tkPresentation.LiaisonResponseStatusUnauthorized, err
+	}
+
+	filtersDto, err := liaison.filtersParser(untrustedInput)
+	if err != nil {
+		return filtersDto, tkPresentation.LiaisonResponseStatusUserError, err
+	}
+	filtersDto.AccountId = accountId
+
+	return filtersDto, tkPresentation.LiaisonResponseStatusSuccess, nil
+}
+
+func (liaison *ActivityRecordLiaison) Read(
+	untrustedInput map[string]any,
+) tkPresentation.LiaisonResponse {
+	requestDto, responseStatus, err := liaison.scopedFiltersParser(untrustedInput)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(responseStatus, err.Error())
+	}
+
+	requestDto.Pagination, err = tkPresentation.PaginationParser(
+		liaison.defaultPagination, untrustedInput,
+	)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError, err.Error(),
+		)
+	}
+
+	responseDto, err := tkUseCase.ReadActivityRecords(
+		liaison.activityRecordQueryRepo, requestDto,
+	)
+	if err != nil {
+		sortByNotAllowedErr := &tkInfraDb.PaginationSortByNotAllowedError{}
+		if errors.As(err, &sortByNotAllowedErr) {
+			return tkPresentation.NewLiaisonResponse(
+				tkPresentation.LiaisonResponseStatusUserError,
+				sortByNotAllowedErr, sortByNotAllowedErr.Error(),
+			)
+		}
+
+		errMessage := err.Error()
+		if strings.HasPrefix(errMessage, "PaginationQueryBuilderError") ||
+			strings.HasPrefix(errMessage, "InvalidRecordDetailsFilter") {
+			return tkPresentation.NewLiaisonResponseNoMessage(
+				tkPresentation.LiaisonResponseStatusUserError, errMessage,
+			)
+		}
+
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusInfraError, "ReadActivityRecordsInfraError",
+		)
+	}
+
+	return tkPresentation.NewLiaisonResponseNoMessage(
+		tkPresentation.LiaisonResponseStatusSuccess, responseDto,
+	)
+}
+
+// Delete requires at least one filter besides the account scope, so an empty request
+// can't wipe the whole trail. The request context "operatorSri", "operatorIpAddress"
+// and "correlationId" attribute the deletion activity records.
+func (liaison *ActivityRecordLiaison) Delete(
+	untrustedInput map[string]any,
+) tkPresentation.LiaisonResponse {
+	filtersDto, responseStatus, err := liaison.scopedFiltersParser(untrustedInput)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(responseStatus, err.Error())
+	}
+
+	if len(filtersDto.RecordDetailsFilters) > 0 {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError,
+			errDeleteRecordDetailsFiltersNotSupported,
+		)
+	}
+
+	deleteDto := tkDto.NewDeleteActivityRecord(
+		filtersDto.RecordId, filtersDto.RecordLevel, filtersDto.RecordCode,
+		filtersDto.AffectedResources, filtersDto.OperatorSri,
+		filtersDto.OperatorIpAddress, filtersDto.CreatedBeforeAt, filtersDto.CreatedAfterAt,
+	)
+	deleteDto.CorrelationId = filtersDto.CorrelationId
+	isFilterSet := deleteDto.RecordId != nil || deleteDto.RecordLevel != nil ||
+		deleteDto.RecordCode != nil || len(deleteDto.AffectedResources) > 0 ||
+		deleteDto.OperatorSri != nil || deleteDto.OperatorIpAddress != nil ||
+		deleteDto.CreatedBeforeAt != nil || deleteDto.CreatedAfterAt != nil ||
+		deleteDto.CorrelationId != nil
+	if !isFilterSet {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError,
+			errDeleteActivityRecordRequiresFilter,
+		)
+	}
+	deleteDto.AccountId = filtersDto.AccountId
+
+	deleteDto.RequestOperatorSri, err = optionalValueObjectParser(
+		untrustedInput["operatorSri"], tkValueObject.NewSystemResourceIdentifier,
+	)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError, err.Error(),
+		)
+	}
+	deleteDto.RequestOperatorIpAddress, err = optionalValueObjectParser(
+		untrustedInput["operatorIpAddress"], tkValueObject.NewIpAddress,
+	)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError, err.Error(),
+		)
+	}
+	deleteDto.RequestCorrelationId, err = optionalValueObjectParser(
+		untrustedInput["correlationId"], tkValueObject.NewCorrelationId,
+	)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusUserError, err.Error(),
+		)
+	}
+
+	responseDto, err := tkUseCase.DeleteActivityRecordWithCount(
+		liaison.activityRecordCmdRepo, deleteDto,
+	)
+	if err != nil {
+		return tkPresentation.NewLiaisonResponseNoMessage(
+			tkPresentation.LiaisonResponseStatusInfraError, err.Error(),
+		)
+	}
+
+	return tkPresentation.NewLiaisonResponse(
+		tkPresentation.LiaisonResponseStatusSuccess, responseDto, "ActivityRecordsDeleted",
+	)
+}
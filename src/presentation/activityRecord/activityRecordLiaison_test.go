@@ -0,0 +1,320 @@
+package tkPresentationActivityRecord
+
+import (
+	"fmt"
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraActivityRecord "github.com/goinfinite/tk/src/infra/activityRecord"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+)
+
+// setupTestActivityRecordLiaison creates a liaison backed by an in-memory trail
+// database holding one record per operator SRI, with IDs following the slice order.
+func setupTestActivityRecordLiaison(
+	t *testing.T,
+	operatorSris []string,
+) *ActivityRecordLiaison {
+	t.Helper()
+
+	trailDbSvc, err := tkInfraDb.NewTrailDatabaseServiceWithSettings(
+		tkInfraDb.TrailDatabaseServiceSettings{Dsn: tkInfraDb.TrailDatabaseInMemoryDsn},
+	)
+	if err != nil {
+		t.Fatalf("SetupFailed: '%s'", err.Error())
+	}
+	t.Cleanup(func() {
+		rawDb, err := trailDbSvc.Handler.DB()
+		if err == nil {
+			_ = rawDb.Close()
+		}
+	})
+
+	cmdRepo := tkInfraActivityRecord.NewActivityRecordCmdRepo(trailDbSvc)
+	for _, rawOperatorSri := range operatorSris {
+		operatorSri := tkValueObject.NewSystemResourceIdentifierMustCreate(rawOperatorSri)
+		err = cmdRepo.Create(tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:  tkValueObject.ActivityRecordCode("AccountUpdated"),
+			OperatorSri: &operatorSri,
+		})
+		if err != nil {
+			t.Fatalf("CreateTestActivityRecordFailed: '%s'", err.Error())
+		}
+	}
+
+	return NewActivityRecordLiaison(ActivityRecordLiaisonSettings{
+		ActivityRecordQueryRepo: tkInfraActivityRecord.NewActivityRecordQueryRepo(trailDbSvc),
+		ActivityRecordCmdRepo:   cmdRepo,
+	})
+}
+
+func readTestRecordIds(liaisonResponse tkPresentation.LiaisonResponse) string {
+	responseDto, assertOk := liaisonResponse.Body.(tkDto.ReadActivityRecordsResponse)
+	if !assertOk {
+		return fmt.Sprint(liaisonResponse.Body)
+	}
+
+	recordIds := []uint64{}
+	for _, activityRecord := range responseDto.ActivityRecords {
+		recordIds = append(recordIds, activityRecord.RecordId.Uint64())
+	}
+	return fmt.Sprint(recordIds)
+}
+
+func TestActivityRecordLiaisonRead(t *testing.T) {
+	liaison := setupTestActivityRecordLiaison(t, []string{
+		"sri://0:account/1", "sri://0:account/2", "sri://1:mailbox/info",
+	})
+
+	testCaseStructs := []struct {
+		name              string
+		untrustedInput    map[string]any
+		expectedStatus    tkPresentation.LiaisonResponseStatus
+		expectedRecordIds string
+	}{
+		{
+			"MissingOperatorAccountId",
+			map[string]any{},
+			tkPresentation.LiaisonResponseStatusUnauthorized, errOperatorAccountIdRequired,
+		},
+		{
+			"ScopedToOperatorAccount",
+			map[string]any{"operatorAccountId": tkValueObject.AccountId(1)},
+			tkPresentation.LiaisonResponseStatusSuccess, "[1 3]",
+		},
+		{
+			"AccountIdIgnoredForNonSystemOperators",
+			map[string]any{"operatorAccountId": tkValueObject.AccountId(1), "accountId": "2"},
+			tkPresentation.LiaisonResponseStatusSuccess, "[1 3]",
+		},
+		{
+			"SystemOperatorReadsEveryAccount",
+			map[string]any{"operatorAccountId": tkValueObject.AccountIdSystem},
+			tkPresentation.LiaisonResponseStatusSuccess, "[1 2 3]",
+		},
+		{
+			"SystemOperatorScopedByAccountId",
+			map[string]any{"operatorAccountId": tkValueObject.AccountIdSystem, "accountId": "2"},
+			tkPresentation.LiaisonResponseStatusSuccess, "[2]",
+		},
+		{
+			"RecordOperatorSriAndPaginationFilters",
+			map[string]any{
+				"operatorAccountId": "0",
+				"recordOperatorSri": "sri://1:mailbox/info",
+				"sortDirection":     "desc",
+			},
+			tkPresentation.LiaisonResponseStatusSuccess, "[3]",
+		},
+		{
+			"RequestCorrelationIdIsNotAFilter",
+			map[string]any{
+				"operatorAccountId": "0",
+				"correlationId":     tkValueObject.CorrelationId("req_1"),
+			},
+			tkPresentation.LiaisonResponseStatusSuccess, "[1 2 3]",
+		},
+		{
+			"RecordCorrelationIdFilter",
+			map[string]any{"operatorAccountId": "0", "recordCorrelationId": "req_1"},
+			tkPresentation.LiaisonResponseStatusSuccess, "[]",
+		},
+		{
+			"InvalidRecordCorrelationId",
+			map[string]any{"operatorAccountId": "0", "recordCorrelationId": "req 1"},
+			tkPresentation.LiaisonResponseStatusUserError, "",
+		},
+		{
+			"InvalidRecordLevel",
+			map[string]any{"operatorAccountId": "0", "recordLevel": "LOUD"},
+			tkPresentation.LiaisonResponseStatusUserError, "",
+		},
+		{
+			"RelativeAndRfc3339CreatedAtFilters",
+			map[string]any{
+				"operatorAccountId": "0",
+				"createdAfterAt":    "1 hour ago",
+				"createdBeforeAt":   "2999-01-01T00:00:00Z",
+			},
+			tkPresentation.LiaisonResponseStatusSuccess, "[1 2 3]",
+		},
+		{
+			"InvalidCreatedBeforeAt",
+			map[string]any{"operatorAccountId": "0", "createdBeforeAt": "yesterday-ish"},
+			tkPresentation.LiaisonResponseStatusUserError, "InvalidCreatedBeforeAt",
+		},
+		{
+			"InvalidAffectedResources",
+			map[string]any{"operatorAccountId": "0", "affectedResources": "not-a-sri"},
+			tkPresentation.LiaisonResponseStatusUserError, errInvalidAffectedResources,
+		},
+		{
+			"SortByOutsideAllowlist",
+			map[string]any{"operatorAccountId": "0", "sortBy": "recordDetails"},
+			tkPresentation.LiaisonResponseStatusUserError, "",
+		},
+	}
+
+	for _, testCase := range testCaseStructs {
+		t.Run(testCase.name, func(t *testing.T) {
+			liaisonResponse := liaison.Read(testCase.untrustedInput)
+			if liaisonResponse.Status != testCase.expectedStatus {
+				t.Fatalf(
+					"StatusMismatch: expected %s, got %s (%v)",
+					testCase.expectedStatus, liaisonResponse.Status, liaisonResponse.Body,
+				)
+			}
+			if testCase.expectedRecordIds == "" {
+				return
+			}
+			if readTestRecordIds(liaisonResponse) != testCase.expectedRecordIds {
+				t.Errorf(
+					"BodyMismatch: expected %s, got %s",
+					testCase.expectedRecordIds, readTestRecordIds(liaisonResponse),
+				)
+			}
+		})
+	}
+}
+
+func TestRecordDetailsFiltersParser(t *testing.T) {
+	testCaseStructs := []struct {
+		rawDetailsFilters    any
+		expectedFiltersCount int
+		expectError          bool
+	}{
+		{nil, 0, false},
+		{`[{"path":"user.role","value":"admin"},{"path":"attempts","operator":"gte","value":3}]`, 2, false},
+		{[]any{map[string]any{"path": "enabled", "value": true}}, 1, false},
+		{`{"path":"user.role"}`, 0, true},
+		{`[{"path":"user.role","operator":"between"}]`, 0, true},
+		{[]any{"user.role"}, 0, true},
+	}
+
+	for _, testCase := range testCaseStructs {
+		detailsFilters, err := recordDetailsFiltersParser(testCase.rawDetailsFilters)
+		if testCase.expectError {
+			if err == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.rawDetailsFilters)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnexpectedError: '%s' [%v]", err.Error(), testCase.rawDetailsFilters)
+			continue
+		}
+		if len(detailsFilters) != testCase.expectedFiltersCount {
+			t.Errorf(
+				"FiltersCountMismatch: expected %d, got %d [%v]",
+				testCase.expectedFiltersCount, len(detailsFilters), testCase.rawDetailsFilters,
+			)
+		}
+	}
+}
+
+func TestActivityRecordLiaisonDelete(t *testing.T) {
+	t.Run("RequiresFilter", func(t *testing.T) {
+		liaison := setupTestActivityRecordLiaison(t, []string{"sri://0:account/1"})
+
+		liaisonResponse := liaison.Delete(map[string]any{"operatorAccountId": "0"})
+		if liaisonResponse.Body != errDeleteActivityRecordRequiresFilter {
+			t.Errorf("MissingExpectedError: %s", errDeleteActivityRecordRequiresFilter)
+		}
+
+		liaisonResponse = liaison.Delete(map[string]any{
+			"operatorAccountId":    "0",
+			"recordCode":           "AccountUpdated",
+			"recordDetailsFilters": `[{"path":"user.role","value":"admin"}]`,
+		})
+		if liaisonResponse.Body != errDeleteRecordDetailsFiltersNotSupported {
+			t.Errorf("MissingExpectedError: %s", errDeleteRecordDetailsFiltersNotSupported)
+		}
+	})
+
+	t.Run("ScopedToOperatorAccount", func(t *testing.T) {
+		liaison := setupTestActivityRecordLiaison(t, []string{
+			"sri://0:account/1", "sri://0:account/2", "sri://1:mailbox/info",
+		})
+
+		liaisonResponse := liaison.Delete(map[string]any{
+			"operatorAccountId": tkValueObject.AccountId(2),
+			"recordCode":        "AccountUpdated",
+		})
+		if liaisonResponse.Status != tkPresentation.LiaisonResponseStatusSuccess {
+			t.Fatalf("UnexpectedError: %v", liaisonResponse.Body)
+		}
+		deleteResponseDto, assertOk := liaisonResponse.Body.(tkDto.DeleteActivityRecordResponse)
+		if !assertOk || deleteResponseDto.DeletedRecordsCount != 1 {
+			t.Errorf("DeletedRecordsCountMismatch: expected 1, got %v", liaisonResponse.Body)
+		}
+
+		// Every deletion leaves its own ActivityRecordsDeleted record (IDs 4 and 5).
+		liaisonResponse = liaison.Read(map[string]any{"operatorAccountId": "0"})
+		if readTestRecordIds(liaisonResponse) != "[1 3 4]" {
+			t.Errorf(
+				"RemainingRecordsMismatch: expected [1 3 4], got %s",
+				readTestRecordIds(liaisonResponse),
+			)
+		}
+
+		liaisonResponse = liaison.Delete(map[string]any{
+			"operatorAccountId": "0", "recordId": "3",
+		})
+		if liaisonResponse.Status != tkPresentation.LiaisonResponseStatusSuccess {
+			t.Fatalf("UnexpectedError: %v", liaisonResponse.Body)
+		}
+
+		liaisonResponse = liaison.Read(map[string]any{"operatorAccountId": "0"})
+		if readTestRecordIds(liaisonResponse) != "[1 4 5]" {
+			t.Errorf(
+				"RemainingRecordsMismatch: expected [1 4 5], got %s",
+				readTestRecordIds(liaisonResponse),
+			)
+		}
+	})
+
+	t.Run("AttributedToRequestOperator", func(t *testing.T) {
+		liaison := setupTestActivityRecordLiaison(t, []string{"sri://0:account/1"})
+
+		liaisonResponse := liaison.Delete(map[string]any{
+			"operatorAccountId": "0",
+			"operatorSri":       tkValueObject.NewSriAccount(tkValueObject.AccountIdSystem),
+			"operatorIpAddress": "192.168.1.10",
+			"correlationId":     "req-42",
+			"recordId":          "1",
+		})
+		if liaisonResponse.Status != tkPresentation.LiaisonResponseStatusSuccess {
+			t.Fatalf("UnexpectedError: %v", liaisonResponse.Body)
+		}
+
+		liaisonResponse = liaison.Read(map[string]any{
+			"operatorAccountId": "0", "recordCode": "ActivityRecordsDeleted",
+		})
+		responseDto, assertOk := liaisonResponse.Body.(tkDto.ReadActivityRecordsResponse)
+		if !assertOk || len(responseDto.ActivityRecords) != 1 {
+			t.Fatalf("DeletedRecordNotFound: %v", liaisonResponse.Body)
+		}
+		deletedRecord := responseDto.ActivityRecords[0]
+		if deletedRecord.OperatorSri == nil ||
+			deletedRecord.OperatorSri.String() != "sri://0:account/0" {
+			t.Errorf("OperatorSriMismatch: %v", deletedRecord.OperatorSri)
+		}
+		if deletedRecord.OperatorIpAddress == nil ||
+			deletedRecord.OperatorIpAddress.String() != "192.168.1.10" {
+			t.Errorf("OperatorIpAddressMismatch: %v", deletedRecord.OperatorIpAddress)
+		}
+		if deletedRecord.CorrelationId == nil || deletedRecord.CorrelationId.String() != "req-42" {
+			t.Errorf("CorrelationIdMismatch: %v", deletedRecord.CorrelationId)
+		}
+
+		liaisonResponse = liaison.Delete(map[string]any{
+			"operatorAccountId": "0", "operatorIpAddress": "invalid", "recordId": "2",
+		})
+		if liaisonResponse.Status != tkPresentation.LiaisonResponseStatusUserError {
+			t.Errorf("MissingExpectedError: InvalidIpAddress")
+		}
+	})
+}
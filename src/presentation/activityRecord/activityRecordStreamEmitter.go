@@ -0,0 +1,202 @@
+package tkPresentationActivityRecord
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraActivityRecord "github.com/goinfinite/tk/src/infra/activityRecord"
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	activityRecordStreamHeartbeatIntervalDefault time.Duration = 15 * time.Second
+	activityRecordStreamReplayBatchSize          uint16        = 100
+	activityRecordStreamEventName                string        = "activityRecord"
+	errActivityRecordStreamNotEnabled            string        = "ActivityRecordStreamNotEnabled"
+	errInvalidLastEventId                        string        = "InvalidLastEventId"
+)
+
+type ActivityRecordStreamEmitterSettings struct {
+	StreamHub               *tkInfraActivityRecord.ActivityRecordStreamHub
+	ActivityRecordQueryRepo tkRepository.ActivityRecordQueryRepo
+	// HeartbeatInterval defaults to 15 seconds.
+	HeartbeatInterval time.Duration
+}
+
+func activityRecordStreamEventWriter(
+	echoResponse *echo.Response,
+	activityRecord tkEntity.ActivityRecord,
+) error {
+	activityRecordJsonBytes, err := json.Marshal(activityRecord)
+	if err != nil {
+		return err
+	}
+
+	_, err = fmt.Fprintf(
+		echoResponse, "id: %d\nevent: %s\ndata: %s\n\n",
+		activityRecord.RecordId.Uint64(), activityRecordStreamEventName,
+		activityRecordJsonBytes,
+	)
+	if err != nil {
+		return err
+	}
+
+	echoResponse.Flush()
+	return nil
+}
+
+// activityRecordStreamReplayer writes the records created after the lastEventId,
+// read from the database in ascending ID batches, and returns the last ID written.
+func activityRecordStreamReplayer(
+	echoResponse *echo.Response,
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo,
+	filterDto tkDto.ReadActivityRecordsRequest,
+	lastEventId tkValueObject.ActivityRecordId,
+) (lastWrittenId tkValueObject.ActivityRecordId, err error) {
+	lastWrittenId = lastEventId
+	for {
+		lastSeenId, err := tkValueObject.NewPaginationLastSeenId(lastWrittenId.String())
+		if err != nil {
+			return lastWrittenId, err
+		}
+		filterDto.Pagination = tkDto.Pagination{
+			ItemsPerPage: activityRecordStreamReplayBatchSize,
+			LastSeenId:   &lastSeenId,
+		}
+
+		responseDto, err := activityRecordQueryRepo.Read(filterDto)
+		if err != nil {
+			return lastWrittenId, err
+		}
+
+		for _, activityRecord := range responseDto.ActivityRecords {
+			err = activityRecordStreamEventWriter(echoResponse, activityRecord)
+			if err != nil {
+				return lastWrittenId, err
+			}
+			lastWrittenId = activityRecord.RecordId
+		}
+
+		if len(responseDto.ActivityRecords) < int(activityRecordStreamReplayBatchSize) {
+			return lastWrittenId, nil
+		}
+	}
+}
+
+// ActivityRecordStreamEmitter streams, as Server-Sent Events, the new records matching
+// the filter ("activityRecord" events whose ID is the record ID) until the client
+// disconnects, sending comment heartbeats meanwhile. Clients resuming with the
+// Last-Event-ID header (or the "lastEventId" query param) first receive the matching
+// records they missed, read from the database. A client falling too far behind is
+// disconnected so it resumes the same way.
+//
+// The Echo server WriteTimeout, if any, also ends the stream.
+func ActivityRecordStreamEmitter(
+	echoContext echo.Context,
+	settings ActivityRecordStreamEmitterSettings,
+	filterDto tkDto.ReadActivityRecordsRequest,
+) error {
+	if settings.StreamHub == nil || settings.ActivityRecordQueryRepo == nil {
+		return tkPresentation.LiaisonApiResponseEmitter(
+			echoContext, tkPresentation.NewLiaisonResponseNoMessage(
+				tkPresentation.LiaisonResponseStatusNotFound, errActivityRecordStreamNotEnabled,
+			),
+		)
+	}
+
+	var lastEventId *tkValueObject.ActivityRecordId
+	rawLastEventId := echoContext.Request().Header.Get("Last-Event-ID")
+	if rawLastEventId == "" {
+		rawLastEventId = echoContext.QueryParam("lastEventId")
+	}
+	if rawLastEventId != "" {
+		parsedLastEventId, err := tkValueObject.NewActivityRecordId(rawLastEventId)
+		if err != nil {
+			return tkPresentation.LiaisonApiResponseEmitter(
+				echoContext, tkPresentation.NewLiaisonResponseNoMessage(
+					tkPresentation.LiaisonResponseStatusUserError, errInvalidLastEventId,
+				),
+			)
+		}
+		lastEventId = &parsedLastEventId
+	}
+
+	// Subscribed before the replay, so records created meanwhile aren't missed.
+	subscription, err := settings.StreamHub.Subscribe(filterDto)
+	if err != nil {
+		return tkPresentation.LiaisonApiResponseEmitter(
+			echoContext, tkPresentation.NewLiaisonResponseNoMessage(
+				tkPresentation.LiaisonResponseStatusUserError, err.Error(),
+			),
+		)
+	}
+	defer settings.StreamHub.Unsubscribe(subscription)
+
+	echoResponse := echoContext.Response()
+	echoResponse.Header().Set(echo.HeaderContentType, "text/event-stream")
+	echoResponse.Header().Set("Cache-Control", "no-cache")
+	echoResponse.Header().Set(echo.HeaderConnection, "keep-alive")
+	echoResponse.Header().Set("X-Accel-Buffering", "no")
+	echoResponse.WriteHeader(http.StatusOK)
+	echoResponse.Flush()
+
+	// Live records already written by the replay are skipped. Live records themselves
+	// are never repeated, though they may arrive slightly out of ID order.
+	replayedUpToId := tkValueObject.ActivityRecordId(0)
+	if lastEventId != nil {
+		replayedUpToId, err = activityRecordStreamReplayer(
+			echoResponse, settings.ActivityRecordQueryRepo, filterDto, *lastEventId,
+		)
+		if err != nil {
+			slog.Debug("ActivityRecordStreamReplayError", slog.String("err", err.Error()))
+			return nil
+		}
+	}
+
+	heartbeatInterval := settings.HeartbeatInterval
+	if heartbeatInterval <= 0 {
+		heartbeatInterval = activityRecordStreamHeartbeatIntervalDefault
+	}
+	heartbeatTicker := time.NewTicker(heartbeatInterval)
+	defer heartbeatTicker.Stop()
+
+	requestContext := echoContext.Request().Context()
+	for {
+		select {
+		case <-requestContext.Done():
+			return nil
+
+		case <-heartbeatTicker.C:
+			_, err = fmt.Fprint(echoResponse, ": heartbeat\n\n")
+			if err != nil {
+				return nil
+			}
+			echoResponse.Flush()
+
+		case activityRecord, isSubscribed := <-subscription.Records:
+			if !isSubscribed {
+				if subscription.IsOverflowed() {
+					slog.Debug("ActivityRecordStreamSubscriberOverflowed")
+				}
+				return nil
+			}
+			if activityRecord.RecordId <= replayedUpToId {
+				continue
+			}
+
+			err = activityRecordStreamEventWriter(echoResponse, activityRecord)
+			if err != nil {
+				slog.Debug("ActivityRecordStreamWriteError", slog.String("err", err.Error()))
+				return nil
+			}
+		}
+	}
+}
@@ -0,0 +1,187 @@
+package tkPresentationActivityRecord
+
+import (
+	"bufio"
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkInfraActivityRecord "github.com/goinfinite/tk/src/infra/activityRecord"
+	tkInfraDb "github.com/goinfinite/tk/src/infra/db"
+	"github.com/labstack/echo/v4"
+)
+
+func readTestStreamLine(t *testing.T, streamLines <-chan string) string {
+	t.Helper()
+
+	select {
+	case streamLine, isOpen := <-streamLines:
+		if !isOpen {
+			t.Fatal("StreamClosedUnexpectedly")
+		}
+		return streamLine
+	case <-time.After(5 * time.Second):
+		t.Fatal("StreamLineTimeout")
+	}
+	return ""
+}
+
+func TestActivityRecordStreamEmitter(t *testing.T) {
+	trailDbSvc, err := tkInfraDb.NewTrailDatabaseServiceWithSettings(
+		tkInfraDb.TrailDatabaseServiceSettings{Dsn: tkInfraDb.TrailDatabaseInMemoryDsn},
+	)
+	if err != nil {
+		t.Fatalf("SetupFailed: '%s'", err.Error())
+	}
+	t.Cleanup(func() {
+		rawDb, err := trailDbSvc.Handler.DB()
+		if err == nil {
+			_ = rawDb.Close()
+		}
+	})
+
+	streamHub := tkInfraActivityRecord.NewActivityRecordStreamHub(
+		tkInfraActivityRecord.ActivityRecordStreamHubSettings{},
+	)
+	cmdRepo, err := tkInfraActivityRecord.NewActivityRecordCmdRepoWithSettings(
+		trailDbSvc, tkInfraActivityRecord.ActivityRecordRepoSettings{StreamHub: streamHub},
+	)
+	if err != nil {
+		t.Fatalf("SetupFailed: '%s'", err.Error())
+	}
+	testRecordCreator := func(rawOperatorSri string) {
+		operatorSri := tkValueObject.NewSystemResourceIdentifierMustCreate(rawOperatorSri)
+		err := cmdRepo.Create(tkDto.CreateActivityRecord{
+			RecordLevel: tkValueObject.ActivityRecordLevelInfo,
+			RecordCode:  tkValueObject.ActivityRecordCode("AccountUpdated"),
+			OperatorSri: &operatorSri,
+		})
+		if err != nil {
+			t.Fatalf("CreateTestActivityRecordFailed: '%s'", err.Error())
+		}
+	}
+	for _, rawOperatorSri := range []string{
+		"sri://0:account/1", "sri://0:account/2", "sri://0:account/1",
+	} {
+		testRecordCreator(rawOperatorSri)
+	}
+
+	liaison := NewActivityRecordLiaison(ActivityRecordLiaisonSettings{
+		ActivityRecordQueryRepo: tkInfraActivityRecord.NewActivityRecordQueryRepo(trailDbSvc),
+		ActivityRecordCmdRepo:   cmdRepo,
+		StreamHub:               streamHub,
+		StreamHeartbeatInterval: 50 * time.Millisecond,
+	})
+	echoInstance := echo.New()
+	routerGroup := echoInstance.Group(
+		"/v1/activity-record",
+		func(next echo.HandlerFunc) echo.HandlerFunc {
+			return func(echoContext echo.Context) error {
+				echoContext.Set("operatorAccountId", tkValueObject.AccountId(1))
+				return next(echoContext)
+			}
+		},
+	)
+	NewActivityRecordController(liaison).RegisterRoutes(routerGroup)
+	httpServer := httptest.NewServer(echoInstance)
+	t.Cleanup(httpServer.Close)
+
+	t.Run("InvalidLastEventId", func(t *testing.T) {
+		httpResponse, err := http.Get(
+			httpServer.URL + "/v1/activity-record/stream/?lastEventId=abc",
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		defer httpResponse.Body.Close()
+
+		if httpResponse.StatusCode != http.StatusBadRequest {
+			t.Errorf("HttpStatusMismatch: expected 400, got %d", httpResponse.StatusCode)
+		}
+	})
+
+	t.Run("ReplayThenLiveRecords", func(t *testing.T) {
+		requestContext, requestCancel := context.WithCancel(context.Background())
+		defer requestCancel()
+
+		httpRequest, err := http.NewRequestWithContext(
+			requestContext, http.MethodGet, httpServer.URL+"/v1/activity-record/stream/", nil,
+		)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		httpRequest.Header.Set("Last-Event-ID", "0")
+
+		httpResponse, err := http.DefaultClient.Do(httpRequest)
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		defer httpResponse.Body.Close()
+
+		if httpResponse.Header.Get(echo.HeaderContentType) != "text/event-stream" {
+			t.Fatalf("ContentTypeMismatch: got %s", httpResponse.Header.Get(echo.HeaderContentType))
+		}
+
+		streamLines := make(chan string)
+		go func() {
+			defer close(streamLines)
+			lineScanner := bufio.NewScanner(httpResponse.Body)
+			for lineScanner.Scan() {
+				select {
+				case streamLines <- lineScanner.Text():
+				case <-requestContext.Done():
+					return
+				}
+			}
+		}()
+
+		// Records 1 and 3 belong to account 1, record 2 is out of its scope.
+		for _, expectedEventId := range []string{"id: 1", "id: 3"} {
+			if streamLine := readTestStreamLine(t, streamLines); streamLine != expectedEventId {
+				t.Fatalf("ReplayedEventMismatch: expected '%s', got '%s'", expectedEventId, streamLine)
+			}
+			if streamLine := readTestStreamLine(t, streamLines); streamLine != "event: activityRecord" {
+				t.Fatalf("EventNameMismatch: got '%s'", streamLine)
+			}
+			if streamLine := readTestStreamLine(t, streamLines); !strings.HasPrefix(streamLine, "data: {") {
+				t.Fatalf("EventDataMismatch: got '%s'", streamLine)
+			}
+			_ = readTestStreamLine(t, streamLines)
+		}
+
+		testRecordCreator("sri://0:account/2")
+		testRecordCreator("sri://0:account/1")
+
+		isHeartbeatReceived := false
+		for {
+			streamLine := readTestStreamLine(t, streamLines)
+			if streamLine == ": heartbeat" {
+				isHeartbeatReceived = true
+				continue
+			}
+			if strings.HasPrefix(streamLine, "id: ") {
+				if streamLine != "id: 5" {
+					t.Fatalf("LiveEventMismatch: expected 'id: 5', got '%s'", streamLine)
+				}
+				break
+			}
+		}
+
+		for !isHeartbeatReceived {
+			isHeartbeatReceived = readTestStreamLine(t, streamLines) == ": heartbeat"
+		}
+
+		requestCancel()
+		for attemptsCount := 0; streamHub.SubscribersCount() > 0; attemptsCount++ {
+			if attemptsCount > 100 {
+				t.Fatal("SubscriptionNotReleased")
+			}
+			time.Sleep(20 * time.Millisecond)
+		}
+	})
+}
@@ -0,0 +1,94 @@
+package tkPresentationActivityRecord
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"time"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkRepository "github.com/goinfinite/tk/src/domain/repository"
+	tkUseCase "github.com/goinfinite/tk/src/domain/useCase"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	tkPresentation "github.com/goinfinite/tk/src/presentation"
+	"github.com/labstack/echo/v4"
+)
+
+// activityRecordsDownloadWriter delays the download headers until the first byte is
+// written, so errors detected before the export starts can still become a regular
+// JSON error response.
+type activityRecordsDownloadWriter struct {
+	echoResponse    *echo.Response
+	downloadHeaders map[string]string
+}
+
+func (writer *activityRecordsDownloadWriter) commit() {
+	if writer.echoResponse.Committed {
+		return
+	}
+
+	for headerName, headerValue := range writer.downloadHeaders {
+		writer.echoResponse.Header().Set(headerName, headerValue)
+	}
+	writer.echoResponse.WriteHeader(http.StatusOK)
+}
+
+func (writer *activityRecordsDownloadWriter) Write(chunkBytes []byte) (int, error) {
+	writer.commit()
+	return writer.echoResponse.Write(chunkBytes)
+}
+
+func (writer *activityRecordsDownloadWriter) Flush() {
+	_ = http.NewResponseController(writer.echoResponse.Writer).Flush()
+}
+
+// ActivityRecordsExportEmitter streams the tkUseCase.ExportActivityRecords output as
+// a file download (e.g. "activity-records-20260102T150405Z.csv"). Errors raised before
+// the first record is written are emitted as liaison responses. Errors after that are
+// logged since the download is already underway and, on NDJSON exports, also written
+// as a final {"exportError":"..."} line so the client can tell the file is incomplete.
+func ActivityRecordsExportEmitter(
+	echoContext echo.Context,
+	activityRecordQueryRepo tkRepository.ActivityRecordQueryRepo,
+	exportDto tkDto.ExportActivityRecordsRequest,
+) error {
+	downloadFileName := "activity-records-" +
+		time.Now().UTC().Format("20060102T150405Z") + "." + exportDto.ExportFormat.String()
+	downloadWriter := &activityRecordsDownloadWriter{
+		echoResponse: echoContext.Response(),
+		downloadHeaders: map[string]string{
+			echo.HeaderContentType:         exportDto.ExportFormat.ReadMimeType().String(),
+			echo.HeaderContentDisposition:  `attachment; filename="` + downloadFileName + `"`,
+			echo.HeaderXContentTypeOptions: "nosniff",
+			"Cache-Control":                "no-store",
+		},
+	}
+
+	_, err := tkUseCase.ExportActivityRecords(
+		activityRecordQueryRepo, exportDto, downloadWriter,
+	)
+	if err != nil {
+		if echoContext.Response().Committed {
+			slog.Error("ActivityRecordsExportInterrupted", slog.String("err", err.Error()))
+			if exportDto.ExportFormat == tkValueObject.ExportFormatNdjson {
+				_ = json.NewEncoder(downloadWriter).Encode(
+					map[string]string{"exportError": err.Error()},
+				)
+			}
+			return nil
+		}
+
+		liaisonResponseStatus := tkPresentation.LiaisonResponseStatusUserError
+		if err.Error() == "ExportActivityRecordsInfraError" {
+			liaisonResponseStatus = tkPresentation.LiaisonResponseStatusInfraError
+		}
+		return tkPresentation.LiaisonApiResponseEmitter(
+			echoContext,
+			tkPresentation.NewLiaisonResponseNoMessage(liaisonResponseStatus, err.Error()),
+		)
+	}
+
+	// Empty NDJSON exports never write, but the download must still be sent.
+	downloadWriter.commit()
+	return nil
+}
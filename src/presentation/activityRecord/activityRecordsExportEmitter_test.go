@@ -0,0 +1,228 @@
+package tkPresentationActivityRecord
+
+import (
+	"encoding/csv"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strconv"
+	"strings"
+	"testing"
+
+	tkDto "github.com/goinfinite/tk/src/domain/dto"
+	tkEntity "github.com/goinfinite/tk/src/domain/entity"
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/labstack/echo/v4"
+)
+
+// fakeExportQueryRepo fails with readErr once successfulReadCallsCount reads are done.
+type fakeExportQueryRepo struct {
+	activityRecords          []tkEntity.ActivityRecord
+	readCallsCount           int
+	successfulReadCallsCount int
+	readErr                  error
+}
+
+func (repo *fakeExportQueryRepo) Read(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (responseDto tkDto.ReadActivityRecordsResponse, err error) {
+	repo.readCallsCount++
+	if repo.readErr != nil && repo.readCallsCount > repo.successfulReadCallsCount {
+		return responseDto, repo.readErr
+	}
+
+	lastSeenId := uint64(0)
+	if requestDto.Pagination.LastSeenId != nil {
+		lastSeenId, _ = strconv.ParseUint(requestDto.Pagination.LastSeenId.String(), 10, 64)
+	}
+
+	for _, activityRecord := range repo.activityRecords {
+		if activityRecord.RecordId.Uint64() <= lastSeenId {
+			continue
+		}
+		if len(responseDto.ActivityRecords) == int(requestDto.Pagination.ItemsPerPage) {
+			break
+		}
+		responseDto.ActivityRecords = append(responseDto.ActivityRecords, activityRecord)
+	}
+
+	return responseDto, nil
+}
+
+func (repo *fakeExportQueryRepo) ReadFirst(
+	requestDto tkDto.ReadActivityRecordsRequest,
+) (activityRecord tkEntity.ActivityRecord, err error) {
+	return activityRecord, errors.New("NotImplemented")
+}
+
+func (repo *fakeExportQueryRepo) Aggregate(
+	aggregateDto tkDto.AggregateActivityRecordsRequest,
+) (responseDto tkDto.AggregateActivityRecordsResponse, err error) {
+	return responseDto, errors.New("NotImplemented")
+}
+
+func (repo *fakeExportQueryRepo) VerifyHashChain() (
+	responseDto tkDto.VerifyActivityRecordsHashChainResponse, err error,
+) {
+	return responseDto, errors.New("NotImplemented")
+}
+
+func TestActivityRecordsExportEmitter(t *testing.T) {
+	recordCode, _ := tkValueObject.NewActivityRecordCode("ExportTest")
+	affectedSri, _ := tkValueObject.NewSystemResourceIdentifier("sri://12:mailbox/1")
+	activityRecords := []tkEntity.ActivityRecord{}
+	for recordIndex := range 20 {
+		recordId, _ := tkValueObject.NewActivityRecordId(recordIndex + 1)
+		activityRecords = append(activityRecords, tkEntity.NewActivityRecord(
+			recordId, tkValueObject.ActivityRecordLevelInfo, recordCode,
+			[]tkValueObject.SystemResourceIdentifier{affectedSri},
+			map[string]any{"recordIndex": recordIndex}, nil, nil,
+			tkValueObject.UnixTime(1700000000+recordIndex),
+		))
+	}
+	activityRecords[0].RecordDetails = "=HYPERLINK(\"http://evil\")"
+
+	emitExport := func(
+		t *testing.T, queryRepo *fakeExportQueryRepo, exportFormat tkValueObject.ExportFormat,
+	) *httptest.ResponseRecorder {
+		t.Helper()
+		httpRequest := httptest.NewRequest(http.MethodGet, "/activity-records/export", nil)
+		responseRecorder := httptest.NewRecorder()
+		echoContext := echo.New().NewContext(httpRequest, responseRecorder)
+
+		err := ActivityRecordsExportEmitter(echoContext, queryRepo, tkDto.ExportActivityRecordsRequest{
+			ExportFormat: exportFormat,
+			BatchSize:    7,
+		})
+		if err != nil {
+			t.Fatalf("UnexpectedError: '%s'", err.Error())
+		}
+		return responseRecorder
+	}
+
+	t.Run("NdjsonDownload", func(t *testing.T) {
+		queryRepo := &fakeExportQueryRepo{activityRecords: activityRecords}
+		responseRecorder := emitExport(t, queryRepo, tkValueObject.ExportFormatNdjson)
+
+		if responseRecorder.Code != http.StatusOK {
+			t.Fatalf("UnexpectedStatusCode: %d", responseRecorder.Code)
+		}
+		if responseRecorder.Header().Get(echo.HeaderContentType) != "application/x-ndjson" {
+			t.Errorf("UnexpectedContentType: %s", responseRecorder.Header().Get(echo.HeaderContentType))
+		}
+		contentDisposition := responseRecorder.Header().Get(echo.HeaderContentDisposition)
+		if !strings.HasPrefix(contentDisposition, `attachment; filename="activity-records-`) ||
+			!strings.HasSuffix(contentDisposition, `.ndjson"`) {
+			t.Errorf("UnexpectedContentDisposition: %s", contentDisposition)
+		}
+
+		ndjsonLines := strings.Split(strings.TrimSpace(responseRecorder.Body.String()), "\n")
+		if len(ndjsonLines) != 20 {
+			t.Fatalf("LinesCountMismatch: expected 20, got %d", len(ndjsonLines))
+		}
+		for lineIndex, ndjsonLine := range ndjsonLines {
+			lineRecord := map[string]any{}
+			err := json.Unmarshal([]byte(ndjsonLine), &lineRecord)
+			if err != nil {
+				t.Fatalf("InvalidNdjsonLine: %v", err)
+			}
+			if lineRecord["recordId"] != float64(lineIndex+1) {
+				t.Errorf("RecordIdMismatch: expected %d, got %v", lineIndex+1, lineRecord["recordId"])
+			}
+		}
+
+		// 20 records in batches of 7, plus the empty batch ending the cursor.
+		if queryRepo.readCallsCount != 4 {
+			t.Errorf("ReadCallsCountMismatch: expected 4, got %d", queryRepo.readCallsCount)
+		}
+	})
+
+	t.Run("CsvDownload", func(t *testing.T) {
+		queryRepo := &fakeExportQueryRepo{activityRecords: activityRecords}
+		responseRecorder := emitExport(t, queryRepo, tkValueObject.ExportFormatCsv)
+
+		if responseRecorder.Header().Get(echo.HeaderContentType) != "text/csv" {
+			t.Errorf("UnexpectedContentType: %s", responseRecorder.Header().Get(echo.HeaderContentType))
+		}
+
+		csvRows, err := csv.NewReader(responseRecorder.Body).ReadAll()
+		if err != nil {
+			t.Fatalf("InvalidCsv: %v", err)
+		}
+		if len(csvRows) != 21 {
+			t.Fatalf("RowsCountMismatch: expected 21, got %d", len(csvRows))
+		}
+		if strings.Join(csvRows[0], ",") != "recordId,recordLevel,recordCode,affectedResources,recordDetails,operatorSri,operatorIpAddress,correlationId,createdAt" {
+			t.Errorf("UnexpectedCsvHeader: %v", csvRows[0])
+		}
+		if csvRows[1][4] != `'=HYPERLINK("http://evil")` {
+			t.Errorf("FormulaNotNeutralized: %s", csvRows[1][4])
+		}
+		if csvRows[2][3] != "sri://12:mailbox/1" || csvRows[2][4] != `{"recordIndex":1}` {
+			t.Errorf("UnexpectedCsvRow: %v", csvRows[2])
+		}
+	})
+
+	t.Run("EmptyNdjsonDownload", func(t *testing.T) {
+		queryRepo := &fakeExportQueryRepo{}
+		responseRecorder := emitExport(t, queryRepo, tkValueObject.ExportFormatNdjson)
+
+		if responseRecorder.Code != http.StatusOK || responseRecorder.Body.Len() != 0 {
+			t.Errorf(
+				"UnexpectedEmptyDownload: status %d, body '%s'",
+				responseRecorder.Code, responseRecorder.Body.String(),
+			)
+		}
+		if responseRecorder.Header().Get(echo.HeaderContentDisposition) == "" {
+			t.Error("MissingContentDisposition")
+		}
+	})
+
+	t.Run("NdjsonErrorAfterDownloadStarted", func(t *testing.T) {
+		queryRepo := &fakeExportQueryRepo{
+			activityRecords:          activityRecords,
+			successfulReadCallsCount: 1,
+			readErr:                  errors.New("DatabaseLocked"),
+		}
+		responseRecorder := emitExport(t, queryRepo, tkValueObject.ExportFormatNdjson)
+
+		if responseRecorder.Code != http.StatusOK {
+			t.Fatalf("UnexpectedStatusCode: %d", responseRecorder.Code)
+		}
+		ndjsonLines := strings.Split(strings.TrimSpace(responseRecorder.Body.String()), "\n")
+		if len(ndjsonLines) != 8 {
+			t.Fatalf("LinesCountMismatch: expected 8, got %d", len(ndjsonLines))
+		}
+		if ndjsonLines[7] != `{"exportError":"ExportActivityRecordsInfraError"}` {
+			t.Errorf("UnexpectedLastLine: %s", ndjsonLines[7])
+		}
+	})
+
+	t.Run("ErrorsBeforeDownload", func(t *testing.T) {
+		testCaseStructs := []struct {
+			queryRepo          *fakeExportQueryRepo
+			exportFormat       tkValueObject.ExportFormat
+			expectedStatusCode int
+		}{
+			{&fakeExportQueryRepo{}, tkValueObject.ExportFormat("xlsx"), http.StatusBadRequest},
+			{
+				&fakeExportQueryRepo{readErr: errors.New("DatabaseLocked")},
+				tkValueObject.ExportFormatCsv, http.StatusInternalServerError,
+			},
+		}
+
+		for _, testCase := range testCaseStructs {
+			responseRecorder := emitExport(t, testCase.queryRepo, testCase.exportFormat)
+			if responseRecorder.Code != testCase.expectedStatusCode {
+				t.Errorf(
+					"UnexpectedStatusCode: expected %d, got %d [%s]",
+					testCase.expectedStatusCode, responseRecorder.Code, testCase.exportFormat,
+				)
+			}
+			if responseRecorder.Header().Get(echo.HeaderContentDisposition) != "" {
+				t.Errorf("UnexpectedContentDisposition [%s]", testCase.exportFormat)
+			}
+		}
+	})
+}
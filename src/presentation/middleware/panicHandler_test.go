@@ -78,10 +78,7 @@ func TestIsOperatorTrustworthy(t *testing.T) {
 }
 
 func TestLogPanic(t *testing.T) {
-	tempDir := t.TempDir()
-	originalWorkingDir, _ := os.Getwd()
-	defer os.Chdir(originalWorkingDir)
-	os.Chdir(tempDir)
+	t.Chdir(t.TempDir())
 
 	fileClerk := tkInfra.FileClerk{}
 	panicReport := &PanicReport{
@@ -119,6 +116,7 @@ func TestLogPanic(t *testing.T) {
 }
 
 func TestApiHandlePanic(t *testing.T) {
+	t.Chdir(t.TempDir())
 	originalEnv := os.Getenv(tkInfra.TrustedIpsEnvVarName)
 	defer os.Setenv(tkInfra.TrustedIpsEnvVarName, originalEnv)
 
@@ -218,6 +216,7 @@ func TestApiHandlePanic(t *testing.T) {
 }
 
 func TestApiPanicHandler(t *testing.T) {
+	t.Chdir(t.TempDir())
 	os.Setenv(tkInfra.TrustedIpsEnvVarName, "")
 
 	echoInstance := echo.New()
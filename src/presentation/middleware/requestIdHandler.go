@@ -0,0 +1,32 @@
+package tkPresentationMiddleware
+
+import (
+	"crypto/rand"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/labstack/echo/v4"
+)
+
+const (
+	RequestIdHandlerHeaderName string = echo.HeaderXRequestID
+	RequestIdHandlerContextKey string = "correlationId"
+)
+
+// ApiRequestIdHandler accepts the request X-Request-Id header when it's a valid
+// CorrelationId, otherwise assigns a random one. The ID is stored in the Echo context
+// under "correlationId", where ApiRequestInputReader reads it from, and echoed in the
+// response header so the client and the HTTP logs can refer to the request.
+func ApiRequestIdHandler(subsequentHandler echo.HandlerFunc) echo.HandlerFunc {
+	return func(echoContext echo.Context) error {
+		correlationId, err := tkValueObject.NewCorrelationId(
+			echoContext.Request().Header.Get(RequestIdHandlerHeaderName),
+		)
+		if err != nil {
+			correlationId = tkValueObject.CorrelationId(rand.Text())
+		}
+
+		echoContext.Set(RequestIdHandlerContextKey, correlationId)
+		echoContext.Response().Header().Set(RequestIdHandlerHeaderName, correlationId.String())
+		return subsequentHandler(echoContext)
+	}
+}
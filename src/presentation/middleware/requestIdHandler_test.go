@@ -0,0 +1,70 @@
+package tkPresentationMiddleware
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/labstack/echo/v4"
+)
+
+func TestApiRequestIdHandler(t *testing.T) {
+	testCaseStructs := []struct {
+		name                  string
+		requestIdHeader       string
+		expectedCorrelationId string
+	}{
+		{"AcceptsValidHeader", "req_1234", "req_1234"},
+		{"AssignsWhenMissing", "", ""},
+		{"AssignsWhenInvalid", "bad id\r\nX-Injected: 1", ""},
+	}
+
+	for _, testCase := range testCaseStructs {
+		t.Run(testCase.name, func(t *testing.T) {
+			echoInstance := echo.New()
+			httpRequest := httptest.NewRequest(http.MethodGet, "/", nil)
+			if testCase.requestIdHeader != "" {
+				httpRequest.Header.Set(RequestIdHandlerHeaderName, testCase.requestIdHeader)
+			}
+			httpRecorder := httptest.NewRecorder()
+			echoContext := echoInstance.NewContext(httpRequest, httpRecorder)
+
+			var contextCorrelationId tkValueObject.CorrelationId
+			requestIdHandler := ApiRequestIdHandler(func(echoContext echo.Context) error {
+				correlationId, assertOk := echoContext.Get(RequestIdHandlerContextKey).(tkValueObject.CorrelationId)
+				if !assertOk {
+					t.Fatal("CorrelationIdNotInContext")
+				}
+				contextCorrelationId = correlationId
+				return nil
+			})
+			if err := requestIdHandler(echoContext); err != nil {
+				t.Fatalf("UnexpectedError: '%s'", err.Error())
+			}
+
+			if testCase.expectedCorrelationId != "" &&
+				contextCorrelationId.String() != testCase.expectedCorrelationId {
+				t.Errorf(
+					"CorrelationIdMismatch: expected %s, got %s",
+					testCase.expectedCorrelationId, contextCorrelationId,
+				)
+			}
+			if _, err := tkValueObject.NewCorrelationId(contextCorrelationId.String()); err != nil {
+				t.Errorf("InvalidAssignedCorrelationId: '%s'", contextCorrelationId)
+			}
+			if contextCorrelationId == tkValueObject.CorrelationId(testCase.requestIdHeader) &&
+				testCase.expectedCorrelationId == "" {
+				t.Errorf("InvalidHeaderAccepted: '%s'", testCase.requestIdHeader)
+			}
+
+			responseRequestId := httpRecorder.Header().Get(RequestIdHandlerHeaderName)
+			if responseRequestId != contextCorrelationId.String() {
+				t.Errorf(
+					"ResponseHeaderMismatch: expected %s, got %s",
+					contextCorrelationId, responseRequestId,
+				)
+			}
+		})
+	}
+}
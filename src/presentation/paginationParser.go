@@ -54,5 +54,13 @@ func PaginationParser(
 		parsedPagination.LastSeenId = &lastSeenId
 	}
 
+	if untrustedInput["cursor"] != nil {
+		cursor, err := tkValueObject.NewPaginationCursor(untrustedInput["cursor"])
+		if err != nil {
+			return parsedPagination, err
+		}
+		parsedPagination.Cursor = &cursor
+	}
+
 	return parsedPagination, nil
 }
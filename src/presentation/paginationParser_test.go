@@ -401,6 +401,40 @@ func TestPaginationParser(t *testing.T) {
 		}
 	})
 
+	t.Run("Cursor", func(t *testing.T) {
+		testCaseStructs := []struct {
+			cursorInput any
+			expectError bool
+		}{
+			{"eyJjIjoiaWQiLCJkIjoiZGVzYyJ9", false},
+			{"", true},
+			{"not a cursor", true},
+			{"bm90LWpzb24", true},
+			{[]string{"eyJjIjoiaWQiLCJkIjoiZGVzYyJ9"}, true},
+		}
+
+		defaultPagination := tkDto.Pagination{
+			PageNumber:   1,
+			ItemsPerPage: 10,
+		}
+
+		for _, testCase := range testCaseStructs {
+			actualOutput, conversionErr := PaginationParser(
+				defaultPagination, map[string]any{"cursor": testCase.cursorInput},
+			)
+			if testCase.expectError && conversionErr == nil {
+				t.Errorf("MissingExpectedError: [%v]", testCase.cursorInput)
+			}
+			if !testCase.expectError && conversionErr != nil {
+				t.Errorf("UnexpectedError: '%s' [%v]", conversionErr.Error(), testCase.cursorInput)
+			}
+			if !testCase.expectError && (actualOutput.Cursor == nil ||
+				actualOutput.Cursor.String() != testCase.cursorInput) {
+				t.Errorf("UnexpectedCursor: '%v' vs '%v'", actualOutput.Cursor, testCase.cursorInput)
+			}
+		}
+	})
+
 	t.Run("SuccessWithEmptyInput", func(t *testing.T) {
 		defaultPagination := tkDto.Pagination{
 			PageNumber:   1,
@@ -427,5 +461,8 @@ func TestPaginationParser(t *testing.T) {
 		if actualOutput.LastSeenId != nil {
 			t.Errorf("UnexpectedLastSeenId: ShouldBeNil")
 		}
+		if actualOutput.Cursor != nil {
+			t.Errorf("UnexpectedCursor: ShouldBeNil")
+		}
 	})
 }
@@ -10,7 +10,11 @@ import (
 	"github.com/labstack/echo/v4"
 )
 
+// ApiRequestInputReader rejects the requests without Content-Type unless
+// ShouldAcceptMissingContentType is set, in which case the bodyless ones (e.g. GET and
+// DELETE requests) are read from their query and path params only.
 type ApiRequestInputReader struct {
+	ShouldAcceptMissingContentType bool
 }
 
 func (reader ApiRequestInputReader) StringDotNotationToHierarchicalMap(
@@ -103,6 +107,8 @@ func (ApiRequestInputReader) MultipartFilesProcessor(
 //   - multipart/form-data: Processes form fields similar to URL-encoded data. File uploads
 //     are normalized under the "files" key, with multiple files indexed as "key_0", "key_1", etc.
 //     Returns "InvalidMultipartFormData" error if the multipart form cannot be parsed.
+//   - Missing Content-Type without body, with ShouldAcceptMissingContentType (e.g. GET
+//     and DELETE requests): Only query and path params are read.
 //   - Other/missing Content-Type: Returns "InvalidContentType" error.
 //
 // Query parameters:
@@ -119,6 +125,8 @@ func (ApiRequestInputReader) MultipartFilesProcessor(
 //   - operatorSri: Extracted from context key and included in the result map.
 //   - operatorAccountId: Extracted from context key and included in the result map.
 //   - operatorIpAddress: If missing, populated using NewRequesterIpExtractor().Execute().
+//   - correlationId: Extracted from context key (set by the request ID middleware) and
+//     included in the result map.
 //
 // Returns:
 //   - A map[string]any containing all extracted request data, or
@@ -156,6 +164,9 @@ func (reader ApiRequestInputReader) Reader(echoContext echo.Context) (map[string
 			requestBody["files"] = fileHeaders
 		}
 
+	case contentType == "" && reader.ShouldAcceptMissingContentType &&
+		echoContext.Request().ContentLength == 0:
+
 	default:
 		return nil, echo.NewHTTPError(http.StatusBadRequest, "InvalidContentType")
 	}
@@ -169,11 +180,12 @@ func (reader ApiRequestInputReader) Reader(echoContext echo.Context) (map[string
 	}
 
 	// The `operatorSri` and `operatorIpAddress` fields in the request body are
-	// typically extracted from an authentication middleware and passed by the
-	// controller in the request body if the API relies on a liaison layer to
-	// process the request uniformly. To prevent repeating the same logic in every
-	// controller and to ensure the untrusted user doesn't succeed in injecting a
-	// fake operator context, we populate/overwrite these values here.
+	// typically extracted from an authentication middleware (and `correlationId`
+	// from the request ID one) and passed by the controller in the request body if
+	// the API relies on a liaison layer to process the request uniformly. To prevent
+	// repeating the same logic in every controller and to ensure the untrusted user
+	// doesn't succeed in injecting a fake operator context, we populate/overwrite
+	// these values here.
 	requestBody["operatorSri"] = nil
 	if operatorSri, assertOk := echoContext.Get("operatorSri").(tkValueObject.SystemResourceIdentifier); assertOk {
 		requestBody["operatorSri"] = operatorSri
@@ -200,5 +212,10 @@ func (reader ApiRequestInputReader) Reader(echoContext echo.Context) (map[string
 		requestBody["operatorIpAddress"] = operatorIpAddress
 	}
 
+	requestBody["correlationId"] = nil
+	if correlationId, assertOk := echoContext.Get("correlationId").(tkValueObject.CorrelationId); assertOk {
+		requestBody["correlationId"] = correlationId
+	}
+
 	return requestBody, nil
 }
@@ -532,6 +532,34 @@ func TestApiRequestInputReader(t *testing.T) {
 		}
 	})
 
+	t.Run("NoContentTypeWithoutBody", func(t *testing.T) {
+		echoInstance := echo.New()
+		httpRequest := httptest.NewRequest(http.MethodGet, "/?search=test", nil)
+		httpRecorder := httptest.NewRecorder()
+		echoContext := echoInstance.NewContext(httpRequest, httpRecorder)
+
+		_, err := requestInputReader.Reader(echoContext)
+		if err == nil || !strings.Contains(err.Error(), "InvalidContentType") {
+			t.Errorf("MissingExpectedError: InvalidContentType")
+		}
+
+		lenientRequestInputReader := ApiRequestInputReader{ShouldAcceptMissingContentType: true}
+		requestInput, err := lenientRequestInputReader.Reader(echoContext)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+		if requestInput["search"] != "test" {
+			t.Errorf("SearchMismatch: expected test, got %v", requestInput["search"])
+		}
+
+		httpRequest = httptest.NewRequest(http.MethodPost, "/", strings.NewReader("raw"))
+		echoContext = echoInstance.NewContext(httpRequest, httptest.NewRecorder())
+		_, err = lenientRequestInputReader.Reader(echoContext)
+		if err == nil || !strings.Contains(err.Error(), "InvalidContentType") {
+			t.Errorf("MissingExpectedError: InvalidContentType")
+		}
+	})
+
 	t.Run("QueryParamsAndPathParams", func(t *testing.T) {
 		echoInstance := echo.New()
 		httpRequest := httptest.NewRequest(
@@ -602,6 +630,40 @@ func TestApiRequestInputReader(t *testing.T) {
 		}
 	})
 
+	t.Run("CorrelationIdFromContext", func(t *testing.T) {
+		echoInstance := echo.New()
+		httpRequest := httptest.NewRequest(
+			http.MethodPost, "/", strings.NewReader(`{"correlationId":"injected"}`),
+		)
+		httpRequest.Header.Set("Content-Type", "application/json")
+		httpRecorder := httptest.NewRecorder()
+		echoContext := echoInstance.NewContext(httpRequest, httpRecorder)
+
+		parsedRequestBody, err := requestInputReader.Reader(echoContext)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+		if parsedRequestBody["correlationId"] != nil {
+			t.Errorf("InjectedCorrelationIdKept: %v", parsedRequestBody["correlationId"])
+		}
+
+		httpRequest = httptest.NewRequest(http.MethodGet, "/", nil)
+		httpRequest.Header.Set("Content-Type", "application/json")
+		echoContext = echoInstance.NewContext(httpRequest, httpRecorder)
+		echoContext.Set("correlationId", tkValueObject.CorrelationId("req_1234"))
+
+		parsedRequestBody, err = requestInputReader.Reader(echoContext)
+		if err != nil {
+			t.Fatalf("UnexpectedError: %v", err)
+		}
+		if parsedRequestBody["correlationId"] != tkValueObject.CorrelationId("req_1234") {
+			t.Errorf(
+				"CorrelationIdMismatch: expected req_1234, got %v",
+				parsedRequestBody["correlationId"],
+			)
+		}
+	})
+
 	t.Run("OperatorIpAddressAlwaysSet", func(t *testing.T) {
 		echoInstance := echo.New()
 		httpRequest := httptest.NewRequest(http.MethodGet, "/", nil)
@@ -1,16 +1,48 @@
 package tkPresentation
 
 import (
+	"errors"
 	"log/slog"
+	"time"
 
 	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
+	"github.com/iancoleman/strcase"
 )
 
+// timeParamParser accepts Unix timestamps (numbers or numeric strings), RFC 3339
+// date times, date-only values (midnight UTC) and relative times ("24h ago").
+func timeParamParser(rawTimeParam any) (timeParam tkValueObject.UnixTime, err error) {
+	timeParam, err = tkValueObject.NewUnixTime(rawTimeParam)
+	if err == nil {
+		return timeParam, nil
+	}
+
+	timeParamStr, assertOk := rawTimeParam.(string)
+	if !assertOk {
+		return timeParam, err
+	}
+
+	for _, timeLayout := range []string{time.RFC3339, time.DateOnly} {
+		goTime, err := time.Parse(timeLayout, timeParamStr)
+		if err == nil {
+			return tkValueObject.NewUnixTimeWithGoTime(goTime), nil
+		}
+	}
+
+	relativeTime, err := tkValueObject.NewRelativeTime(timeParamStr)
+	if err != nil {
+		return timeParam, err
+	}
+
+	return relativeTime.ReadAsUnixTime(), nil
+}
+
 // This function parses time parameters from untrusted input. Time parameters are
 // almost always optional, so it returns a map of pointers to tkValueObject.UnixTime.
 // This allows the caller to easily determine if a time parameter was provided by
 // checking if the pointer is nil. The parsed time parameters can then be directly
-// set in a DTO.
+// set in a DTO. Invalid time parameters are set to nil; StrictTimeParamsParser fails
+// on them instead.
 func TimeParamsParser(
 	timeParamNames []string,
 	untrustedInput map[string]any,
@@ -27,7 +59,7 @@ func TimeParamsParser(
 			continue
 		}
 
-		timeParam, err := tkValueObject.NewUnixTime(untrustedInput[timeParamName])
+		timeParam, err := timeParamParser(untrustedInput[timeParamName])
 		if err != nil {
 			slog.Debug("InvalidTimeParam", slog.String("timeParamName", timeParamName))
 			timeParamsPtr[timeParamName] = nil
@@ -39,3 +71,21 @@ func TimeParamsParser(
 
 	return timeParamsPtr
 }
+
+// StrictTimeParamsParser is TimeParamsParser failing with an "Invalid<ParamName>"
+// error on the first invalid time parameter, so a filter isn't silently dropped. The
+// map then only holds the provided time parameters.
+func StrictTimeParamsParser(
+	timeParamNames []string,
+	untrustedInput map[string]any,
+) (map[string]*tkValueObject.UnixTime, error) {
+	timeParamsPtr := TimeParamsParser(timeParamNames, untrustedInput)
+	for _, timeParamName := range timeParamNames {
+		timeParamPtr, isTimeParamSet := timeParamsPtr[timeParamName]
+		if isTimeParamSet && timeParamPtr == nil {
+			return timeParamsPtr, errors.New("Invalid" + strcase.ToCamel(timeParamName))
+		}
+	}
+
+	return timeParamsPtr, nil
+}
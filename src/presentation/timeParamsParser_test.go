@@ -3,6 +3,7 @@ package tkPresentation
 import (
 	"slices"
 	"testing"
+	"time"
 
 	tkValueObject "github.com/goinfinite/tk/src/domain/valueObject"
 )
@@ -31,6 +32,23 @@ func TestTimeParamsParser(t *testing.T) {
 					}(),
 				},
 			},
+			{
+				timeParamNames: []string{"createdAt", "updatedAt"},
+				inputMap: map[string]any{
+					"createdAt": "2021-01-01T00:00:00Z",
+					"updatedAt": "2021-01-02",
+				},
+				expectedOutput: map[string]*tkValueObject.UnixTime{
+					"createdAt": func() *tkValueObject.UnixTime {
+						timeParam, _ := tkValueObject.NewUnixTime(1609459200)
+						return &timeParam
+					}(),
+					"updatedAt": func() *tkValueObject.UnixTime {
+						timeParam, _ := tkValueObject.NewUnixTime(1609545600)
+						return &timeParam
+					}(),
+				},
+			},
 		}
 
 		for _, testCase := range testCaseStructs {
@@ -49,6 +67,19 @@ func TestTimeParamsParser(t *testing.T) {
 		}
 	})
 
+	t.Run("SuccessWithRelativeTime", func(t *testing.T) {
+		actualOutput := TimeParamsParser(
+			[]string{"createdAt"}, map[string]any{"createdAt": "24h ago"},
+		)
+
+		expectedValue := tkValueObject.NewUnixTimeBeforeNow(24 * time.Hour)
+		actualValue := actualOutput["createdAt"]
+		if actualValue == nil || actualValue.Int64() < expectedValue.Int64() ||
+			actualValue.Int64()-expectedValue.Int64() > 1 {
+			t.Errorf("UnexpectedCreatedAt: expected '%v', got '%v'", expectedValue, actualValue)
+		}
+	})
+
 	t.Run("SuccessWithPartialFields", func(t *testing.T) {
 		testCaseStructs := []struct {
 			timeParamNames []string
@@ -180,3 +211,66 @@ func TestTimeParamsParser(t *testing.T) {
 		}
 	})
 }
+
+func TestStrictTimeParamsParser(t *testing.T) {
+	testCaseStructs := []struct {
+		inputMap       map[string]any
+		expectedOutput map[string]*tkValueObject.UnixTime
+		expectedError  string
+	}{
+		{
+			inputMap: map[string]any{"createdAt": 1609459200, "updatedAt": ""},
+			expectedOutput: map[string]*tkValueObject.UnixTime{
+				"createdAt": func() *tkValueObject.UnixTime {
+					timeParam, _ := tkValueObject.NewUnixTime(1609459200)
+					return &timeParam
+				}(),
+			},
+		},
+		{
+			inputMap:      map[string]any{"createdAt": 1609459200, "updatedAt": "invalid"},
+			expectedError: "InvalidUpdatedAt",
+		},
+		{
+			inputMap:      map[string]any{"createdAt": "2021-13-01"},
+			expectedError: "InvalidCreatedAt",
+		},
+		{
+			inputMap:      map[string]any{"createdAt": "2021-01-01T00:00:00"},
+			expectedError: "InvalidCreatedAt",
+		},
+		{
+			inputMap:      map[string]any{"createdAt": "300 years ago"},
+			expectedError: "InvalidCreatedAt",
+		},
+	}
+
+	for _, testCase := range testCaseStructs {
+		actualOutput, err := StrictTimeParamsParser(
+			[]string{"createdAt", "updatedAt"}, testCase.inputMap,
+		)
+		if testCase.expectedError != "" {
+			if err == nil || err.Error() != testCase.expectedError {
+				t.Errorf(
+					"MissingExpectedError: %s (got %v) [%v]",
+					testCase.expectedError, err, testCase.inputMap,
+				)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("UnexpectedError: '%s' [%v]", err.Error(), testCase.inputMap)
+			continue
+		}
+
+		if len(actualOutput) != len(testCase.expectedOutput) {
+			t.Errorf("UnexpectedOutput: expected '%v', got '%v'", testCase.expectedOutput, actualOutput)
+		}
+		for paramName, expectedValue := range testCase.expectedOutput {
+			actualValue := actualOutput[paramName]
+			if actualValue == nil || *actualValue != *expectedValue {
+				t.Errorf("Unexpected%s: expected '%v', got '%v'", paramName, expectedValue, actualValue)
+			}
+		}
+	}
+}